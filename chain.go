@@ -4,6 +4,7 @@
 package config
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -16,16 +17,36 @@ type ChainConfig struct {
 	Genesis json.RawMessage // Genesis JSON
 	Config  json.RawMessage // Chain config JSON (eth APIs, etc.)
 	Upgrade json.RawMessage // Upgrade config JSON
+
+	// Extra holds VM-specific sidecar files (e.g. a precompile allowlist),
+	// keyed by file name
+	Extra map[string][]byte
 }
 
 // ChainManager handles unified chain configuration across all nodes
 type ChainManager struct {
-	paths *Paths
+	paths          *Paths
+	validateConfig bool // Run ValidateChainConfig in SaveChain
+}
+
+// ChainManagerOption is a functional option for the ChainManager
+type ChainManagerOption func(*ChainManager)
+
+// WithChainConfigValidation makes SaveChain reject chain configs that fail
+// ValidateChainConfig
+func WithChainConfigValidation() ChainManagerOption {
+	return func(cm *ChainManager) {
+		cm.validateConfig = true
+	}
 }
 
 // NewChainManager creates a new chain manager
-func NewChainManager(paths *Paths) *ChainManager {
-	return &ChainManager{paths: paths}
+func NewChainManager(paths *Paths, opts ...ChainManagerOption) *ChainManager {
+	cm := &ChainManager{paths: paths}
+	for _, opt := range opts {
+		opt(cm)
+	}
+	return cm
 }
 
 // DefaultChainManager creates a chain manager with default paths
@@ -39,6 +60,12 @@ func DefaultChainManager() (*ChainManager, error) {
 
 // ListChains returns all configured chains
 func (cm *ChainManager) ListChains() ([]string, error) {
+	return cm.ListChainsContext(context.Background())
+}
+
+// ListChainsContext is ListChains, returning ctx.Err() if ctx is cancelled
+// while the chains directory is scanned
+func (cm *ChainManager) ListChainsContext(ctx context.Context) ([]string, error) {
 	chainsDir := cm.paths.ChainsBaseDir()
 	entries, err := os.ReadDir(chainsDir)
 	if err != nil {
@@ -50,6 +77,12 @@ func (cm *ChainManager) ListChains() ([]string, error) {
 
 	var chains []string
 	for _, entry := range entries {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
 		if entry.IsDir() {
 			// Verify it has a genesis file
 			genesisPath := cm.paths.ChainGenesis(entry.Name())
@@ -97,37 +130,56 @@ func (cm *ChainManager) LoadChain(chainName string) (*ChainConfig, error) {
 		cc.Upgrade = upgrade
 	}
 
+	// Load any extra sidecar files
+	extra, err := readExtraChainFiles(cm.paths.ChainDir(chainName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read extra files for chain %s: %w", chainName, err)
+	}
+	cc.Extra = extra
+
 	return cc, nil
 }
 
-// SaveChain saves chain configuration
+// SaveChain saves chain configuration. All files are staged next to their
+// targets and renamed into place together; if any step fails the files
+// already replaced are restored, so the chain directory never mixes old
+// and new files. Such failures are reported as a *ChainSaveError.
 func (cm *ChainManager) SaveChain(cc *ChainConfig) error {
+	if err := cm.validateChain(cc); err != nil {
+		return err
+	}
+
 	// Ensure chain directory exists
 	if err := cm.paths.EnsureChainDir(cc.Name); err != nil {
 		return fmt.Errorf("failed to create chain directory: %w", err)
 	}
 
-	// Save genesis (required)
-	if len(cc.Genesis) > 0 {
-		if err := os.WriteFile(cm.paths.ChainGenesis(cc.Name), cc.Genesis, 0644); err != nil {
-			return fmt.Errorf("failed to write genesis: %w", err)
+	// Genesis is required, config and upgrade are optional; empty files
+	// are left untouched
+	files := make(map[string][]byte)
+	for name, data := range map[string][]byte{GenesisFile: cc.Genesis, ConfigFile: cc.Config, UpgradeFile: cc.Upgrade} {
+		if len(data) > 0 {
+			files[name] = data
 		}
 	}
+	for name, data := range cc.Extra {
+		files[name] = data
+	}
+	return writeChainFilesAtomic(cc.Name, cm.paths.ChainDir(cc.Name), files, cm.paths.Policy().FileMode)
+}
 
-	// Save config (optional)
-	if len(cc.Config) > 0 {
-		if err := os.WriteFile(cm.paths.ChainConfig(cc.Name), cc.Config, 0644); err != nil {
-			return fmt.Errorf("failed to write config: %w", err)
+// validateChain runs the checks SaveChain makes before writing anything
+func (cm *ChainManager) validateChain(cc *ChainConfig) error {
+	if cm.validateConfig && len(cc.Config) > 0 {
+		if err := ValidateChainConfig(cc.Config); err != nil {
+			return fmt.Errorf("invalid config for chain %s: %w", cc.Name, err)
 		}
 	}
-
-	// Save upgrade (optional)
-	if len(cc.Upgrade) > 0 {
-		if err := os.WriteFile(cm.paths.ChainUpgrade(cc.Name), cc.Upgrade, 0644); err != nil {
-			return fmt.Errorf("failed to write upgrade: %w", err)
+	for name := range cc.Extra {
+		if err := validateExtraChainFile(name); err != nil {
+			return err
 		}
 	}
-
 	return nil
 }
 
@@ -141,7 +193,7 @@ func (cm *ChainManager) SaveGenesis(chainName string, genesis []byte) error {
 	if err := cm.paths.EnsureChainDir(chainName); err != nil {
 		return err
 	}
-	return os.WriteFile(cm.paths.ChainGenesis(chainName), genesis, 0644)
+	return cm.paths.WriteFile(cm.paths.ChainGenesis(chainName), genesis)
 }
 
 // DeleteChain removes all configuration for a chain
@@ -164,7 +216,7 @@ func (cm *ChainManager) CopyChainConfigsToNode(chainName, chainID, nodeDir strin
 	}
 
 	// Create node's chain config directory
-	nodeChainDir := filepath.Join(nodeDir, "configs", "chains", chainID)
+	nodeChainDir := filepath.Join(nodeDir, NodeConfigsDir, NodeChainConfigsDir, chainID)
 	if err := os.MkdirAll(nodeChainDir, 0755); err != nil {
 		return err
 	}
@@ -185,6 +237,121 @@ func (cm *ChainManager) CopyChainConfigsToNode(chainName, chainID, nodeDir strin
 		}
 	}
 
+	// Copy extra sidecar files
+	return writeExtraChainFiles(nodeChainDir, cc.Extra, func(path string, data []byte) error {
+		return os.WriteFile(path, data, 0644)
+	})
+}
+
+// LoadNodeChainConfigs reads a node's chain-config-dir, as written by
+// CopyChainConfigsToNode, keyed by chain ID. Each ChainConfig has Name set
+// to the chain ID and holds the config, upgrade, and extra files found;
+// Genesis is not part of the node-side layout and is left empty. A missing
+// directory yields no configs.
+func LoadNodeChainConfigs(chainConfigDir string) (map[string]ChainConfig, error) {
+	return LoadNodeChainConfigsContext(context.Background(), chainConfigDir)
+}
+
+// LoadNodeChainConfigsContext is LoadNodeChainConfigs, returning ctx.Err()
+// if ctx is cancelled between chains
+func LoadNodeChainConfigsContext(ctx context.Context, chainConfigDir string) (map[string]ChainConfig, error) {
+	entries, err := os.ReadDir(chainConfigDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read chain config dir: %w", err)
+	}
+
+	configs := make(map[string]ChainConfig)
+	for _, entry := range entries {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		if !entry.IsDir() {
+			continue
+		}
+		chainID := entry.Name()
+		dir := filepath.Join(chainConfigDir, chainID)
+		cc := ChainConfig{Name: chainID}
+
+		if cc.Config, err = readOptionalFile(filepath.Join(dir, ConfigFile)); err != nil {
+			return nil, fmt.Errorf("failed to read config for chain %s: %w", chainID, err)
+		}
+		if cc.Upgrade, err = readOptionalFile(filepath.Join(dir, UpgradeFile)); err != nil {
+			return nil, fmt.Errorf("failed to read upgrade for chain %s: %w", chainID, err)
+		}
+		if cc.Extra, err = readExtraChainFiles(dir); err != nil {
+			return nil, fmt.Errorf("failed to read extra files for chain %s: %w", chainID, err)
+		}
+		configs[chainID] = cc
+	}
+	return configs, nil
+}
+
+// readOptionalFile reads path, returning nil if it does not exist
+func readOptionalFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return data, err
+}
+
+// isKnownChainFile reports whether name is one of the standard chain files
+func isKnownChainFile(name string) bool {
+	return name == GenesisFile || name == ConfigFile || name == UpgradeFile
+}
+
+// readExtraChainFiles reads all regular files in dir other than the
+// standard chain files. Returns nil if there are none.
+func readExtraChainFiles(dir string) (map[string][]byte, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var extra map[string][]byte
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() || isKnownChainFile(entry.Name()) || isStagedChainFile(entry.Name()) {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		if extra == nil {
+			extra = make(map[string][]byte)
+		}
+		extra[entry.Name()] = data
+	}
+	return extra, nil
+}
+
+// validateExtraChainFile checks that name can be used for a sidecar file
+func validateExtraChainFile(name string) error {
+	if name == "" || name != filepath.Base(name) || name == "." || name == ".." {
+		return fmt.Errorf("invalid extra chain file name %q", name)
+	}
+	if isKnownChainFile(name) {
+		return fmt.Errorf("extra chain file %q conflicts with a standard chain file", name)
+	}
+	return nil
+}
+
+// writeExtraChainFiles writes extra sidecar files into dir
+func writeExtraChainFiles(dir string, extra map[string][]byte, write func(string, []byte) error) error {
+	for name, data := range extra {
+		if err := validateExtraChainFile(name); err != nil {
+			return err
+		}
+		if err := write(filepath.Join(dir, name), data); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
 	return nil
 }
 
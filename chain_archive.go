@@ -0,0 +1,195 @@
+// Copyright (C) 2021-2025, Lux Industries Inc. All rights reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package config
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// maxChainArchiveFile caps each file read from a chain archive
+const maxChainArchiveFile = 64 << 20
+
+// ExportChain writes a tar.gz of a chain's genesis, config, upgrade, and
+// extra files to w. Entries are stored under <chainName>/ so ImportChain
+// can restore the chain under the same name.
+func (cm *ChainManager) ExportChain(chainName string, w io.Writer) error {
+	cc, err := cm.LoadChain(chainName)
+	if err != nil {
+		return err
+	}
+
+	files := map[string][]byte{GenesisFile: cc.Genesis}
+	if len(cc.Config) > 0 {
+		files[ConfigFile] = cc.Config
+	}
+	if len(cc.Upgrade) > 0 {
+		files[UpgradeFile] = cc.Upgrade
+	}
+	for name, data := range cc.Extra {
+		files[name] = data
+	}
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	modTime := time.Now()
+	for _, name := range names {
+		data := files[name]
+		hdr := &tar.Header{
+			Name:    path.Join(chainName, name),
+			Mode:    0644,
+			Size:    int64(len(data)),
+			ModTime: modTime,
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return fmt.Errorf("failed to write archive header: %w", err)
+		}
+		if _, err := tw.Write(data); err != nil {
+			return fmt.Errorf("failed to write %s to archive: %w", name, err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to finish archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to finish archive: %w", err)
+	}
+	return nil
+}
+
+// ImportChain restores a chain from an archive written by ExportChain and
+// returns its name. The archive must hold a single chain with a genesis
+// that is a JSON object. An existing chain of the same name is replaced
+// only if overwrite is set; the archive is validated first and the chain's
+// files are swapped atomically, so a failed import leaves it untouched.
+func (cm *ChainManager) ImportChain(r io.Reader, overwrite bool) (string, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return "", fmt.Errorf("failed to read chain archive: %w", err)
+	}
+	defer gz.Close()
+
+	cc := &ChainConfig{}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to read chain archive: %w", err)
+		}
+		if hdr.Typeflag == tar.TypeDir {
+			continue
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			return "", fmt.Errorf("invalid chain archive: %s is not a regular file", hdr.Name)
+		}
+
+		chainName, name := path.Split(path.Clean(hdr.Name))
+		chainName = path.Clean(chainName)
+		if chainName == "." || chainName != path.Base(chainName) || chainName == ".." {
+			return "", fmt.Errorf("invalid chain archive: entry %s is not in a chain directory", hdr.Name)
+		}
+		if cc.Name == "" {
+			cc.Name = chainName
+		} else if cc.Name != chainName {
+			return "", fmt.Errorf("invalid chain archive: holds chains %s and %s", cc.Name, chainName)
+		}
+
+		if hdr.Size > maxChainArchiveFile {
+			return "", fmt.Errorf("invalid chain archive: %s is too large", hdr.Name)
+		}
+		data, err := io.ReadAll(io.LimitReader(tr, maxChainArchiveFile))
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s from chain archive: %w", hdr.Name, err)
+		}
+
+		switch name {
+		case GenesisFile:
+			cc.Genesis = data
+		case ConfigFile:
+			cc.Config = data
+		case UpgradeFile:
+			cc.Upgrade = data
+		default:
+			if cc.Extra == nil {
+				cc.Extra = make(map[string][]byte)
+			}
+			cc.Extra[name] = data
+		}
+	}
+
+	if cc.Name == "" {
+		return "", fmt.Errorf("invalid chain archive: no files")
+	}
+	if err := ValidateGenesis(cc.Genesis); err != nil {
+		return "", fmt.Errorf("invalid genesis for chain %s: %w", cc.Name, err)
+	}
+
+	if err := cm.validateChain(cc); err != nil {
+		return "", err
+	}
+
+	exists := cm.ChainExists(cc.Name)
+	if exists && !overwrite {
+		return "", fmt.Errorf("chain %s already exists", cc.Name)
+	}
+	// SaveChain replaces the existing files atomically; files the archive
+	// does not contain are only removed once it has succeeded
+	if err := cm.SaveChain(cc); err != nil {
+		return "", err
+	}
+	if exists {
+		if err := removeStaleChainFiles(cm.paths.ChainDir(cc.Name), cc); err != nil {
+			return "", fmt.Errorf("failed to remove stale files of chain %s: %w", cc.Name, err)
+		}
+	}
+	return cc.Name, nil
+}
+
+// removeStaleChainFiles removes regular files in dir that cc does not
+// contain, leaving staged temp files to their writer
+func removeStaleChainFiles(dir string, cc *ChainConfig) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.Type().IsRegular() || isStagedChainFile(name) {
+			continue
+		}
+		keep := false
+		switch name {
+		case GenesisFile:
+			keep = true
+		case ConfigFile:
+			keep = len(cc.Config) > 0
+		case UpgradeFile:
+			keep = len(cc.Upgrade) > 0
+		default:
+			_, keep = cc.Extra[name]
+		}
+		if keep {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, name)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
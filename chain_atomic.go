@@ -0,0 +1,168 @@
+// Copyright (C) 2021-2025, Lux Industries Inc. All rights reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ChainSaveError reports the chain file SaveChain failed to write.
+// RolledBack is true when the chain directory was left as it was before
+// the save.
+type ChainSaveError struct {
+	Chain      string
+	File       string
+	RolledBack bool
+	Err        error
+}
+
+func (e *ChainSaveError) Error() string {
+	state := "rolled back"
+	if !e.RolledBack {
+		state = "rollback failed"
+	}
+	return fmt.Sprintf("failed to write %s for chain %s (%s): %v", e.File, e.Chain, state, e.Err)
+}
+
+func (e *ChainSaveError) Unwrap() error {
+	return e.Err
+}
+
+// stagedFile is a chain file written to a temp file awaiting rename
+type stagedFile struct {
+	name    string
+	target  string
+	temp    string
+	old     []byte // Previous contents, if the target existed
+	existed bool
+}
+
+// writeChainFilesAtomic writes files (keyed by name) into dir all or
+// nothing. Each file is staged in a temp file in dir; once all are staged
+// the previous contents are captured and the temp files are renamed into
+// place. On failure, replaced files are restored and new ones removed.
+func writeChainFilesAtomic(chain, dir string, files map[string][]byte, mode os.FileMode) error {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		// Standard files first, in a fixed order, then sidecars by name
+		ri, rj := chainFileRank(names[i]), chainFileRank(names[j])
+		if ri != rj {
+			return ri < rj
+		}
+		return names[i] < names[j]
+	})
+
+	staged := make([]*stagedFile, 0, len(names))
+	cleanup := func() {
+		for _, f := range staged {
+			if f.temp != "" {
+				os.Remove(f.temp)
+			}
+		}
+	}
+
+	for _, name := range names {
+		f := &stagedFile{name: name, target: filepath.Join(dir, name)}
+		staged = append(staged, f)
+		temp, err := writeTempFile(dir, name, files[name], mode)
+		if err != nil {
+			cleanup()
+			return &ChainSaveError{Chain: chain, File: name, RolledBack: true, Err: err}
+		}
+		f.temp = temp
+
+		old, err := os.ReadFile(f.target)
+		switch {
+		case err == nil:
+			f.old, f.existed = old, true
+		case !os.IsNotExist(err):
+			cleanup()
+			return &ChainSaveError{Chain: chain, File: name, RolledBack: true, Err: err}
+		}
+	}
+
+	for i, f := range staged {
+		if err := os.Rename(f.temp, f.target); err != nil {
+			cleanup()
+			rbErr := rollbackChainFiles(staged[:i], mode)
+			return &ChainSaveError{Chain: chain, File: f.name, RolledBack: rbErr == nil, Err: errors.Join(err, rbErr)}
+		}
+		f.temp = ""
+	}
+	return nil
+}
+
+// rollbackChainFiles restores the previous contents of renamed files,
+// removing those that did not exist before
+func rollbackChainFiles(renamed []*stagedFile, mode os.FileMode) error {
+	var errs []error
+	for _, f := range renamed {
+		if !f.existed {
+			if err := os.Remove(f.target); err != nil && !os.IsNotExist(err) {
+				errs = append(errs, fmt.Errorf("failed to remove %s: %w", f.name, err))
+			}
+			continue
+		}
+		temp, err := writeTempFile(filepath.Dir(f.target), f.name, f.old, mode)
+		if err == nil {
+			err = os.Rename(temp, f.target)
+		}
+		if err != nil {
+			os.Remove(temp)
+			errs = append(errs, fmt.Errorf("failed to restore %s: %w", f.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// isStagedChainFile reports whether name is a temp file left by
+// writeChainFilesAtomic
+func isStagedChainFile(name string) bool {
+	return strings.HasPrefix(name, ".") && strings.Contains(name, ".tmp-")
+}
+
+// writeTempFile writes data to a new temp file in dir named after name and
+// returns its path
+func writeTempFile(dir, name string, data []byte, mode os.FileMode) (string, error) {
+	f, err := os.CreateTemp(dir, "."+name+".tmp-*")
+	if err != nil {
+		return "", err
+	}
+	temp := f.Name()
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Chmod(mode)
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(temp)
+		return "", err
+	}
+	return temp, nil
+}
+
+// chainFileRank orders chain files for writing: genesis, config, upgrade,
+// then sidecars
+func chainFileRank(name string) int {
+	switch name {
+	case GenesisFile:
+		return 0
+	case ConfigFile:
+		return 1
+	case UpgradeFile:
+		return 2
+	default:
+		return 3
+	}
+}
@@ -0,0 +1,207 @@
+// Copyright (C) 2021-2025, Lux Industries Inc. All rights reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// chainValueKind is the expected JSON shape of a chain config value
+type chainValueKind int
+
+const (
+	kindBool chainValueKind = iota
+	kindString
+	kindStringList
+	kindUint
+	kindNumber
+	kindDuration // Go duration string or integer nanoseconds
+)
+
+// evmChainConfigKeys are the recognized EVM chain config.json keys
+var evmChainConfigKeys = map[string]chainValueKind{
+	"eth-apis":                       kindStringList,
+	"log-level":                      kindString,
+	"log-json-format":                kindBool,
+	"pruning-enabled":                kindBool,
+	"state-sync-enabled":             kindBool,
+	"state-sync-min-blocks":          kindUint,
+	"allow-unfinalized-queries":      kindBool,
+	"allow-unprotected-txs":          kindBool,
+	"local-txs-enabled":              kindBool,
+	"admin-api-enabled":              kindBool,
+	"metrics-expensive-enabled":      kindBool,
+	"offline-pruning-enabled":        kindBool,
+	"offline-pruning-data-directory": kindString,
+	"keystore-directory":             kindString,
+	"rpc-gas-cap":                    kindUint,
+	"rpc-tx-fee-cap":                 kindNumber,
+	"api-max-duration":               kindDuration,
+	"api-max-blocks-per-request":     kindUint,
+	"ws-cpu-refill-rate":             kindDuration,
+	"ws-cpu-max-stored":              kindDuration,
+	"continuous-profiler-dir":        kindString,
+	"continuous-profiler-frequency":  kindDuration,
+	"tx-pool-price-limit":            kindUint,
+	"tx-pool-account-slots":          kindUint,
+	"tx-pool-global-slots":           kindUint,
+	"snapshot-cache":                 kindUint,
+	"trie-clean-cache":               kindUint,
+	"trie-dirty-cache":               kindUint,
+	"commit-interval":                kindUint,
+	"chainId":                        kindUint,
+}
+
+// evmBlockForks are block-activated forks, in activation order
+var evmBlockForks = []string{
+	"homesteadBlock", "eip150Block", "eip155Block", "eip158Block",
+	"byzantiumBlock", "constantinopleBlock", "petersburgBlock",
+	"istanbulBlock", "muirGlacierBlock", "berlinBlock", "londonBlock",
+}
+
+// evmTimeForks are timestamp-activated forks, in activation order
+var evmTimeForks = []string{"shanghaiTime", "cancunTime", "pragueTime"}
+
+// ValidateChainConfig checks that an EVM chain config.json is well formed:
+// it must be a JSON object, recognized keys must have the expected types,
+// and fork activations must be non-negative and non-decreasing in fork
+// order. Unknown keys are allowed; use CheckChainConfig to list them.
+func ValidateChainConfig(data []byte) error {
+	_, err := CheckChainConfig(data)
+	return err
+}
+
+// CheckChainConfig validates like ValidateChainConfig and additionally
+// returns a warning for each unrecognized key
+func CheckChainConfig(data []byte) ([]string, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var cfg map[string]interface{}
+	if err := dec.Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("chain config must be a JSON object: %w", err)
+	}
+	if cfg == nil {
+		return nil, fmt.Errorf("chain config must be a JSON object")
+	}
+
+	keys := make([]string, 0, len(cfg))
+	for key := range cfg {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var warnings []string
+	var errs []error
+	for _, key := range keys {
+		kind, known := evmChainConfigKeys[key]
+		switch {
+		case known:
+			if err := checkChainValue(cfg[key], kind); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", key, err))
+			}
+		case contains(evmBlockForks, key) || contains(evmTimeForks, key):
+			// Checked with fork ordering below
+		default:
+			warnings = append(warnings, fmt.Sprintf("unrecognized chain config key %q", key))
+		}
+	}
+
+	for _, forks := range [][]string{evmBlockForks, evmTimeForks} {
+		if err := checkForkOrder(cfg, forks); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return warnings, errors.Join(errs...)
+}
+
+// checkChainValue checks that value has the expected kind
+func checkChainValue(value interface{}, kind chainValueKind) error {
+	switch kind {
+	case kindBool:
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("expected a boolean, got %v", value)
+		}
+	case kindString:
+		if _, ok := value.(string); !ok {
+			return fmt.Errorf("expected a string, got %v", value)
+		}
+	case kindStringList:
+		list, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("expected a list of strings, got %v", value)
+		}
+		for _, item := range list {
+			if _, ok := item.(string); !ok {
+				return fmt.Errorf("expected a list of strings, got element %v", item)
+			}
+		}
+	case kindUint:
+		if _, err := chainUint(value); err != nil {
+			return err
+		}
+	case kindNumber:
+		n, ok := value.(json.Number)
+		if !ok {
+			return fmt.Errorf("expected a number, got %v", value)
+		}
+		if _, err := n.Float64(); err != nil {
+			return fmt.Errorf("expected a number, got %v", value)
+		}
+	case kindDuration:
+		switch v := value.(type) {
+		case string:
+			if _, err := time.ParseDuration(v); err != nil {
+				return fmt.Errorf("invalid duration %q", v)
+			}
+		case json.Number:
+			if _, err := v.Int64(); err != nil {
+				return fmt.Errorf("expected a duration, got %v", value)
+			}
+		default:
+			return fmt.Errorf("expected a duration, got %v", value)
+		}
+	}
+	return nil
+}
+
+// chainUint parses a non-negative integer
+func chainUint(value interface{}) (uint64, error) {
+	n, ok := value.(json.Number)
+	if !ok {
+		return 0, fmt.Errorf("expected a non-negative integer, got %v", value)
+	}
+	i, err := n.Int64()
+	if err != nil || i < 0 {
+		return 0, fmt.Errorf("expected a non-negative integer, got %v", value)
+	}
+	return uint64(i), nil
+}
+
+// checkForkOrder checks that the forks present in cfg activate in order
+func checkForkOrder(cfg map[string]interface{}, forks []string) error {
+	prevFork := ""
+	var prev uint64
+	for _, fork := range forks {
+		value, ok := cfg[fork]
+		if !ok || value == nil {
+			continue
+		}
+		at, err := chainUint(value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", fork, err)
+		}
+		if prevFork != "" && at < prev {
+			return fmt.Errorf("%s (%d) activates before %s (%d)", fork, at, prevFork, prev)
+		}
+		prevFork, prev = fork, at
+	}
+	return nil
+}
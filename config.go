@@ -8,7 +8,10 @@ package config
 
 import (
 	"fmt"
+	"net/url"
+	"os"
 	"path/filepath"
+	"strings"
 )
 
 // LuxConfig is the unified configuration for all Lux components
@@ -27,6 +30,9 @@ type LuxConfig struct {
 
 	// Node contains node-specific configuration
 	Node NodeConfig `json:"node" yaml:"node" mapstructure:"node"`
+
+	// Telemetry contains metrics and tracing configuration
+	Telemetry TelemetryConfig `json:"telemetry" yaml:"telemetry" mapstructure:"telemetry"`
 }
 
 // LogConfig defines unified logging settings
@@ -34,7 +40,7 @@ type LogConfig struct {
 	// Level is the minimum log level to output
 	Level string `json:"level" yaml:"level" mapstructure:"level"`
 
-	// Format is the log output format (terminal, json, plain)
+	// Format is the log output format (terminal, json, plain, logfmt)
 	Format string `json:"format" yaml:"format" mapstructure:"format"`
 
 	// Directory is where log files are written
@@ -57,6 +63,19 @@ type LogConfig struct {
 
 	// ShowColors enables colored output for terminal format
 	ShowColors bool `json:"show-colors" yaml:"show-colors" mapstructure:"show-colors"`
+
+	// TimeKey overrides the encoded timestamp key (default "time")
+	TimeKey string `json:"time-key,omitempty" yaml:"time-key,omitempty" mapstructure:"time-key"`
+
+	// LevelKey overrides the encoded level key (default "level")
+	LevelKey string `json:"level-key,omitempty" yaml:"level-key,omitempty" mapstructure:"level-key"`
+
+	// MessageKey overrides the encoded message key (default "msg")
+	MessageKey string `json:"message-key,omitempty" yaml:"message-key,omitempty" mapstructure:"message-key"`
+
+	// TimeLayout overrides the Go time layout used for timestamps
+	// (default DefaultLogTimeLayout)
+	TimeLayout string `json:"time-layout,omitempty" yaml:"time-layout,omitempty" mapstructure:"time-layout"`
 }
 
 // NetworkConfig defines network-related settings
@@ -83,6 +102,24 @@ type NodeConfig struct {
 	DBType string `json:"db-type" yaml:"db-type" mapstructure:"db-type"`
 }
 
+// TelemetryConfig defines metrics and tracing settings
+type TelemetryConfig struct {
+	// MetricsEnabled exposes the metrics API
+	MetricsEnabled bool `json:"metrics-enabled" yaml:"metrics-enabled" mapstructure:"metrics-enabled"`
+
+	// MetricsPort is the metrics listen port (0 = serve on the HTTP port)
+	MetricsPort int `json:"metrics-port" yaml:"metrics-port" mapstructure:"metrics-port"`
+
+	// TracingEnabled enables OpenTelemetry tracing
+	TracingEnabled bool `json:"tracing-enabled" yaml:"tracing-enabled" mapstructure:"tracing-enabled"`
+
+	// TracingEndpoint is where trace data is sent (empty = exporter default)
+	TracingEndpoint string `json:"tracing-endpoint" yaml:"tracing-endpoint" mapstructure:"tracing-endpoint"`
+
+	// SampleRate is the fraction of traces to sample, from 0 to 1
+	SampleRate float64 `json:"sample-rate" yaml:"sample-rate" mapstructure:"sample-rate"`
+}
+
 // Validate validates the configuration
 func (c *LuxConfig) Validate() error {
 	if c.DataDir == "" {
@@ -94,27 +131,40 @@ func (c *LuxConfig) Validate() error {
 	}
 
 	// Validate log level
-	validLevels := map[string]bool{
-		"verbo": true, "debug": true, "trace": true, "info": true,
-		"warn": true, "error": true, "fatal": true, "off": true,
-	}
-	if !validLevels[c.Log.Level] {
-		return fmt.Errorf("invalid log level: %s", c.Log.Level)
+	if _, err := ParseLogLevel(c.Log.Level); err != nil {
+		return err
 	}
 
 	// Validate log format
 	validFormats := map[string]bool{
-		"terminal": true, "json": true, "plain": true,
+		"terminal": true, "json": true, "plain": true, "logfmt": true,
 	}
 	if !validFormats[c.Log.Format] {
 		return fmt.Errorf("invalid log format: %s", c.Log.Format)
 	}
 
+	// Validate encoder keys, which would collide in structured output
+	if err := c.Log.validateEncoderKeys(); err != nil {
+		return err
+	}
+
+	// Validate database backend
+	if !IsSupportedDBType(c.Node.DBType) {
+		return fmt.Errorf("invalid db-type: %s (must be badgerdb, leveldb, pebbledb, or memdb)", c.Node.DBType)
+	}
+
 	// Validate network
 	if c.Network.ID == 0 {
 		return fmt.Errorf("network.id cannot be zero")
 	}
 
+	// Validate API endpoint (empty means derive from http-port)
+	if c.Network.APIEndpoint != "" {
+		if _, err := NormalizeAPIEndpoint(c.Network.APIEndpoint); err != nil {
+			return fmt.Errorf("invalid api-endpoint: %w", err)
+		}
+	}
+
 	// Validate ports
 	if c.Node.HTTPPort < 1 || c.Node.HTTPPort > 65535 {
 		return fmt.Errorf("invalid http-port: %d", c.Node.HTTPPort)
@@ -123,9 +173,153 @@ func (c *LuxConfig) Validate() error {
 		return fmt.Errorf("invalid staking-port: %d", c.Node.StakingPort)
 	}
 
+	// Validate telemetry
+	if c.Telemetry.MetricsPort < 0 || c.Telemetry.MetricsPort > 65535 {
+		return fmt.Errorf("invalid metrics-port: %d", c.Telemetry.MetricsPort)
+	}
+	if c.Telemetry.SampleRate < 0 || c.Telemetry.SampleRate > 1 {
+		return fmt.Errorf("invalid tracing-sample-rate: %v (must be between 0 and 1)", c.Telemetry.SampleRate)
+	}
+
 	return nil
 }
 
+// configWarningChecks are the non-fatal checks run by ValidateWithWarnings.
+// Each returns a warning message, or "" when the config looks fine.
+var configWarningChecks = []func(c *LuxConfig) string{
+	func(c *LuxConfig) string {
+		if c.Log.Compress && c.Log.MaxAge == 0 {
+			return "log.compress is enabled but log.max-age is 0, so compressed logs are never removed by age"
+		}
+		return ""
+	},
+	func(c *LuxConfig) string {
+		if c.Log.ShowColors && c.Log.Format == "json" {
+			return "log.show-colors has no effect with json log format"
+		}
+		return ""
+	},
+	func(c *LuxConfig) string {
+		if id, ok := LookupNetworkID(c.Network.Name); ok && id != c.Network.ID {
+			return fmt.Sprintf("network.id %d does not match the registered id %d of network %s", c.Network.ID, id, c.Network.Name)
+		}
+		return ""
+	},
+	func(c *LuxConfig) string {
+		if c.DataDir == "" {
+			return ""
+		}
+		if _, err := os.Stat(c.DataDir); os.IsNotExist(err) {
+			return fmt.Sprintf("data-dir %s does not exist yet", c.DataDir)
+		}
+		return ""
+	},
+}
+
+// ValidateWithWarnings validates the configuration like Validate and also
+// returns non-fatal warnings about suspicious settings. Warnings are
+// reported even when validation fails.
+func (c *LuxConfig) ValidateWithWarnings() ([]string, error) {
+	var warnings []string
+	for _, check := range configWarningChecks {
+		if msg := check(c); msg != "" {
+			warnings = append(warnings, msg)
+		}
+	}
+	return warnings, c.Validate()
+}
+
+// NodePortStride is the port offset between consecutive local nodes.
+// Node i uses HTTPPort+i*NodePortStride and StakingPort+i*NodePortStride.
+const NodePortStride = 2
+
+// PortsForNode returns a copy of base with HTTP and staking ports offset for
+// the node at index, so nodes in a local multi-node network don't collide.
+// It fails if a port leaves the valid range or if the base ports are spaced
+// such that one node's HTTP port would equal another node's staking port.
+func PortsForNode(base NodeConfig, index int) (NodeConfig, error) {
+	if index < 0 {
+		return NodeConfig{}, fmt.Errorf("node index must be non-negative: %d", index)
+	}
+
+	diff := base.StakingPort - base.HTTPPort
+	if diff%NodePortStride == 0 {
+		return NodeConfig{}, fmt.Errorf("http-port %d and staking-port %d overlap across nodes with stride %d",
+			base.HTTPPort, base.StakingPort, NodePortStride)
+	}
+
+	cfg := base
+	cfg.HTTPPort = base.HTTPPort + index*NodePortStride
+	cfg.StakingPort = base.StakingPort + index*NodePortStride
+	for _, port := range []int{cfg.HTTPPort, cfg.StakingPort} {
+		if port < 1 || port > 65535 {
+			return NodeConfig{}, fmt.Errorf("port %d for node %d is out of range", port, index)
+		}
+	}
+
+	return cfg, nil
+}
+
+// NormalizeNetworkName returns the canonical form of a network name.
+// Names matching a registered network (mainnet, testnet, local, or one added
+// with RegisterNetwork) case-insensitively are returned in their registered
+// spelling; unknown names are returned verbatim. The bool reports whether
+// the name differed from the canonical form only by case.
+func NormalizeNetworkName(name string) (string, bool) {
+	if known, ok := lookupNetworkName(name); ok {
+		return known, name != known
+	}
+	return name, false
+}
+
+// GetAPIEndpoint returns the normalized API endpoint.
+// If APIEndpoint is empty, it is derived from Node.HTTPPort on localhost.
+func (c *LuxConfig) GetAPIEndpoint() string {
+	if c.Network.APIEndpoint == "" {
+		return DefaultAPIEndpoint(c.Node.HTTPPort)
+	}
+	endpoint, err := NormalizeAPIEndpoint(c.Network.APIEndpoint)
+	if err != nil {
+		return c.Network.APIEndpoint
+	}
+	return endpoint
+}
+
+// DefaultAPIEndpoint returns the local API endpoint for an HTTP port
+func DefaultAPIEndpoint(httpPort int) string {
+	return fmt.Sprintf("http://127.0.0.1:%d", httpPort)
+}
+
+// NormalizeAPIEndpoint validates an API endpoint and returns it in canonical
+// form: an http or https URL with an explicit host and port and no trailing
+// slash. A missing scheme defaults to http.
+func NormalizeAPIEndpoint(endpoint string) (string, error) {
+	endpoint = strings.TrimSpace(endpoint)
+	if endpoint == "" {
+		return "", fmt.Errorf("endpoint cannot be empty")
+	}
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "http://" + endpoint
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse %q: %w", endpoint, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("unsupported scheme %q in %q: must be http or https", u.Scheme, endpoint)
+	}
+	if u.Hostname() == "" {
+		return "", fmt.Errorf("missing host in %q", endpoint)
+	}
+	if u.Port() == "" {
+		return "", fmt.Errorf("missing port in %q", endpoint)
+	}
+
+	u.Path = strings.TrimRight(u.Path, "/")
+	return u.String(), nil
+}
+
 // GetLogPath returns the full path for a named log file
 func (c *LuxConfig) GetLogPath(name string) string {
 	return filepath.Join(c.Log.Directory, name+".log")
@@ -151,11 +345,25 @@ func (c *LuxConfig) GetConfigsPath() string {
 	return filepath.Join(c.DataDir, "configs")
 }
 
-// GetChainsConfigPath returns the chains config directory path
+// Paths returns the directory layout rooted at DataDir
+func (c *LuxConfig) Paths() *Paths {
+	return NewPaths(c.DataDir)
+}
+
+// GetChainsConfigPath returns the chains config directory path, the
+// node's chain-config-dir keyed by chain ID (<DataDir>/configs/chains).
+// ChainManager.CopyChainConfigsToNode with DataDir as the node directory
+// populates it from GetChainDefinitionsPath.
 func (c *LuxConfig) GetChainsConfigPath() string {
 	return filepath.Join(c.GetConfigsPath(), "chains")
 }
 
+// GetChainDefinitionsPath returns the directory ChainManager reads and
+// writes, keyed by chain name (<DataDir>/chains). It is not read by the node.
+func (c *LuxConfig) GetChainDefinitionsPath() string {
+	return c.Paths().ChainsBaseDir()
+}
+
 // GetVMsConfigPath returns the VMs config directory path
 func (c *LuxConfig) GetVMsConfigPath() string {
 	return filepath.Join(c.GetConfigsPath(), "vms")
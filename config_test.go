@@ -4,12 +4,45 @@
 package config
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/spf13/pflag"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"golang.org/x/crypto/ripemd160"
+
+	"github.com/luxfi/config/spec"
 )
 
+// testPluginBinary is a minimal file that passes Install's binary checks
+var testPluginBinary = append([]byte("\x7fELF"), make([]byte, MinPluginBinarySize)...)
+
 func TestDefaultConfig(t *testing.T) {
 	cfg := DefaultConfig()
 
@@ -63,11 +96,31 @@ func TestConfigValidation(t *testing.T) {
 			modify:  func(c *LuxConfig) { c.Network.ID = 0 },
 			wantErr: true,
 		},
+		{
+			name:    "invalid API endpoint scheme",
+			modify:  func(c *LuxConfig) { c.Network.APIEndpoint = "ftp://127.0.0.1:9630" },
+			wantErr: true,
+		},
+		{
+			name:    "API endpoint without port",
+			modify:  func(c *LuxConfig) { c.Network.APIEndpoint = "http://localhost" },
+			wantErr: true,
+		},
 		{
 			name:    "invalid HTTP port",
 			modify:  func(c *LuxConfig) { c.Node.HTTPPort = 0 },
 			wantErr: true,
 		},
+		{
+			name:    "invalid db type",
+			modify:  func(c *LuxConfig) { c.Node.DBType = "pebble" },
+			wantErr: true,
+		},
+		{
+			name:    "memdb db type",
+			modify:  func(c *LuxConfig) { c.Node.DBType = "memdb" },
+			wantErr: false,
+		},
 		{
 			name:    "invalid staking port",
 			modify:  func(c *LuxConfig) { c.Node.StakingPort = 70000 },
@@ -87,6 +140,88 @@ func TestConfigValidation(t *testing.T) {
 	}
 }
 
+func TestNormalizeAPIEndpoint(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"http://127.0.0.1:9630", "http://127.0.0.1:9630", false},
+		{"127.0.0.1:9630", "http://127.0.0.1:9630", false},
+		{"https://api.lux.network:443/", "https://api.lux.network:443", false},
+		{"http://127.0.0.1:9630/ext/bc/C/rpc/", "http://127.0.0.1:9630/ext/bc/C/rpc", false},
+		{"ws://127.0.0.1:9630", "", true},
+		{"http://:9630", "", true},
+		{"", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := NormalizeAPIEndpoint(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NormalizeAPIEndpoint(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("NormalizeAPIEndpoint(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+
+	cfg := DefaultConfig()
+	cfg.Network.APIEndpoint = ""
+	cfg.Node.HTTPPort = 9650
+	if got := cfg.GetAPIEndpoint(); got != "http://127.0.0.1:9650" {
+		t.Errorf("GetAPIEndpoint() = %q, want derived endpoint", got)
+	}
+}
+
+func TestPortsForNode(t *testing.T) {
+	base := DefaultConfig().Node
+
+	seen := make(map[int]bool)
+	for i := 0; i < 5; i++ {
+		cfg, err := PortsForNode(base, i)
+		if err != nil {
+			t.Fatalf("PortsForNode(%d) error = %v", i, err)
+		}
+		for _, port := range []int{cfg.HTTPPort, cfg.StakingPort} {
+			if seen[port] {
+				t.Errorf("port %d reused by node %d", port, i)
+			}
+			seen[port] = true
+		}
+	}
+
+	if _, err := PortsForNode(NodeConfig{HTTPPort: 9630, StakingPort: 9632}, 1); err == nil {
+		t.Error("PortsForNode() accepted overlapping port pairs")
+	}
+	if _, err := PortsForNode(NodeConfig{HTTPPort: 65534, StakingPort: 65535}, 1); err == nil {
+		t.Error("PortsForNode() accepted out-of-range ports")
+	}
+}
+
+func TestNormalizeNetworkName(t *testing.T) {
+	tests := []struct {
+		input  string
+		want   string
+		folded bool
+	}{
+		{"mainnet", "mainnet", false},
+		{"Mainnet", "mainnet", true},
+		{"TESTNET", "testnet", true},
+		{"MyNet", "MyNet", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, folded := NormalizeNetworkName(tt.input)
+			if got != tt.want || folded != tt.folded {
+				t.Errorf("NormalizeNetworkName(%q) = (%q, %v), want (%q, %v)", tt.input, got, folded, tt.want, tt.folded)
+			}
+		})
+	}
+}
+
 func TestExpandPath(t *testing.T) {
 	home, _ := os.UserHomeDir()
 
@@ -275,3 +410,4229 @@ func TestResolvePluginDir(t *testing.T) {
 		t.Errorf("ResolvePluginDir() = %q, want '/custom/data/plugins'", result)
 	}
 }
+
+func TestResolvePluginDirExplained(t *testing.T) {
+	prev := GlobalStore().Get()
+	t.Cleanup(func() { GlobalStore().Set(prev) })
+
+	// LUX_PLUGIN_DIR wins; a package layout resolves to current/
+	envDir := t.TempDir()
+	if _, err := NewPluginPackageManager(envDir); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("LUX_PLUGIN_DIR", envDir)
+	t.Setenv("LUXD_PLUGIN_DIR", t.TempDir())
+	dir, source, err := ResolvePluginDirExplained()
+	if err != nil || dir != filepath.Join(envDir, CurrentPluginsDir) || source != PluginDirSourceEnv {
+		t.Errorf("ResolvePluginDirExplained() = %q, %q, %v; want %s/current from %s", dir, source, err, envDir, PluginDirSourceEnv)
+	}
+
+	// A package layout without current/, e.g. after an interrupted install,
+	// falls back to the base directory
+	bareDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bareDir, registryFile), []byte(`{}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("LUX_PLUGIN_DIR", bareDir)
+	dir, source, err = ResolvePluginDirExplained()
+	if err != nil || dir != bareDir || source != PluginDirSourceEnv+" (packages, no current/)" {
+		t.Errorf("ResolvePluginDirExplained() = %q, %q, %v; want %s without current/", dir, source, err, bareDir)
+	}
+	if dir := ResolvePluginDir(); dir != bareDir {
+		t.Errorf("ResolvePluginDir() = %q, want %s without current/", dir, bareDir)
+	}
+	if dir := nodePluginDir(bareDir); dir != bareDir {
+		t.Errorf("nodePluginDir() = %q, want %s without current/", dir, bareDir)
+	}
+
+	// The legacy env var is next, here with a flat layout
+	t.Setenv("LUX_PLUGIN_DIR", "")
+	flatDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(flatDir, VMID("evm")), testPluginBinary, 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("LUXD_PLUGIN_DIR", flatDir)
+	dir, source, err = ResolvePluginDirExplained()
+	if err != nil || dir != flatDir || source != PluginDirSourceLegacyEnv+" (legacy-flat)" {
+		t.Errorf("ResolvePluginDirExplained() = %q, %q, %v; want %s from legacy env", dir, source, err, flatDir)
+	}
+
+	// Then the global config
+	t.Setenv("LUXD_PLUGIN_DIR", "")
+	cfg := DefaultConfig()
+	cfg.PluginDir = filepath.Join(t.TempDir(), "missing")
+	SetGlobal(cfg)
+	dir, source, err = ResolvePluginDirExplained()
+	if err != nil || dir != cfg.PluginDir || source != PluginDirSourceConfig+" (empty)" {
+		t.Errorf("ResolvePluginDirExplained() = %q, %q, %v; want %s from config", dir, source, err, cfg.PluginDir)
+	}
+}
+
+func TestLoaderSearchPaths(t *testing.T) {
+	home, _ := os.UserHomeDir()
+	tmpDir := t.TempDir()
+
+	loader := NewLoader(WithConfigPaths(tmpDir, "~/lux-test"))
+	got := loader.SearchPaths()
+	want := []string{tmpDir, filepath.Join(home, "lux-test")}
+	if len(got) != len(want) {
+		t.Fatalf("SearchPaths() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("SearchPaths()[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+
+	if _, err := loader.Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !loader.ConfigFileNotFound() {
+		t.Error("ConfigFileNotFound() = false with no config file present")
+	}
+}
+
+func TestWriteSampleConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	for _, format := range []string{"yaml", "toml", "json"} {
+		t.Run(format, func(t *testing.T) {
+			path := filepath.Join(tmpDir, "config."+format)
+			if err := WriteSampleConfig(path, "", false); err != nil {
+				t.Fatalf("WriteSampleConfig() error = %v", err)
+			}
+
+			loader := NewLoader(WithConfigFile(path))
+			cfg, err := loader.Load()
+			if err != nil {
+				t.Fatalf("Load() of sample config error = %v", err)
+			}
+			if cfg.Network.ID != DefaultConfig().Network.ID {
+				t.Errorf("Network.ID = %d, want %d", cfg.Network.ID, DefaultConfig().Network.ID)
+			}
+
+			if err := WriteSampleConfig(path, "", false); err == nil {
+				t.Error("WriteSampleConfig() overwrote an existing file without force")
+			}
+			if err := WriteSampleConfig(path, "", true); err != nil {
+				t.Errorf("WriteSampleConfig() with force error = %v", err)
+			}
+		})
+	}
+
+	// Concurrent writers without force: exactly one creates the file
+	path := filepath.Join(tmpDir, "race", "config.yaml")
+	var created atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if WriteSampleConfig(path, "", false) == nil {
+				created.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := created.Load(); n != 1 {
+		t.Errorf("%d concurrent WriteSampleConfig() calls succeeded, want 1", n)
+	}
+}
+
+func TestLoaderCompressedConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "config.json.gz")
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(`{"log": {"level": "debug"}, "network": {"name": "local", "id": 1337}}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	loader := NewLoader(WithConfigFile(path))
+	cfg, err := loader.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Log.Level != "debug" || cfg.Network.Name != "local" {
+		t.Errorf("Load() = log %s network %s, want debug local", cfg.Log.Level, cfg.Network.Name)
+	}
+	if loader.GetConfigFilePath() != path {
+		t.Errorf("GetConfigFilePath() = %s, want %s", loader.GetConfigFilePath(), path)
+	}
+
+	// Round trip through Save as gzipped YAML
+	saved := filepath.Join(tmpDir, "out", "config.yaml.gz")
+	if err := loader.Save(saved); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	cfg, err = NewLoader(WithConfigFile(saved)).Load()
+	if err != nil {
+		t.Fatalf("Load() of saved config error = %v", err)
+	}
+	if cfg.Network.Name != "local" {
+		t.Errorf("saved Network.Name = %s, want local", cfg.Network.Name)
+	}
+
+	if err := loader.Save(filepath.Join(tmpDir, "config.gz")); err == nil {
+		t.Error("Save() without an inner format succeeded")
+	}
+
+	// Round trip through Save as zstd-compressed TOML
+	saved = filepath.Join(tmpDir, "out", "config.toml.zst")
+	if err := loader.Save(saved); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	cfg, err = NewLoader(WithConfigFile(saved)).Load()
+	if err != nil {
+		t.Fatalf("Load() of zstd config error = %v", err)
+	}
+	if cfg.Network.Name != "local" {
+		t.Errorf("zstd Network.Name = %s, want local", cfg.Network.Name)
+	}
+
+	// Compressed files are found in the search paths, after any
+	// uncompressed file in the same directory
+	searched := NewLoader(WithConfigPaths(tmpDir))
+	if _, err := searched.Load(); err != nil {
+		t.Fatalf("Load() from search paths error = %v", err)
+	}
+	if searched.GetConfigFilePath() != path {
+		t.Errorf("GetConfigFilePath() = %s, want %s", searched.GetConfigFilePath(), path)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "config.yaml"), []byte("log:\n  level: warn\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err = searched.Load()
+	if err != nil {
+		t.Fatalf("Load() from search paths error = %v", err)
+	}
+	if cfg.Log.Level != "warn" {
+		t.Errorf("Log.Level = %s, want warn from the uncompressed file", cfg.Log.Level)
+	}
+	if files := searched.ConflictingConfigFiles(); len(files) != 1 || files[0] != path {
+		t.Errorf("ConflictingConfigFiles() = %v, want [%s]", files, path)
+	}
+}
+
+func TestRegisterConfigValidator(t *testing.T) {
+	t.Cleanup(ResetConfigValidators)
+
+	var order []string
+	RegisterConfigValidator(func(cfg *LuxConfig) error {
+		order = append(order, "first")
+		if cfg.Network.Name == NetworkTestnet && cfg.GetDBPath() == DefaultConfig().GetDBPath() {
+			return fmt.Errorf("testnet must not use the mainnet db path")
+		}
+		return nil
+	})
+	RegisterConfigValidator(func(cfg *LuxConfig) error {
+		order = append(order, "second")
+		return fmt.Errorf("second rule failed")
+	})
+
+	t.Setenv("LUX_NETWORK_NAME", NetworkTestnet)
+	t.Setenv("LUX_NETWORK_ID", "96368")
+	_, err := NewLoader().LoadFrom(strings.NewReader("{}"), "json")
+	if err == nil {
+		t.Fatal("LoadFrom() passed with failing validators")
+	}
+	for _, msg := range []string{"mainnet db path", "second rule failed"} {
+		if !strings.Contains(err.Error(), msg) {
+			t.Errorf("LoadFrom() error = %v, want %q", err, msg)
+		}
+	}
+	if strings.Join(order, ",") != "first,second" {
+		t.Errorf("validators ran in order %v", order)
+	}
+
+	ResetConfigValidators()
+	if _, err := NewLoader().LoadFrom(strings.NewReader("{}"), "json"); err != nil {
+		t.Errorf("LoadFrom() after reset error = %v", err)
+	}
+}
+
+func TestLoaderDeprecatedInUse(t *testing.T) {
+	s := spec.MustSpec().Merge([]spec.FlagSpec{
+		{Key: "old-file-key", Type: spec.TypeString, Deprecated: true, DeprecatedMessage: "no longer used"},
+		{Key: "old-env-key", Type: spec.TypeString, Deprecated: true, ReplacedBy: "new-env-key"},
+		{Key: "old-unset-key", Type: spec.TypeString, Deprecated: true},
+	}, nil)
+	spec.SetSpec(s)
+	t.Cleanup(func() { spec.SetSpec(nil) })
+
+	t.Setenv("LUX_OLD_ENV_KEY", "x")
+	loader := NewLoader()
+	if _, err := loader.LoadFrom(strings.NewReader(`{"old-file-key": "y"}`), "json"); err != nil {
+		t.Fatal(err)
+	}
+
+	usages, err := loader.DeprecatedInUse()
+	if err != nil {
+		t.Fatalf("DeprecatedInUse() error = %v", err)
+	}
+	if len(usages) != 2 {
+		t.Fatalf("DeprecatedInUse() = %+v, want 2 usages", usages)
+	}
+	if u := usages[0]; u.Key != "old-env-key" || u.Source != SourceEnv || u.EnvVar != "LUX_OLD_ENV_KEY" || u.ReplacedBy != "new-env-key" {
+		t.Errorf("usages[0] = %+v", u)
+	}
+	if u := usages[1]; u.Key != "old-file-key" || u.Source != SourceFile || u.Message != "no longer used" {
+		t.Errorf("usages[1] = %+v", u)
+	}
+}
+
+func TestDetectPreferredDBType(t *testing.T) {
+	tests := []struct {
+		goos, goarch string
+		tags         []string
+		want         string
+	}{
+		{"linux", "amd64", nil, "badgerdb"},
+		{"linux", "amd64", []string{"netgo", "pebbledb"}, "pebbledb"},
+		{"linux", "arm", nil, "leveldb"},
+		{"js", "wasm", nil, "memdb"},
+	}
+	for _, tt := range tests {
+		if got := preferredDBType(tt.goos, tt.goarch, tt.tags); got != tt.want {
+			t.Errorf("preferredDBType(%s, %s, %v) = %s, want %s", tt.goos, tt.goarch, tt.tags, got, tt.want)
+		}
+	}
+
+	if got := DetectPreferredDBType(); !IsSupportedDBType(got) {
+		t.Errorf("DetectPreferredDBType() = %s, not a supported db-type", got)
+	}
+	cfg, err := NewLoader(WithDetectedDBType()).LoadFrom(strings.NewReader("{}"), "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Node.DBType != DetectPreferredDBType() {
+		t.Errorf("Node.DBType = %s, want detected %s", cfg.Node.DBType, DetectPreferredDBType())
+	}
+}
+
+func TestLoaderFlagPrecedence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"log": {"level": "debug"}, "node": {"http-port": 9000}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	load := func(args ...string) (*Loader, *LuxConfig) {
+		t.Helper()
+		fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		AddAllFlags(fs)
+		if err := fs.Parse(args); err != nil {
+			t.Fatal(err)
+		}
+		loader := NewLoader(WithConfigFile(path))
+		if err := loader.BindFlags(fs); err != nil {
+			t.Fatal(err)
+		}
+		cfg, err := loader.Load()
+		if err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+		return loader, cfg
+	}
+
+	// Unset flags don't replace configured values with their defaults
+	loader, cfg := load()
+	if cfg.Log.Level != "debug" || cfg.Node.HTTPPort != 9000 {
+		t.Errorf("without flags: level %s port %d, want debug 9000", cfg.Log.Level, cfg.Node.HTTPPort)
+	}
+	if src := loader.Source("log.level"); src != SourceFile {
+		t.Errorf("Source(log.level) = %s, want file", src)
+	}
+
+	// Flags set on the command line win
+	loader, cfg = load("--log-level=warn", "--http-port=9100")
+	if cfg.Log.Level != "warn" || cfg.Node.HTTPPort != 9100 {
+		t.Errorf("with flags: level %s port %d, want warn 9100", cfg.Log.Level, cfg.Node.HTTPPort)
+	}
+	if src := loader.Source("log.level"); src != SourceFlag {
+		t.Errorf("Source(log.level) = %s, want flag", src)
+	}
+}
+
+func TestLoaderProfiles(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	base := `{"network": {"name": "mainnet"}, "log": {"level": "warn"}}`
+	dev := "network:\n  name: local\n  id: 1337\n"
+	if err := os.WriteFile(filepath.Join(tmpDir, "config.json"), []byte(base), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "config.dev.yaml"), []byte(dev), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "config.ci.toml"), []byte("[log]\nlevel = \"debug\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	loader := NewLoader(WithConfigPaths(tmpDir), WithProfile("dev"))
+	cfg, err := loader.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Network.Name != "local" || cfg.Network.ID != 1337 {
+		t.Errorf("profile not merged: network = %+v", cfg.Network)
+	}
+	if cfg.Log.Level != "warn" {
+		t.Errorf("base value lost: log level = %q, want warn", cfg.Log.Level)
+	}
+
+	profiles := loader.Profiles()
+	if len(profiles) != 2 || profiles[0] != "ci" || profiles[1] != "dev" {
+		t.Errorf("Profiles() = %v, want [ci dev]", profiles)
+	}
+
+	if _, err := NewLoader(WithConfigPaths(tmpDir), WithProfile("missing")).Load(); err == nil {
+		t.Error("Load() with missing profile should fail")
+	}
+}
+
+func TestBindAndValidate(t *testing.T) {
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	AddAllFlags(fs)
+	if err := fs.Parse([]string{"--db-type=leveldb"}); err != nil {
+		t.Fatal(err)
+	}
+
+	loader := NewLoader(WithConfigPaths(t.TempDir()))
+	cfg, violations, err := loader.BindAndValidate(fs)
+	if err != nil {
+		t.Fatalf("BindAndValidate() error = %v", err)
+	}
+	if cfg == nil {
+		t.Fatal("BindAndValidate() returned nil config")
+	}
+
+	// The node spec does not accept leveldb for db-type
+	if len(violations) != 1 || violations[0].Key != DBTypeKey {
+		t.Errorf("violations = %v, want one for %s", violations, DBTypeKey)
+	}
+}
+
+func TestForceSetGlobalGPUConfig(t *testing.T) {
+	// Restore the global GPU state so other tests see it untouched
+	globalGPUMu.RLock()
+	prevCfg, prevSet := globalGPUConfig, globalGPUConfigSet
+	globalGPUMu.RUnlock()
+	t.Cleanup(func() {
+		globalGPUMu.Lock()
+		defer globalGPUMu.Unlock()
+		globalGPUConfig, globalGPUConfigSet = prevCfg, prevSet
+		if !prevSet {
+			globalGPUConfigOnce = sync.Once{}
+		}
+	})
+
+	var calls []GPUConfig
+	t.Cleanup(RegisterGPUReloadHandler(func(cfg GPUConfig) {
+		calls = append(calls, cfg)
+	}))
+
+	cfg := DefaultGPUConfig()
+	cfg.Backend = "cpu"
+	if err := ForceSetGlobalGPUConfig(cfg); err != nil {
+		t.Fatalf("ForceSetGlobalGPUConfig() error = %v", err)
+	}
+	if GPUBackend() != "cpu" {
+		t.Errorf("GPUBackend() = %q, want cpu", GPUBackend())
+	}
+
+	// Reapplying the same config must not fire handlers again
+	if err := ForceSetGlobalGPUConfig(cfg); err != nil {
+		t.Fatalf("ForceSetGlobalGPUConfig() error = %v", err)
+	}
+	if len(calls) != 1 {
+		t.Errorf("reload handler called %d times, want 1", len(calls))
+	}
+
+	cfg.Backend = "invalid"
+	if err := ForceSetGlobalGPUConfig(cfg); err == nil {
+		t.Error("ForceSetGlobalGPUConfig() accepted an invalid backend")
+	}
+
+	// An unregistered handler is not called again
+	var removedCalls int
+	unregister := RegisterGPUReloadHandler(func(GPUConfig) { removedCalls++ })
+	unregister()
+	cfg.Backend = "auto"
+	if err := ForceSetGlobalGPUConfig(cfg); err != nil {
+		t.Fatalf("ForceSetGlobalGPUConfig() error = %v", err)
+	}
+	if removedCalls != 0 {
+		t.Errorf("unregistered handler called %d times, want 0", removedCalls)
+	}
+	if len(calls) != 2 {
+		t.Errorf("reload handler called %d times, want 2", len(calls))
+	}
+}
+
+func TestInstallFromURLResume(t *testing.T) {
+	content := append(append([]byte(nil), testPluginBinary...), bytes.Repeat([]byte("plugin-binary-"), 1024)...)
+	sum := sha256.Sum256(content)
+	checksum := hex.EncodeToString(sum[:])
+
+	var rangeRequests int
+	interrupt := true
+	modified := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Range") != "" {
+			rangeRequests++
+		}
+		if interrupt && r.Header.Get("Range") == "" {
+			// Send half the file, then drop the connection
+			interrupt = false
+			w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+			w.Header().Set("Last-Modified", modified.Format(http.TimeFormat))
+			w.Write(content[:len(content)/2])
+			panic(http.ErrAbortHandler)
+		}
+		http.ServeContent(w, r, "evm", modified, bytes.NewReader(content))
+	}))
+	defer srv.Close()
+
+	pm, err := NewPluginPackageManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest := &PluginManifest{Org: "luxfi", Name: "evm", Version: "v1.0.0", VMID: VMID(VMNameLuxEVM)}
+	partPath := pm.partPath(manifest.Org, manifest.Name, manifest.Version)
+
+	if err := pm.InstallFromURL(context.Background(), manifest, srv.URL, checksum); err == nil {
+		t.Fatal("InstallFromURL() succeeded on an interrupted download")
+	}
+	if !Exists(partPath) || !Exists(partMetaPath(partPath)) {
+		t.Fatal("interrupted download did not keep its partial file and metadata")
+	}
+
+	if err := pm.InstallFromURL(context.Background(), manifest, srv.URL, checksum); err != nil {
+		t.Fatalf("InstallFromURL() error = %v", err)
+	}
+	if rangeRequests != 1 {
+		t.Errorf("range requests = %d, want 1", rangeRequests)
+	}
+	if Exists(partPath) || Exists(partMetaPath(partPath)) {
+		t.Error("partial download not cleaned up")
+	}
+
+	installed, err := os.ReadFile(filepath.Join(pm.PackagePath("luxfi", "evm", "v1.0.0"), "evm"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(installed, content) {
+		t.Error("installed binary does not match downloaded content")
+	}
+
+	// A partial file from another URL, or without a checksum, is not resumed
+	manifest.Version = "v1.0.2"
+	interrupt = true
+	_ = pm.InstallFromURL(context.Background(), manifest, srv.URL+"/other", checksum)
+	if err := pm.InstallFromURL(context.Background(), manifest, srv.URL, checksum); err != nil {
+		t.Fatal(err)
+	}
+	manifest.Version = "v1.0.3"
+	interrupt = true
+	_ = pm.InstallFromURL(context.Background(), manifest, srv.URL, "")
+	if err := pm.InstallFromURL(context.Background(), manifest, srv.URL, ""); err != nil {
+		t.Fatal(err)
+	}
+	if rangeRequests != 1 {
+		t.Errorf("range requests = %d, want no resume for a different URL or without checksum", rangeRequests)
+	}
+
+	// A range response that does not start at the partial size restarts
+	if !contentRangeStartsAt("bytes 100-199/200", 100) || contentRangeStartsAt("bytes 0-199/200", 100) || contentRangeStartsAt("", 100) {
+		t.Error("contentRangeStartsAt() mismatch")
+	}
+
+	manifest.Version = "v1.0.1"
+	if err := pm.InstallFromURL(context.Background(), manifest, srv.URL, strings.Repeat("0", 64)); err == nil {
+		t.Error("InstallFromURL() accepted a checksum mismatch")
+	}
+	if Exists(pm.partPath(manifest.Org, manifest.Name, manifest.Version)) {
+		t.Error("partial download kept after checksum mismatch")
+	}
+}
+
+func TestPluginPackageManagerVerifyAll(t *testing.T) {
+	pm, err := NewPluginPackageManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	binary := filepath.Join(t.TempDir(), "evm")
+	if err := os.WriteFile(binary, testPluginBinary, 0755); err != nil {
+		t.Fatal(err)
+	}
+	good := &PluginManifest{Org: "luxfi", Name: "evm", Version: "v1.0.0", VMName: VMNameLuxEVM, VMID: VMID(VMNameLuxEVM)}
+	if err := pm.Install(ctx, good, binary); err != nil {
+		t.Fatal(err)
+	}
+	bad := &PluginManifest{Org: "luxfi", Name: "corevm", Version: "v1.0.0", VMName: VMNameCoreVM, VMID: VMID(VMNameCoreVM)}
+	if err := pm.Install(ctx, bad, binary); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(pm.PackagePath("luxfi", "corevm", "v1.0.0"), "corevm")); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := pm.VerifyAll(ctx)
+	if err != nil {
+		t.Fatalf("VerifyAll() error = %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("VerifyAll() returned %d results, want 2", len(results))
+	}
+	for _, r := range results {
+		wantOK := r.Name == "evm"
+		if r.OK != wantOK {
+			t.Errorf("%s/%s OK = %v (%s), want %v", r.Org, r.Name, r.OK, r.Reason, wantOK)
+		}
+	}
+}
+
+func TestCompareSemver(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"v1.0.0", "v1.0.0", 0},
+		{"v1.2.0", "v1.10.0", -1},
+		{"1.0.1", "v1.0.0", 1},
+		{"v1.0.0-rc.1", "v1.0.0", -1},
+		{"v1.0.0-alpha", "v1.0.0-alpha.1", -1},
+		{"v1.0.0-alpha.2", "v1.0.0-alpha.10", -1},
+		{"v1.0.0-1", "v1.0.0-alpha", -1},
+		{"v1.0.0+build1", "v1.0.0+build2", 0},
+		{"latest", "v0.0.1", -1},
+	}
+
+	for _, tt := range tests {
+		if got := CompareSemver(tt.a, tt.b); got != tt.want {
+			t.Errorf("CompareSemver(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+
+	if IsValidSemver("v1.02.0") || IsValidSemver("v1.0") || !IsValidSemver("v1.0.0-rc.1+sha.abc") {
+		t.Error("IsValidSemver() returned unexpected result")
+	}
+	if got := LatestSemver([]string{"v1.2.0", "v1.10.0", "v1.9.9"}); got != "v1.10.0" {
+		t.Errorf("LatestSemver() = %q, want v1.10.0", got)
+	}
+}
+
+func TestPluginPackageManagerListOrder(t *testing.T) {
+	pm, err := NewPluginPackageManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	binary := filepath.Join(t.TempDir(), "vm")
+	if err := os.WriteFile(binary, testPluginBinary, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range []PluginManifest{
+		{Org: "luxfi", Name: "evm", Version: "v1.2.0", VMID: VMID("vm-b")},
+		{Org: "luxfi", Name: "evm", Version: "v1.10.0", VMID: VMID("vm-b")},
+		{Org: "acme", Name: "zvm", Version: "v0.1.0", VMID: VMID("vm-a")},
+	} {
+		if err := pm.Install(ctx, &m, binary); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	manifests, err := pm.List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, m := range manifests {
+		got = append(got, m.Org+"/"+m.Name+"@"+m.Version)
+	}
+	want := []string{"acme/zvm@v0.1.0", "luxfi/evm@v1.10.0", "luxfi/evm@v1.2.0"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("List() order = %v, want %v", got, want)
+	}
+
+	active, err := pm.ListActiveSorted(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(active) != 2 || active[0].VMID != VMID("vm-a") || active[1].VMID != VMID("vm-b") {
+		t.Errorf("ListActiveSorted() = %v, want vm-a then vm-b", active)
+	}
+}
+
+func TestPluginPackageManagerAliases(t *testing.T) {
+	pm, err := NewPluginPackageManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	binary := filepath.Join(t.TempDir(), "vm")
+	if err := os.WriteFile(binary, testPluginBinary, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	evm := &PluginManifest{Org: "luxfi", Name: "evm", Version: "v1.0.0", VMID: VMID("vm-evm"), Aliases: []string{"subnetevm"}}
+	if err := pm.Install(ctx, evm, binary); err != nil {
+		t.Fatal(err)
+	}
+	for _, alias := range []string{"evm", "subnetevm"} {
+		target, err := os.Readlink(pm.AliasPath(alias))
+		if err != nil {
+			t.Fatalf("alias %s not created: %v", alias, err)
+		}
+		if want := filepath.Join(pm.PackagePath("luxfi", "evm", "v1.0.0"), "evm"); target != want {
+			t.Errorf("alias %s -> %s, want %s", alias, target, want)
+		}
+	}
+
+	// A second package claiming the same alias does not steal it
+	fork := &PluginManifest{Org: "acme", Name: "evmfork", Version: "v0.1.0", VMID: VMID("vm-fork"), Aliases: []string{"subnetevm"}}
+	if err := pm.Install(ctx, fork, binary); err != nil {
+		t.Fatal(err)
+	}
+	if owner := pm.registry.Aliases["subnetevm"]; owner != "luxfi/evm@v1.0.0" {
+		t.Errorf("alias subnetevm owned by %s, want luxfi/evm@v1.0.0", owner)
+	}
+
+	if err := pm.Uninstall(ctx, "luxfi", "evm", "v1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Lstat(pm.AliasPath("subnetevm")); !os.IsNotExist(err) {
+		t.Error("alias subnetevm not removed on uninstall")
+	}
+}
+
+func TestDefaultPluginManagerInstallVerified(t *testing.T) {
+	tmpDir := t.TempDir()
+	pm := NewPluginManagerWithDir(filepath.Join(tmpDir, "plugins")).(*DefaultPluginManager)
+
+	content := []byte("plugin content")
+	source := filepath.Join(tmpDir, "source")
+	if err := os.WriteFile(source, content, 0755); err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(content)
+	want := hex.EncodeToString(sum[:])
+
+	got, err := pm.InstallVerified(context.Background(), source, "vm", want)
+	if err != nil {
+		t.Fatalf("InstallVerified() error = %v", err)
+	}
+	if got != want {
+		t.Errorf("InstallVerified() = %s, want %s", got, want)
+	}
+
+	if _, err := pm.InstallVerified(context.Background(), source, "vm2", strings.Repeat("0", 64)); err == nil {
+		t.Error("InstallVerified() accepted a checksum mismatch")
+	}
+	if pm.Exists("vm2") {
+		t.Error("InstallVerified() left a plugin behind after a checksum mismatch")
+	}
+}
+
+func TestDefaultPluginManagerCopyOptions(t *testing.T) {
+	tmpDir := t.TempDir()
+	content := bytes.Repeat([]byte("0123456789"), 1000)
+	source := filepath.Join(tmpDir, "source")
+	if err := os.WriteFile(source, content, 0755); err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(content)
+	want := hex.EncodeToString(sum[:])
+
+	for _, strategy := range []CopyStrategy{CopyBuffered, CopyKernel} {
+		pm := NewPluginManagerWithDir(filepath.Join(tmpDir, "plugins"),
+			WithCopyBufferSize(4096), WithCopyStrategy(strategy), WithOverwritePolicy(Overwrite)).(*DefaultPluginManager)
+
+		got, err := pm.InstallVerified(context.Background(), source, "vm", want)
+		if err != nil {
+			t.Fatalf("strategy %d: InstallVerified() error = %v", strategy, err)
+		}
+		if got != want {
+			t.Errorf("strategy %d: InstallVerified() = %s, want %s", strategy, got, want)
+		}
+		if data, _ := os.ReadFile(pm.GetPath("vm")); !bytes.Equal(data, content) {
+			t.Errorf("strategy %d: installed binary differs from source", strategy)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if err := pm.Install(ctx, source, "cancelled"); !errors.Is(err, context.Canceled) {
+			t.Errorf("strategy %d: Install() with cancelled context error = %v", strategy, err)
+		}
+		if pm.Exists("cancelled") {
+			t.Errorf("strategy %d: cancelled install left a plugin behind", strategy)
+		}
+	}
+}
+
+func TestDefaultPluginManagerOverwritePolicy(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldSource := filepath.Join(tmpDir, "old")
+	newSource := filepath.Join(tmpDir, "new")
+	if err := os.WriteFile(oldSource, []byte("old binary"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(newSource, []byte("new binary"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	pluginDir := filepath.Join(tmpDir, "plugins")
+	ctx := context.Background()
+
+	pm := NewPluginManagerWithDir(pluginDir)
+	if err := pm.Install(ctx, oldSource, "vm"); err != nil {
+		t.Fatal(err)
+	}
+	if err := pm.Install(ctx, newSource, "vm"); !errors.Is(err, ErrExists) {
+		t.Errorf("Install() over existing plugin error = %v, want ErrExists", err)
+	}
+
+	skip := NewPluginManagerWithDir(pluginDir, WithOverwritePolicy(SkipIfExists))
+	if err := skip.Install(ctx, newSource, "vm"); err != nil {
+		t.Errorf("Install() with SkipIfExists error = %v", err)
+	}
+	if data, _ := os.ReadFile(pm.GetPath("vm")); string(data) != "old binary" {
+		t.Errorf("plugin = %q after skip, want old binary", data)
+	}
+
+	// A handle opened before the overwrite keeps reading the old binary
+	running, err := os.Open(pm.GetPath("vm"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer running.Close()
+	overwrite := NewPluginManagerWithDir(pluginDir, WithOverwritePolicy(Overwrite))
+	if err := overwrite.Install(ctx, newSource, "vm"); err != nil {
+		t.Fatalf("Install() with Overwrite error = %v", err)
+	}
+	if data, _ := os.ReadFile(pm.GetPath("vm")); string(data) != "new binary" {
+		t.Errorf("plugin = %q after overwrite, want new binary", data)
+	}
+	if data, _ := io.ReadAll(running); string(data) != "old binary" {
+		t.Errorf("open handle read %q, want old binary", data)
+	}
+	if entries, _ := os.ReadDir(pluginDir); len(entries) != 1 {
+		t.Errorf("plugin dir has %d entries, want no leftover temp files", len(entries))
+	}
+}
+
+func TestDetectPluginLayout(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	layout, err := DetectPluginLayout(filepath.Join(tmpDir, "missing"))
+	if err != nil || layout != LayoutEmpty {
+		t.Errorf("missing dir: layout = %v, err = %v, want empty", layout, err)
+	}
+
+	flat := filepath.Join(tmpDir, "flat")
+	if err := os.MkdirAll(flat, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(flat, VMID(VMNameLuxEVM)), testPluginBinary, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if layout, _ := DetectPluginLayout(flat); layout != LayoutLegacyFlat {
+		t.Errorf("flat dir: layout = %v, want legacy-flat", layout)
+	}
+
+	pkgs := filepath.Join(tmpDir, "pkgs")
+	if _, err := NewPluginPackageManager(pkgs); err != nil {
+		t.Fatal(err)
+	}
+	if layout, _ := DetectPluginLayout(pkgs); layout != LayoutPackages {
+		t.Errorf("package dir: layout = %v, want packages", layout)
+	}
+}
+
+func TestResolvePluginBinary(t *testing.T) {
+	paths := NewPaths(t.TempDir())
+	if err := paths.EnsureCurrentPluginsDir(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := paths.ResolvePluginBinary("missing"); !errors.Is(err, ErrPluginNotFound) {
+		t.Errorf("missing plugin: err = %v, want ErrPluginNotFound", err)
+	}
+
+	binary := filepath.Join(paths.BaseDir, "evm")
+	if err := os.WriteFile(binary, testPluginBinary, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(binary, paths.PluginPath("good")); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := paths.ResolvePluginBinary("good"); err != nil || got != binary {
+		t.Errorf("ResolvePluginBinary() = %q, %v, want %q", got, err, binary)
+	}
+
+	if err := os.Symlink(filepath.Join(paths.BaseDir, "gone"), paths.PluginPath("dangling")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := paths.ResolvePluginBinary("dangling"); !errors.Is(err, ErrPluginDangling) {
+		t.Errorf("dangling plugin: err = %v, want ErrPluginDangling", err)
+	}
+
+	if err := os.Symlink("loop", paths.PluginPath("loop")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := paths.ResolvePluginBinary("loop"); !errors.Is(err, ErrSymlinkLoop) {
+		t.Errorf("looping plugin: err = %v, want ErrSymlinkLoop", err)
+	}
+}
+
+func TestPathsPermissionPolicy(t *testing.T) {
+	paths := NewPaths(t.TempDir())
+	if err := paths.WriteNodeKeyFile(NetworkLocal, "node1", StakingKeyFile, []byte("key")); err != nil {
+		t.Fatalf("WriteNodeKeyFile() error = %v", err)
+	}
+
+	info, err := os.Stat(paths.NodeStakingKey(NetworkLocal, "node1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0600 {
+		t.Errorf("key file mode = %o, want 600", mode)
+	}
+	info, err = os.Stat(paths.NodeKeysDir(NetworkLocal, "node1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0700 {
+		t.Errorf("key dir mode = %o, want 700", mode)
+	}
+
+	policy := DefaultPermissionPolicy()
+	policy.DirMode = 0750
+	strict := NewPathsWithPolicy(t.TempDir(), policy)
+	if err := strict.EnsureChainDir("zoo"); err != nil {
+		t.Fatal(err)
+	}
+	info, err = os.Stat(strict.ChainDir("zoo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode&^0750 != 0 {
+		t.Errorf("chain dir mode = %o, want at most 750", mode)
+	}
+}
+
+func TestPluginManifestValidate(t *testing.T) {
+	valid := PluginManifest{Org: "luxfi", Name: "evm", Version: "v1.0.0", VMName: VMNameLuxEVM, VMID: VMID(VMNameLuxEVM)}
+
+	tests := []struct {
+		name    string
+		modify  func(*PluginManifest)
+		wantErr bool
+	}{
+		{"valid", func(m *PluginManifest) {}, false},
+		{"missing org", func(m *PluginManifest) { m.Org = "" }, true},
+		{"bad version", func(m *PluginManifest) { m.Version = "latest" }, true},
+		{"missing vmid", func(m *PluginManifest) { m.VMID = "" }, true},
+		{"vmid mismatch", func(m *PluginManifest) { m.VMID = VMID(VMNameAVM) }, true},
+		{"binary path", func(m *PluginManifest) { m.Binary = "bin/evm" }, true},
+		{"duplicate alias", func(m *PluginManifest) { m.Aliases = []string{"a", "a"} }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := valid
+			tt.modify(&m)
+			if err := m.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMigrateBaseDir(t *testing.T) {
+	oldPaths := NewPaths(filepath.Join(t.TempDir(), "old"))
+	cm := NewChainManager(oldPaths)
+	if err := cm.SaveGenesis("zoo", []byte(`{"config":{"chainId":200200}}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	pm, err := NewPluginPackageManager(oldPaths.PluginsBaseDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	binary := filepath.Join(t.TempDir(), "evm")
+	if err := os.WriteFile(binary, testPluginBinary, 0755); err != nil {
+		t.Fatal(err)
+	}
+	manifest := &PluginManifest{Org: "luxfi", Name: "evm", Version: "v1.0.0", VMID: VMID(VMNameLuxEVM)}
+	if err := pm.Install(context.Background(), manifest, binary); err != nil {
+		t.Fatal(err)
+	}
+
+	// The cross-filesystem fallback copies read-only packages with their
+	// modes intact and can remove them afterwards
+	pkgPath := pm.PackagePath("luxfi", "evm", "v1.0.0")
+	copied := filepath.Join(t.TempDir(), "copy")
+	if err := copyTree(context.Background(), pkgPath, copied); err != nil {
+		t.Fatalf("copyTree() error = %v", err)
+	}
+	for _, path := range []string{pkgPath, filepath.Join(pkgPath, "manifest.json")} {
+		want, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rel, _ := filepath.Rel(pkgPath, path)
+		got, err := os.Stat(filepath.Join(copied, rel))
+		if err != nil {
+			t.Fatalf("copied %s missing: %v", rel, err)
+		}
+		if got.Mode().Perm() != want.Mode().Perm() {
+			t.Errorf("copied %s mode = %v, want %v", rel, got.Mode().Perm(), want.Mode().Perm())
+		}
+	}
+	if err := removeTree(copied); err != nil || Exists(copied) {
+		t.Errorf("removeTree() error = %v, exists = %v", err, Exists(copied))
+	}
+
+	// A migration that fails after moving every tree, here while rewriting
+	// symlinks, moves them all back
+	failedBase := filepath.Join(t.TempDir(), "failed")
+	if _, err := oldPaths.MigrateBaseDirContext(errOnlyContext{context.Background()}, failedBase); !errors.Is(err, context.Canceled) {
+		t.Fatalf("MigrateBaseDirContext() error = %v, want context.Canceled", err)
+	}
+	if !NewChainManager(oldPaths).ChainExists("zoo") {
+		t.Error("failed migration did not restore the chains tree")
+	}
+	if Exists(filepath.Join(failedBase, ChainsDir)) || Exists(filepath.Join(failedBase, PluginsDir)) {
+		t.Error("failed migration left trees in the new base directory")
+	}
+	if _, err := oldPaths.ResolvePluginBinary(manifest.VMID); err != nil {
+		t.Errorf("failed migration broke the plugin symlink: %v", err)
+	}
+
+	newPaths, err := oldPaths.MigrateBaseDir(filepath.Join(t.TempDir(), "new"))
+	if err != nil {
+		t.Fatalf("MigrateBaseDir() error = %v", err)
+	}
+
+	if Exists(oldPaths.ChainsBaseDir()) {
+		t.Error("old chains directory still exists")
+	}
+	if !NewChainManager(newPaths).ChainExists("zoo") {
+		t.Error("chain not migrated")
+	}
+	if _, err := newPaths.ResolvePluginBinary(manifest.VMID); err != nil {
+		t.Errorf("plugin symlink not rewritten: %v", err)
+	}
+}
+
+// errOnlyContext reports cancellation from Err but never closes Done, so
+// work that only selects on Done proceeds until the first Err check
+type errOnlyContext struct{ context.Context }
+
+func (errOnlyContext) Done() <-chan struct{} { return nil }
+func (errOnlyContext) Err() error            { return context.Canceled }
+
+func TestLoaderCoercesSpecTypes(t *testing.T) {
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	fs.String("uptime-metric-freq", "", "")
+	if err := fs.Parse([]string{"--uptime-metric-freq=1h"}); err != nil {
+		t.Fatal(err)
+	}
+
+	loader := NewLoader(WithConfigPaths(t.TempDir()))
+	if err := loader.BindFlags(fs); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loader.Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if d, ok := loader.Get("uptime-metric-freq").(time.Duration); !ok || d != time.Hour {
+		t.Errorf("uptime-metric-freq = %#v, want 1h duration", loader.Get("uptime-metric-freq"))
+	}
+
+	if err := fs.Set("uptime-metric-freq", "soon"); err != nil {
+		t.Fatal(err)
+	}
+	// A value that cannot be coerced is reported per key, not fatal
+	if _, err := loader.Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if errs := loader.CoercionErrors(); len(errs) != 1 || errs[0].Key != "uptime-metric-freq" {
+		t.Errorf("CoercionErrors() = %v, want one error for uptime-metric-freq", errs)
+	}
+	if !strings.Contains(strings.Join(loader.Warnings(), "\n"), "uptime-metric-freq") {
+		t.Errorf("Warnings() = %v, want a coercion warning for uptime-metric-freq", loader.Warnings())
+	}
+
+	// Coerced values reach the typed config, not just Get
+	t.Setenv("LUX_NODE_HTTP_PORT", " 9700 ")
+	cfg, err := NewLoader(WithConfigPaths(t.TempDir())).Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Node.HTTPPort != 9700 {
+		t.Errorf("Node.HTTPPort = %d, want 9700 coerced from the env var", cfg.Node.HTTPPort)
+	}
+}
+
+func TestChainManagerExtraFiles(t *testing.T) {
+	paths := NewPaths(t.TempDir())
+	cm := NewChainManager(paths)
+
+	cc := &ChainConfig{
+		Name:    "zoo",
+		Genesis: []byte(`{"config":{"chainId":200200}}`),
+		Extra:   map[string][]byte{"allowlist.json": []byte(`["0x01"]`)},
+	}
+	if err := cm.SaveChain(cc); err != nil {
+		t.Fatalf("SaveChain() error = %v", err)
+	}
+
+	loaded, err := cm.LoadChain("zoo")
+	if err != nil {
+		t.Fatalf("LoadChain() error = %v", err)
+	}
+	if string(loaded.Extra["allowlist.json"]) != `["0x01"]` || len(loaded.Extra) != 1 {
+		t.Errorf("LoadChain() Extra = %v, want allowlist.json only", loaded.Extra)
+	}
+
+	nodeDir := t.TempDir()
+	if err := cm.CopyChainConfigsToNode("zoo", "chain-id", nodeDir); err != nil {
+		t.Fatal(err)
+	}
+	if !Exists(filepath.Join(nodeDir, "configs", "chains", "chain-id", "allowlist.json")) {
+		t.Error("extra file not copied to node")
+	}
+
+	cc.Extra = map[string][]byte{"../escape": []byte("x")}
+	if err := cm.SaveChain(cc); err == nil {
+		t.Error("SaveChain() accepted an extra file name with a path")
+	}
+}
+
+func TestChainManagerSaveChainRollback(t *testing.T) {
+	paths := NewPaths(t.TempDir())
+	cm := NewChainManager(paths)
+	if err := cm.SaveChain(&ChainConfig{Name: "zoo", Genesis: []byte(`{"v":1}`)}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A directory in place of upgrade.json makes the last rename fail
+	if err := os.Mkdir(paths.ChainUpgrade("zoo"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	err := cm.SaveChain(&ChainConfig{
+		Name:    "zoo",
+		Genesis: []byte(`{"v":2}`),
+		Config:  []byte(`{"v":2}`),
+		Upgrade: []byte(`{"v":2}`),
+	})
+	var saveErr *ChainSaveError
+	if !errors.As(err, &saveErr) {
+		t.Fatalf("SaveChain() error = %v, want ChainSaveError", err)
+	}
+	if saveErr.File != UpgradeFile || !saveErr.RolledBack {
+		t.Errorf("ChainSaveError = %+v, want rolled back %s", saveErr, UpgradeFile)
+	}
+
+	if genesis, _ := cm.LoadGenesis("zoo"); string(genesis) != `{"v":1}` {
+		t.Errorf("genesis after rollback = %s, want original", genesis)
+	}
+	if Exists(paths.ChainConfig("zoo")) {
+		t.Error("config.json left behind after rollback")
+	}
+	entries, _ := os.ReadDir(paths.ChainDir("zoo"))
+	if len(entries) != 2 {
+		t.Errorf("chain dir has %d entries after rollback, want genesis and upgrade dir", len(entries))
+	}
+}
+
+func TestChainManagerExportImport(t *testing.T) {
+	src := NewChainManager(NewPaths(t.TempDir()))
+	cc := &ChainConfig{
+		Name:    "zoo",
+		Genesis: []byte(`{"config":{"chainId":200200}}`),
+		Config:  []byte(`{"eth-apis":["eth"]}`),
+		Extra:   map[string][]byte{"allowlist.json": []byte(`["0x01"]`)},
+	}
+	if err := src.SaveChain(cc); err != nil {
+		t.Fatal(err)
+	}
+
+	var archive bytes.Buffer
+	if err := src.ExportChain("zoo", &archive); err != nil {
+		t.Fatalf("ExportChain() error = %v", err)
+	}
+	data := archive.Bytes()
+
+	dstPaths := NewPaths(t.TempDir())
+	dst := NewChainManager(dstPaths)
+	name, err := dst.ImportChain(bytes.NewReader(data), false)
+	if err != nil || name != "zoo" {
+		t.Fatalf("ImportChain() = %s, %v", name, err)
+	}
+	loaded, err := dst.LoadChain("zoo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(loaded.Genesis) != string(cc.Genesis) || string(loaded.Config) != string(cc.Config) || string(loaded.Extra["allowlist.json"]) != `["0x01"]` {
+		t.Errorf("imported chain = %+v, want %+v", loaded, cc)
+	}
+
+	if _, err := dst.ImportChain(bytes.NewReader(data), false); err == nil {
+		t.Error("ImportChain() replaced an existing chain without overwrite")
+	}
+	if _, err := dst.ImportChain(bytes.NewReader(data), true); err != nil {
+		t.Errorf("ImportChain() with overwrite error = %v", err)
+	}
+
+	// A failed overwrite leaves the existing chain intact
+	invalid := &ChainConfig{Name: "zoo", Genesis: cc.Genesis, Config: []byte(`{"rpc-gas-cap":"lots"}`)}
+	other := NewChainManager(NewPaths(t.TempDir()))
+	if err := other.SaveChain(invalid); err != nil {
+		t.Fatal(err)
+	}
+	archive.Reset()
+	if err := other.ExportChain("zoo", &archive); err != nil {
+		t.Fatal(err)
+	}
+	strict := NewChainManager(dstPaths, WithChainConfigValidation())
+	if _, err := strict.ImportChain(bytes.NewReader(archive.Bytes()), true); err == nil {
+		t.Error("ImportChain() accepted an invalid chain config")
+	}
+	if loaded, err := dst.LoadChain("zoo"); err != nil || string(loaded.Config) != string(cc.Config) || loaded.Extra["allowlist.json"] == nil {
+		t.Errorf("chain after failed import = %+v, %v; want it unchanged", loaded, err)
+	}
+
+	// A successful overwrite drops files the archive does not have
+	if _, err := dst.ImportChain(bytes.NewReader(archive.Bytes()), true); err != nil {
+		t.Fatal(err)
+	}
+	if loaded, err := dst.LoadChain("zoo"); err != nil || string(loaded.Config) != string(invalid.Config) || len(loaded.Extra) != 0 {
+		t.Errorf("chain after overwrite = %+v, %v; want archive contents only", loaded, err)
+	}
+
+	// A genesis that isn't a JSON object is rejected
+	if err := src.SaveGenesis("bad", []byte("not json")); err != nil {
+		t.Fatal(err)
+	}
+	archive.Reset()
+	if err := src.ExportChain("bad", &archive); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dst.ImportChain(&archive, false); err == nil || dst.ChainExists("bad") {
+		t.Errorf("ImportChain() accepted invalid genesis, err = %v", err)
+	}
+}
+
+func TestPreflightCheck(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.DataDir = t.TempDir()
+	cfg.PluginDir = filepath.Join(cfg.DataDir, "plugins")
+
+	results := PreflightCheck(cfg)
+	status := make(map[string]CheckStatus)
+	for _, r := range results {
+		status[r.Name] = r.Status
+	}
+	for _, name := range []string{"config", "spec", "data-dir", "gpu"} {
+		if status[name] != CheckPass {
+			t.Errorf("check %s = %s, want pass", name, status[name])
+		}
+	}
+	if status["plugin-dir"] != CheckWarn {
+		t.Errorf("check plugin-dir = %s, want warn for empty dir", status["plugin-dir"])
+	}
+	if PreflightFailed(results) {
+		t.Error("PreflightFailed() = true, want false")
+	}
+
+	cfg.Log.Level = "loud"
+	if !PreflightFailed(PreflightCheck(cfg)) {
+		t.Error("PreflightFailed() = false for invalid config")
+	}
+	if !PreflightFailed(PreflightCheck(nil)) {
+		t.Error("PreflightFailed() = false for nil config")
+	}
+}
+
+func TestPluginPackageManagerPluginEnv(t *testing.T) {
+	pm, err := NewPluginPackageManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	binary := filepath.Join(t.TempDir(), "vm")
+	if err := os.WriteFile(binary, testPluginBinary, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	bad := &PluginManifest{Org: "luxfi", Name: "evm", Version: "v1.0.0", VMID: VMID("vm-evm"), Env: map[string]string{"BAD-KEY": "x"}}
+	if err := pm.Install(ctx, bad, binary); err == nil {
+		t.Fatal("Install() accepted an invalid env key")
+	}
+
+	m := &PluginManifest{Org: "luxfi", Name: "evm", Version: "v1.0.0", VMID: VMID("vm-evm"), Env: map[string]string{"EVM_CACHE_MB": "512"}}
+	if err := pm.Install(ctx, m, binary); err != nil {
+		t.Fatal(err)
+	}
+
+	env, err := pm.PluginEnv(VMID("vm-evm"))
+	if err != nil {
+		t.Fatalf("PluginEnv() error = %v", err)
+	}
+	if env["EVM_CACHE_MB"] != "512" || len(env) != 1 {
+		t.Errorf("PluginEnv() = %v, want EVM_CACHE_MB=512", env)
+	}
+
+	if _, err := pm.PluginEnv("unknown"); err == nil {
+		t.Error("PluginEnv() expected error for inactive vmid")
+	}
+}
+
+func TestPluginRegistrySchemaVersion(t *testing.T) {
+	dir := t.TempDir()
+	registryPath := filepath.Join(dir, "registry.json")
+
+	// Unknown fields from a newer writer survive a load/save round trip
+	legacy := `{"plugins":{},"active":{},"future_field":{"luxfi/evm":"v1.0.0"},"updated_at":"2025-01-01T00:00:00Z"}`
+	if err := os.WriteFile(registryPath, []byte(legacy), 0644); err != nil {
+		t.Fatal(err)
+	}
+	pm, err := NewPluginPackageManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pm.saveRegistry(); err != nil {
+		t.Fatalf("saveRegistry() error = %v", err)
+	}
+	data, err := os.ReadFile(registryPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"future_field"`) {
+		t.Errorf("unknown field dropped on save: %s", data)
+	}
+	if !strings.Contains(string(data), `"schema_version": 1`) {
+		t.Errorf("schema version not written: %s", data)
+	}
+
+	// A registry from a newer schema is not overwritten
+	newer := `{"schema_version":99,"plugins":{},"active":{}}`
+	if err := os.WriteFile(registryPath, []byte(newer), 0644); err != nil {
+		t.Fatal(err)
+	}
+	pm, err = NewPluginPackageManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pm.saveRegistry(); err == nil {
+		t.Error("saveRegistry() expected error for newer schema version")
+	}
+}
+
+func TestPluginPackageManagerFind(t *testing.T) {
+	pm, err := NewPluginPackageManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	binary := filepath.Join(t.TempDir(), "vm")
+	if err := os.WriteFile(binary, testPluginBinary, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	vmid := VMID("subnetevm")
+	for _, m := range []*PluginManifest{
+		{Org: "luxfi", Name: "evm", Version: "v1.0.0", VMID: vmid, VMName: "subnetevm"},
+		{Org: "luxfi", Name: "evm", Version: "v1.1.0", VMID: vmid, VMName: "subnetevm"},
+		{Org: "acme", Name: "evm", Version: "v0.1.0", VMID: VMID("vm-acme"), Aliases: []string{"subnetevm"}},
+	} {
+		if err := pm.Install(ctx, m, binary); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	found, err := pm.FindByVMName("subnetevm")
+	if err != nil {
+		t.Fatalf("FindByVMName() error = %v", err)
+	}
+	var got []string
+	for _, m := range found {
+		got = append(got, m.Org+"@"+m.Version)
+	}
+	if want := "acme@v0.1.0 luxfi@v1.1.0 luxfi@v1.0.0"; strings.Join(got, " ") != want {
+		t.Errorf("FindByVMName() = %v, want %s", got, want)
+	}
+
+	// Activating an older version is reflected after the index refreshes
+	if err := pm.Activate(ctx, "luxfi", "evm", "v1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	m, err := pm.FindByVMID(vmid)
+	if err != nil {
+		t.Fatalf("FindByVMID() error = %v", err)
+	}
+	if m.Version != "v1.0.0" {
+		t.Errorf("FindByVMID() version = %s, want active v1.0.0", m.Version)
+	}
+
+	if _, err := pm.FindByVMID("unknown"); !errors.Is(err, ErrPluginNotFound) {
+		t.Errorf("FindByVMID() error = %v, want ErrPluginNotFound", err)
+	}
+}
+
+func TestLoaderExplain(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte("log:\n  format: json\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "config.dev.yaml"), []byte("node:\n  db-type: memdb\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("LUX_NETWORK_NAME", "testnet")
+	t.Setenv("LUX_NETWORK_ID", "96368")
+
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	fs.String("log-dir", "", "")
+	if err := fs.Parse([]string{"--log-dir=/tmp/lux-logs"}); err != nil {
+		t.Fatal(err)
+	}
+
+	loader := NewLoader(WithConfigPaths(dir), WithProfile("dev"))
+	if err := loader.BindFlags(fs); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loader.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	e := loader.Explain()
+	if len(e.Files) != 2 || filepath.Base(e.Files[0]) != "config.yaml" || filepath.Base(e.Files[1]) != "config.dev.yaml" {
+		t.Errorf("Explain().Files = %v, want config.yaml then config.dev.yaml", e.Files)
+	}
+
+	sources := make(map[string]KeySource)
+	for _, ks := range e.Keys {
+		sources[ks.Key] = ks
+	}
+	tests := map[string]ConfigSource{
+		"log.format":   SourceFile,
+		"node.db-type": SourceProfile,
+		"network.name": SourceEnv,
+		"log-dir":      SourceFlag,
+		"log.level":    SourceDefault,
+	}
+	for key, want := range tests {
+		if got := sources[key].Source; got != want {
+			t.Errorf("source of %s = %s, want %s", key, got, want)
+		}
+	}
+	if got := sources["network.name"].EnvVar; got != "LUX_NETWORK_NAME" {
+		t.Errorf("env var of network.name = %s, want LUX_NETWORK_NAME", got)
+	}
+}
+
+func TestLoaderLoadFrom(t *testing.T) {
+	t.Setenv("LUX_NODE_HTTP_PORT", "9650")
+
+	loader := NewLoader(WithConfigPaths(t.TempDir()))
+	cfg, err := loader.LoadFrom(strings.NewReader("log:\n  level: debug\nnode:\n  http-port: 9000\n"), "yaml")
+	if err != nil {
+		t.Fatalf("LoadFrom() error = %v", err)
+	}
+	if cfg.Log.Level != "debug" {
+		t.Errorf("Log.Level = %s, want debug", cfg.Log.Level)
+	}
+	if cfg.Node.HTTPPort != 9650 {
+		t.Errorf("Node.HTTPPort = %d, want env override 9650", cfg.Node.HTTPPort)
+	}
+	if cfg.Node.DBType != "badgerdb" {
+		t.Errorf("Node.DBType = %s, want default badgerdb", cfg.Node.DBType)
+	}
+
+	// Nothing from an earlier Load on the same loader carries over
+	first, second := t.TempDir(), t.TempDir()
+	if err := os.WriteFile(filepath.Join(first, "config.yaml"), []byte("log:\n  level: warn\nnode:\n  db-type: leveldb\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(second, "config.yaml"), []byte("log:\n  level: error\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	reused := NewLoader(WithConfigPaths(first, second))
+	if _, err := reused.Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(reused.ConflictingConfigFiles()) != 1 {
+		t.Fatalf("ConflictingConfigFiles() = %v, want one shadowed file", reused.ConflictingConfigFiles())
+	}
+	cfg, err = reused.LoadFrom(strings.NewReader("log:\n  level: debug\n"), "yaml")
+	if err != nil {
+		t.Fatalf("LoadFrom() error = %v", err)
+	}
+	if cfg.Node.DBType != "badgerdb" {
+		t.Errorf("Node.DBType = %s, want default badgerdb after LoadFrom", cfg.Node.DBType)
+	}
+	if path := reused.GetConfigFilePath(); path != "" {
+		t.Errorf("GetConfigFilePath() = %q, want empty after LoadFrom", path)
+	}
+	if files := reused.ConflictingConfigFiles(); len(files) != 0 {
+		t.Errorf("ConflictingConfigFiles() = %v, want none after LoadFrom", files)
+	}
+
+	if _, err := NewLoader().LoadFrom(strings.NewReader(`{"log":{"level":"loud"}}`), "json"); err == nil {
+		t.Error("LoadFrom() expected validation error")
+	}
+	if _, err := NewLoader().LoadFrom(strings.NewReader(""), "ini"); err == nil {
+		t.Error("LoadFrom() expected error for unsupported format")
+	}
+}
+
+func TestStore(t *testing.T) {
+	first := DefaultConfig()
+	store := NewStore(first)
+	if store.Get() != first {
+		t.Fatal("Get() did not return the initial config")
+	}
+
+	var got []*LuxConfig
+	unsubscribe := store.Subscribe(func(cfg *LuxConfig) { got = append(got, cfg) })
+
+	second := DefaultConfig()
+	store.Set(second)
+	if store.Get() != second {
+		t.Error("Get() did not return the updated config")
+	}
+	if len(got) != 1 || got[0] != second {
+		t.Errorf("subscriber saw %v, want one update", got)
+	}
+
+	unsubscribe()
+	store.Set(first)
+	if len(got) != 1 {
+		t.Error("subscriber notified after unsubscribe")
+	}
+
+	// Subscribers run in registration order
+	var order []int
+	for i := 0; i < 5; i++ {
+		i := i
+		defer store.Subscribe(func(*LuxConfig) { order = append(order, i) })()
+	}
+	store.Set(second)
+	if fmt.Sprint(order) != "[0 1 2 3 4]" {
+		t.Errorf("subscribers ran in order %v, want registration order", order)
+	}
+
+	// Concurrent Sets are serialized, so the last notification matches Get
+	var last atomic.Pointer[LuxConfig]
+	defer store.Subscribe(func(cfg *LuxConfig) { last.Store(cfg) })()
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			store.Set(DefaultConfig())
+		}()
+	}
+	wg.Wait()
+	if last.Load() != store.Get() {
+		t.Error("last notification does not carry the current config")
+	}
+}
+
+func TestSetGlobalNotifiesStore(t *testing.T) {
+	prev := GlobalStore().Get()
+	t.Cleanup(func() { GlobalStore().Set(prev) })
+
+	var notified *LuxConfig
+	unsubscribe := GlobalStore().Subscribe(func(cfg *LuxConfig) { notified = cfg })
+	defer unsubscribe()
+
+	cfg := DefaultConfig()
+	cfg.Network.Name = "testnet"
+	SetGlobal(cfg)
+
+	if Global() != cfg {
+		t.Error("Global() did not return the config from SetGlobal")
+	}
+	if notified != cfg {
+		t.Error("SetGlobal() did not notify store subscribers")
+	}
+}
+
+func TestStrictGlobal(t *testing.T) {
+	prev := GlobalStore().Get()
+	t.Cleanup(func() {
+		configOnce, globalLoadErr, globalFallback = sync.Once{}, nil, nil
+		SetStrictGlobal(false)
+		GlobalStore().Set(prev)
+	})
+	t.Setenv("LUX_LOG_LEVEL", "bogus")
+
+	reset := func(strict bool) {
+		configOnce, globalLoadErr, globalFallback = sync.Once{}, nil, nil
+		globalStore.cfg.Store(nil)
+		SetStrictGlobal(strict)
+	}
+
+	reset(false)
+	cfg, err := GlobalE()
+	if err == nil || cfg == nil {
+		t.Fatalf("lenient GlobalE() = %v, %v, want defaults and an error", cfg, err)
+	}
+	if Global() != cfg {
+		t.Error("lenient Global() did not return the default config")
+	}
+	// Later callers still learn the load failed, until a config is set
+	if _, err := GlobalE(); err == nil {
+		t.Error("second lenient GlobalE() error = nil, want the load error")
+	}
+	SetGlobal(DefaultConfig())
+	if _, err := GlobalE(); err != nil {
+		t.Errorf("GlobalE() after SetGlobal error = %v", err)
+	}
+
+	reset(true)
+	if cfg, err := GlobalE(); err == nil || cfg != nil {
+		t.Errorf("strict GlobalE() = %v, %v, want nil and an error", cfg, err)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("strict Global() did not panic on a load error")
+		}
+	}()
+	Global()
+}
+
+func TestRegisterNetwork(t *testing.T) {
+	if err := RegisterNetwork("Zoo-Devnet", 200200); err != nil {
+		t.Fatalf("RegisterNetwork() error = %v", err)
+	}
+	t.Cleanup(func() {
+		networksMu.Lock()
+		delete(networkIDs, "Zoo-Devnet")
+		networksMu.Unlock()
+	})
+
+	if err := RegisterNetwork("zoo-devnet", 200200); err != nil {
+		t.Errorf("re-registering the same network: %v", err)
+	}
+	if err := RegisterNetwork("zoo-devnet", 200201); err == nil {
+		t.Error("RegisterNetwork() allowed a name to change id")
+	}
+	if err := RegisterNetwork("other", MainnetID); err == nil {
+		t.Error("RegisterNetwork() allowed reusing the mainnet id")
+	}
+
+	if name, folded := NormalizeNetworkName("ZOO-DEVNET"); name != "Zoo-Devnet" || !folded {
+		t.Errorf("NormalizeNetworkName() = %s, %v, want Zoo-Devnet, true", name, folded)
+	}
+	if name, ok := LookupNetworkName(200200); !ok || name != "Zoo-Devnet" {
+		t.Errorf("LookupNetworkName() = %s, %v", name, ok)
+	}
+
+	cfg := DefaultConfig()
+	cfg.DataDir = t.TempDir()
+	cfg.Network.Name = "Zoo-Devnet"
+	cfg.Network.ID = 200200
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() error = %v for registered network", err)
+	}
+	if warnings, _ := cfg.ValidateWithWarnings(); len(warnings) != 0 {
+		t.Errorf("ValidateWithWarnings() = %v for registered network", warnings)
+	}
+
+	// A mismatched ID is a warning, not an error, so custom deployments load
+	cfg.Network.ID = MainnetID
+	warnings, err := cfg.ValidateWithWarnings()
+	if err != nil {
+		t.Errorf("ValidateWithWarnings() error = %v for a mismatched network id", err)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "Zoo-Devnet") {
+		t.Errorf("ValidateWithWarnings() = %v, want a network id mismatch warning", warnings)
+	}
+	cfg.Network.Name = "private"
+	if warnings, err := cfg.ValidateWithWarnings(); err != nil || len(warnings) != 0 {
+		t.Errorf("ValidateWithWarnings() = %v, %v for unregistered network", warnings, err)
+	}
+}
+
+func TestNodeDataPaths(t *testing.T) {
+	paths := NewPaths("/data/lux")
+	np := paths.NodeDataPaths("local", "run_1", "node1")
+
+	nodeDir := "/data/lux/networks/local/runs/run_1/node1"
+	if np.NodeDir != nodeDir {
+		t.Errorf("NodeDir = %s, want %s", np.NodeDir, nodeDir)
+	}
+	want := map[string]string{
+		DBPathKey:         nodeDir + "/db",
+		LogsDirKey:        nodeDir + "/logs",
+		ChainConfigDirKey: nodeDir + "/configs/chains",
+		NetConfigDirKey:   nodeDir + "/configs/nets",
+	}
+	for key, dir := range np.Flags() {
+		if want[key] != dir {
+			t.Errorf("Flags()[%s] = %s, want %s", key, dir, want[key])
+		}
+	}
+
+	// CopyChainConfigsToNode writes into the same chain config directory
+	base := t.TempDir()
+	cm := NewChainManager(NewPaths(base))
+	if err := cm.SaveChain(&ChainConfig{Name: "zoo", Genesis: []byte(`{}`), Config: []byte(`{}`)}); err != nil {
+		t.Fatal(err)
+	}
+	np = NewPaths(base).NodeDataPaths("local", "run_1", "node1")
+	if err := cm.CopyChainConfigsToNode("zoo", "chain-id", np.NodeDir); err != nil {
+		t.Fatal(err)
+	}
+	if !Exists(filepath.Join(np.ChainConfigDir, "chain-id", ConfigFile)) {
+		t.Error("chain config not copied under ChainConfigDir")
+	}
+}
+
+func TestLuxConfigPathsMatchChainManager(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.DataDir = t.TempDir()
+
+	cm := NewChainManager(cfg.Paths())
+	if err := cm.SaveChain(&ChainConfig{Name: "zoo", Genesis: []byte(`{}`), Config: []byte(`{}`)}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Chains are defined by name under GetChainDefinitionsPath
+	if got, want := cfg.GetChainDefinitionsPath(), cfg.Paths().ChainsBaseDir(); got != want {
+		t.Errorf("GetChainDefinitionsPath() = %s, want %s", got, want)
+	}
+	if !Exists(filepath.Join(cfg.GetChainDefinitionsPath(), "zoo", GenesisFile)) {
+		t.Error("chain saved by ChainManager not found under GetChainDefinitionsPath()")
+	}
+
+	// The node reads chain configs by chain ID from GetChainsConfigPath,
+	// which CopyChainConfigsToNode fills for a node rooted at DataDir
+	if got, want := cfg.GetChainsConfigPath(), filepath.Join(cfg.DataDir, "configs", "chains"); got != want {
+		t.Errorf("GetChainsConfigPath() = %s, want %s", got, want)
+	}
+	if err := cm.CopyChainConfigsToNode("zoo", "chain-id", cfg.DataDir); err != nil {
+		t.Fatal(err)
+	}
+	if !Exists(filepath.Join(cfg.GetChainsConfigPath(), "chain-id", ConfigFile)) {
+		t.Error("chain config copied to the node not found under GetChainsConfigPath()")
+	}
+}
+
+func TestPluginPackageManagerVMIDConflict(t *testing.T) {
+	pm, err := NewPluginPackageManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	binary := filepath.Join(t.TempDir(), "vm")
+	if err := os.WriteFile(binary, testPluginBinary, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := pm.Install(ctx, &PluginManifest{Org: "luxfi", Name: "evm", Version: "v1.0.0", VMID: VMID("vm-shared")}, binary); err != nil {
+		t.Fatal(err)
+	}
+
+	// A new version of the same package may take over its own VMID
+	if err := pm.Install(ctx, &PluginManifest{Org: "luxfi", Name: "evm", Version: "v1.1.0", VMID: VMID("vm-shared")}, binary); err != nil {
+		t.Fatalf("Install() of a new version error = %v", err)
+	}
+
+	other := &PluginManifest{Org: "acme", Name: "vm", Version: "v0.1.0", VMID: VMID("vm-shared")}
+	err = pm.Install(ctx, other, binary)
+	if !errors.Is(err, ErrVMIDConflict) {
+		t.Fatalf("Install() error = %v, want ErrVMIDConflict", err)
+	}
+	if !strings.Contains(err.Error(), "luxfi/evm@v1.1.0") {
+		t.Errorf("conflict error %q does not name the active package", err)
+	}
+	if err := pm.Link(ctx, other, binary); !errors.Is(err, ErrVMIDConflict) {
+		t.Errorf("Link() error = %v, want ErrVMIDConflict", err)
+	}
+
+	if err := pm.Install(ctx, other, binary, WithVMIDOverride()); err != nil {
+		t.Fatalf("Install() with override error = %v", err)
+	}
+	conflicts, err := pm.CheckVMIDConflicts(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(conflicts) != 1 || conflicts[0].VMID != VMID("vm-shared") || strings.Join(conflicts[0].Packages, ",") != "acme/vm,luxfi/evm" {
+		t.Errorf("CheckVMIDConflicts() = %+v", conflicts)
+	}
+}
+
+func TestLogFactoryOutputs(t *testing.T) {
+	dir := t.TempDir()
+	factory := NewLogFactory(LogConfig{Level: "warn", Format: "plain", Directory: dir})
+
+	outputs := factory.Outputs()
+	if len(outputs) != 1 || outputs[0].Type != OutputConsole || outputs[0].Format != "plain" || outputs[0].Level != "warn" {
+		t.Fatalf("Outputs() before CreateLogger = %+v, want console only", outputs)
+	}
+
+	if _, err := factory.CreateLogger("node"); err != nil {
+		t.Fatal(err)
+	}
+	outputs = factory.Outputs()
+	if len(outputs) != 2 {
+		t.Fatalf("Outputs() = %+v, want console and file", outputs)
+	}
+	file := outputs[1]
+	if file.Type != OutputFile || file.Target != filepath.Join(dir, "node.log") || file.Logger != "node" || file.Format != "json" {
+		t.Errorf("file output = %+v", file)
+	}
+
+	if got := NewLogFactory(LogConfig{Level: "off"}).Outputs()[0].Level; got != "off" {
+		t.Errorf("console level for off = %s, want off", got)
+	}
+}
+
+func TestLogFactoryValidateOutputs(t *testing.T) {
+	// A directory under a regular file can never be created
+	blocker := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(blocker, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := LogConfig{Level: "info", Format: "json", Directory: filepath.Join(blocker, "logs")}
+
+	factory := NewLogFactory(cfg)
+	if err := factory.ValidateOutputs(); err == nil {
+		t.Error("ValidateOutputs() accepted an unusable log directory")
+	}
+	if _, err := factory.CreateLogger("node"); err == nil {
+		t.Error("CreateLogger() succeeded without its log directory")
+	}
+
+	lenient := NewLogFactory(cfg, WithBestEffortFileLogging())
+	if _, err := lenient.CreateLogger("node"); err != nil {
+		t.Errorf("CreateLogger() with best-effort file logging error = %v", err)
+	}
+	if outputs := lenient.Outputs(); len(outputs) != 1 {
+		t.Errorf("best-effort Outputs() = %+v, want console only", outputs)
+	}
+
+	cfg.Directory = filepath.Join(t.TempDir(), "logs")
+	if err := NewLogFactory(cfg).ValidateOutputs(); err != nil {
+		t.Errorf("ValidateOutputs() error = %v", err)
+	}
+	if err := NewLogFactory(LogConfig{}).ValidateOutputs(); err != nil {
+		t.Errorf("ValidateOutputs() without file logging error = %v", err)
+	}
+}
+
+func TestLogFactoryEnabled(t *testing.T) {
+	factory := NewLogFactory(LogConfig{Level: "warn", Format: "json", Directory: t.TempDir()})
+	if _, err := factory.CreateLogger("node"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := factory.Enabled(LogLevelInfo); len(got) != 0 {
+		t.Errorf("Enabled(info) = %+v, want none", got)
+	}
+	if got := factory.Enabled(LogLevelError); len(got) != 2 {
+		t.Errorf("Enabled(error) = %+v, want console and file", got)
+	}
+	if got := factory.Enabled(LogLevelOff); got != nil {
+		t.Errorf("Enabled(off) = %+v, want nil", got)
+	}
+
+	// An output with its own, lower level accepts debug on its own
+	factory.files[0].Level = string(LogLevelDebug)
+	got := factory.Enabled(LogLevelDebug)
+	if len(got) != 1 || got[0].Type != OutputFile {
+		t.Errorf("Enabled(debug) = %+v, want only the file", got)
+	}
+}
+
+func TestLogFactorySyncAll(t *testing.T) {
+	dir := t.TempDir()
+	factory := NewLogFactory(LogConfig{Level: "info", Format: "json", Directory: dir})
+
+	for _, name := range []string{"node", "chain"} {
+		logger, err := factory.CreateLogger(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		logger.Named("sub").Info("tail message")
+	}
+
+	if err := factory.SyncAll(); err != nil {
+		t.Fatalf("SyncAll() error = %v", err)
+	}
+	for _, name := range []string{"node", "chain"} {
+		data, err := os.ReadFile(filepath.Join(dir, name+".log"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(data), "tail message") {
+			t.Errorf("%s.log missing flushed message", name)
+		}
+	}
+}
+
+func TestValidateChainConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		wantErr bool
+	}{
+		{"valid", `{"eth-apis":["eth","net"],"pruning-enabled":true,"rpc-gas-cap":50000000,"api-max-duration":"30s"}`, false},
+		{"ordered forks", `{"homesteadBlock":0,"istanbulBlock":0,"londonBlock":10,"shanghaiTime":100,"cancunTime":200}`, false},
+		{"unknown key", `{"my-custom-flag":1}`, false},
+		{"not an object", `[1,2]`, true},
+		{"invalid json", `{`, true},
+		{"wrong bool type", `{"pruning-enabled":"yes"}`, true},
+		{"negative uint", `{"rpc-gas-cap":-1}`, true},
+		{"bad duration", `{"api-max-duration":"forever"}`, true},
+		{"bad api list", `{"eth-apis":"eth"}`, true},
+		{"forks out of order", `{"berlinBlock":10,"londonBlock":5}`, true},
+		{"time forks out of order", `{"shanghaiTime":200,"cancunTime":100}`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateChainConfig([]byte(tt.config))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateChainConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	warnings, err := CheckChainConfig([]byte(`{"pruning-enabled":true,"my-custom-flag":1}`))
+	if err != nil || len(warnings) != 1 || !strings.Contains(warnings[0], "my-custom-flag") {
+		t.Errorf("CheckChainConfig() = %v, %v, want one warning", warnings, err)
+	}
+
+	cm := NewChainManager(NewPaths(t.TempDir()), WithChainConfigValidation())
+	bad := &ChainConfig{Name: "zoo", Genesis: []byte(`{}`), Config: []byte(`{"pruning-enabled":1}`)}
+	if err := cm.SaveChain(bad); err == nil {
+		t.Error("SaveChain() with validation accepted an invalid config")
+	}
+}
+
+func TestPluginPackageManagerImmutableInstall(t *testing.T) {
+	pm, err := NewPluginPackageManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	binary := filepath.Join(t.TempDir(), "vm")
+	if err := os.WriteFile(binary, testPluginBinary, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	m := &PluginManifest{Org: "luxfi", Name: "evm", Version: "v1.0.0", VMID: VMID("vm-evm")}
+	if err := pm.Install(ctx, m, binary, WithImmutable()); err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+
+	pkgPath := pm.PackagePath("luxfi", "evm", "v1.0.0")
+	for path, want := range map[string]os.FileMode{
+		pkgPath:                                 0555,
+		filepath.Join(pkgPath, "evm"):           0555,
+		filepath.Join(pkgPath, "manifest.json"): 0444,
+	} {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != want {
+			t.Errorf("mode of %s = %o, want %o", path, info.Mode().Perm(), want)
+		}
+	}
+
+	// Reinstalling over an immutable package works
+	if err := pm.Install(ctx, m, binary, WithImmutable()); err != nil {
+		t.Fatalf("reinstall error = %v", err)
+	}
+
+	if err := pm.Link(ctx, m, binary, WithImmutable()); err == nil {
+		t.Error("Link() accepted WithImmutable")
+	}
+
+	// Linking over an immutable package unlocks it first
+	if err := pm.Link(ctx, m, binary); err != nil {
+		t.Fatalf("Link() over immutable package error = %v", err)
+	}
+	for _, path := range []string{pkgPath, filepath.Join(pkgPath, "manifest.json")} {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm()&0200 == 0 {
+			t.Errorf("mode of %s = %o, want owner-writable", path, info.Mode().Perm())
+		}
+	}
+
+	if err := pm.Uninstall(ctx, "luxfi", "evm", "v1.0.0"); err != nil {
+		t.Fatalf("Uninstall() error = %v", err)
+	}
+	if Exists(pkgPath) {
+		t.Error("immutable package not removed")
+	}
+}
+
+func TestPluginPackageManagerUpgradePlan(t *testing.T) {
+	pm, err := NewPluginPackageManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	binary := filepath.Join(t.TempDir(), "vm")
+	if err := os.WriteFile(binary, testPluginBinary, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range []*PluginManifest{
+		{Org: "luxfi", Name: "evm", Version: "v1.0.0", VMID: VMID("vm-evm")},
+		{Org: "luxfi", Name: "timestampvm", Version: "v0.2.0", VMID: VMID("vm-ts")},
+		{Org: "acme", Name: "vm", Version: "v1.0.0", VMID: VMID("vm-acme")},
+	} {
+		if err := pm.Install(ctx, m, binary); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := pm.Pin("acme", "vm", "v1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+
+	actions, err := pm.UpgradePlan(map[string][]string{
+		"luxfi/evm":          {"v1.0.0", "v1.2.0", "v1.3.0-rc.1", "garbage"},
+		"luxfi/timestampvm":  {"v0.1.0", "v0.2.0"},
+		"acme/vm":            {"v2.0.0"},
+		"luxfi/notinstalled": {"v9.0.0"},
+	})
+	if err != nil {
+		t.Fatalf("UpgradePlan() error = %v", err)
+	}
+
+	want := []string{
+		"luxfi/evm: active v1.0.0 → latest available v1.2.0",
+		"luxfi/timestampvm: active v0.2.0 is up to date",
+	}
+	if len(actions) != len(want) {
+		t.Fatalf("UpgradePlan() = %v, want %d actions", actions, len(want))
+	}
+	for i, a := range actions {
+		if a.String() != want[i] {
+			t.Errorf("action %d = %q, want %q", i, a.String(), want[i])
+		}
+	}
+
+	// The pinned package is left at its version
+	active, err := pm.ListActive(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := active[VMID("vm-acme")].Version; got != "v1.0.0" {
+		t.Errorf("pinned acme/vm active version = %s, want v1.0.0", got)
+	}
+}
+
+func TestPluginPackageManagerRelinkAll(t *testing.T) {
+	oldBase := filepath.Join(t.TempDir(), "plugins")
+	pm, err := NewPluginPackageManager(oldBase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	binary := filepath.Join(t.TempDir(), "vm")
+	if err := os.WriteFile(binary, testPluginBinary, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := pm.Install(ctx, &PluginManifest{Org: "luxfi", Name: "evm", Version: "v1.0.0", VMID: VMID("vm-evm")}, binary); err != nil {
+		t.Fatal(err)
+	}
+	if err := pm.Link(ctx, &PluginManifest{Org: "luxfi", Name: "dev", Version: "v0.0.1", VMID: VMID("vm-dev")}, binary); err != nil {
+		t.Fatal(err)
+	}
+	if err := pm.Install(ctx, &PluginManifest{Org: "luxfi", Name: "gone", Version: "v1.0.0", VMID: VMID("vm-gone")}, binary); err != nil {
+		t.Fatal(err)
+	}
+
+	// Move the plugin tree; absolute symlinks now point at the old location
+	newBase := filepath.Join(t.TempDir(), "plugins")
+	if err := os.Rename(oldBase, newBase); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(newBase, packagesDir, "luxfi", "gone", "v1.0.0", "gone")); err != nil {
+		t.Fatal(err)
+	}
+
+	pm, err = NewPluginPackageManager(newBase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := pm.RelinkAll(ctx)
+	if err != nil {
+		t.Fatalf("RelinkAll() error = %v", err)
+	}
+
+	status := make(map[string]RelinkStatus)
+	for _, r := range results {
+		status[r.VMID] = r.Status
+	}
+	want := map[string]RelinkStatus{VMID("vm-evm"): RelinkRepaired, VMID("vm-dev"): RelinkOK, VMID("vm-gone"): RelinkMissing}
+	for vmid, s := range want {
+		if status[vmid] != s {
+			t.Errorf("status of %s = %s, want %s", vmid, status[vmid], s)
+		}
+	}
+
+	if _, err := os.Stat(pm.ActivePath(VMID("vm-evm"))); err != nil {
+		t.Errorf("vm-evm symlink still broken: %v", err)
+	}
+	if _, err := os.Stat(pm.AliasPath("evm")); err != nil {
+		t.Errorf("evm alias still broken: %v", err)
+	}
+}
+
+func TestCompareDefaults(t *testing.T) {
+	mismatches, err := CompareDefaults()
+	if err != nil {
+		t.Fatalf("CompareDefaults() error = %v", err)
+	}
+
+	found := make(map[string]DefaultMismatch)
+	for _, m := range mismatches {
+		found[m.Key] = m
+	}
+
+	// The node keeps plugins in plugins/current, the package in plugins/
+	if _, ok := found[PluginDirKey]; !ok {
+		t.Errorf("CompareDefaults() did not report %s: %+v", PluginDirKey, mismatches)
+	}
+	for _, key := range []string{DataDirKey, NetworkIDKey, HTTPPortKey, StakingPortKey, DBTypeKey, LogLevelKey} {
+		if m, ok := found[key]; ok {
+			t.Errorf("CompareDefaults() reported %s: package %v, spec %v", key, m.Package, m.Spec)
+		}
+	}
+}
+
+func TestLoaderExpandsEnvInValues(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip("no home directory")
+	}
+	dir := t.TempDir()
+	t.Setenv("LUX_TEST_HOST", "10.0.0.5")
+	t.Setenv("LUX_TEST_NET", "devnet")
+
+	content := "log:\n  directory: ~/lux-logs\n" +
+		"network:\n  name: ${LUX_TEST_NET}\n  api-endpoint: http://$LUX_TEST_HOST:9630\n" +
+		"plugin-dir: $HOME/plugins\n"
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := NewLoader(WithConfigPaths(dir)).Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(home, "lux-logs"); cfg.Log.Directory != want {
+		t.Errorf("Log.Directory = %s, want %s", cfg.Log.Directory, want)
+	}
+	if want := filepath.Join(os.Getenv("HOME"), "plugins"); cfg.PluginDir != want {
+		t.Errorf("PluginDir = %s, want %s", cfg.PluginDir, want)
+	}
+	if cfg.Network.Name != "devnet" {
+		t.Errorf("Network.Name = %s, want devnet", cfg.Network.Name)
+	}
+	if cfg.Network.APIEndpoint != "http://10.0.0.5:9630" {
+		t.Errorf("Network.APIEndpoint = %s, want expanded host", cfg.Network.APIEndpoint)
+	}
+}
+
+func TestPathsListNodes(t *testing.T) {
+	paths := NewPaths(t.TempDir())
+	runDir := paths.NetworkRunDir("local", "run_20250101_000000")
+	for _, dir := range []string{"node2", "node1", "node10", "snapshots"} {
+		if err := os.MkdirAll(filepath.Join(runDir, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(runDir, "node.log"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	nodes, err := paths.ListNodes("local", "run_20250101_000000")
+	if err != nil {
+		t.Fatalf("ListNodes() error = %v", err)
+	}
+	if got := strings.Join(nodes, ","); got != "node1,node10,node2" {
+		t.Errorf("ListNodes() = %s, want node1,node10,node2", got)
+	}
+
+	if nodes, err := paths.ListNodes("local", "missing"); err != nil || nodes != nil {
+		t.Errorf("ListNodes() for missing run = %v, %v", nodes, err)
+	}
+
+	latest, err := paths.FindLatestRun("local")
+	if err != nil || latest != "run_20250101_000000" {
+		t.Errorf("FindLatestRun() = %s, %v", latest, err)
+	}
+}
+
+func TestPathsNodePrefixes(t *testing.T) {
+	paths := NewPaths(t.TempDir())
+	runDir := paths.NetworkRunDir("local", "run_20250101_000000")
+	for _, dir := range []string{"validator1", "beacon0", "extra"} {
+		if err := os.MkdirAll(filepath.Join(runDir, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Default prefixes find no nodes
+	if latest, _ := paths.FindLatestRun("local"); latest != "" {
+		t.Errorf("FindLatestRun() with default prefixes = %s, want none", latest)
+	}
+
+	paths.NodePrefixes = []string{"validator", "beacon"}
+	nodes, err := paths.ListNodes("local", "run_20250101_000000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(nodes, ","); got != "beacon0,validator1" {
+		t.Errorf("ListNodes() = %s, want beacon0,validator1", got)
+	}
+	if latest, _ := paths.FindLatestRun("local"); latest != "run_20250101_000000" {
+		t.Errorf("FindLatestRun() = %s, want run_20250101_000000", latest)
+	}
+
+	paths.NodePrefixes = []string{}
+	if nodes, _ := paths.ListNodes("local", "run_20250101_000000"); len(nodes) != 3 {
+		t.Errorf("ListNodes() with empty prefixes = %v, want all directories", nodes)
+	}
+}
+
+func TestValidateNodeLayout(t *testing.T) {
+	paths := NewPaths(t.TempDir())
+	node1 := paths.NodeDataPaths("local", "run_20250101_000000", "node1")
+	node2 := paths.NodeDataPaths("local", "run_20250101_000000", "node2")
+
+	if err := ValidateNodeLayout(node1.NodeDir, node1.DBDir, node1.LogsDir, node1.ChainConfigDir); err != nil {
+		t.Errorf("ValidateNodeLayout() error = %v", err)
+	}
+	if err := ValidateNodeLayout(node1.NodeDir, node1.DBDir, node1.DBDir, node1.ChainConfigDir); err == nil {
+		t.Error("ValidateNodeLayout() accepted db-dir equal to log-dir")
+	}
+	if err := ValidateNodeLayout(node1.NodeDir, node1.DBDir, "", node1.ChainConfigDir); err == nil {
+		t.Error("ValidateNodeLayout() accepted an empty log-dir")
+	}
+	if err := ValidateNodeLayout(node1.NodeDir, node2.DBDir, node1.LogsDir, node1.ChainConfigDir); err == nil {
+		t.Error("ValidateNodeLayout() accepted a db-dir outside the node directory")
+	}
+	if err := ValidateNodeLayout(node1.NodeDir, node2.DBDir, node1.LogsDir, node1.ChainConfigDir, AllowOutsideNodeDir()); err != nil {
+		t.Errorf("ValidateNodeLayout(AllowOutsideNodeDir) error = %v", err)
+	}
+
+	if err := ValidateNodeLayouts([]NodeDataPaths{node1, node2}); err != nil {
+		t.Errorf("ValidateNodeLayouts() error = %v", err)
+	}
+	node2.DBDir = node1.DBDir
+	if err := ValidateNodeLayouts([]NodeDataPaths{node1, node2}, AllowOutsideNodeDir()); err == nil {
+		t.Error("ValidateNodeLayouts() accepted a shared db-dir")
+	}
+}
+
+func TestTrackChains(t *testing.T) {
+	chains, err := ParseTrackChains(" chainA, chainB,chainA ")
+	if err != nil {
+		t.Fatalf("ParseTrackChains() error = %v", err)
+	}
+	if got := chains.String(); got != "chainA,chainB" {
+		t.Errorf("String() = %s, want chainA,chainB", got)
+	}
+	if chains, err := ParseTrackChains(""); err != nil || len(chains) != 0 {
+		t.Errorf("ParseTrackChains(\"\") = %v, %v", chains, err)
+	}
+	for _, bad := range []string{"a,,b", "a,b c"} {
+		if _, err := ParseTrackChains(bad); err == nil {
+			t.Errorf("ParseTrackChains(%q) succeeded", bad)
+		}
+	}
+	if err := (TrackChains{"a", "a"}).Validate(); err == nil {
+		t.Error("Validate() accepted a duplicate chain")
+	}
+
+	ids := map[string]string{"zoo": "chainZoo", "hanzo": "chainHanzo"}
+	chains, err = TrackChainsFor([]string{"zoo", "hanzo"}, ids)
+	if err != nil || chains.String() != "chainZoo,chainHanzo" {
+		t.Errorf("TrackChainsFor() = %s, %v", chains, err)
+	}
+	if _, err := TrackChainsFor([]string{"spc"}, ids); err == nil {
+		t.Error("TrackChainsFor() accepted a chain without an id")
+	}
+}
+
+func TestBuildNodeFlags(t *testing.T) {
+	paths := NewPaths(t.TempDir())
+	pm, err := NewPluginPackageManager(paths.PluginsBaseDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cm := NewChainManager(paths)
+	if err := cm.SaveChain(&ChainConfig{Name: "zoo", Genesis: []byte(`{}`), Config: []byte(`{"eth-apis":["eth"]}`)}); err != nil {
+		t.Fatal(err)
+	}
+
+	params := NodeLaunchParams{
+		Paths:        paths,
+		Chains:       cm,
+		Plugins:      pm,
+		Network:      NetworkLocal,
+		RunID:        "run_20250101_000000",
+		NodeName:     "node2",
+		NodeIndex:    1,
+		TrackChains:  map[string]string{"zoo": "chain-zoo"},
+		BootstrapIDs: []string{"NodeID-1"},
+		BootstrapIPs: []string{"127.0.0.1:9631"},
+	}
+
+	_, err = BuildNodeFlags(params)
+	var missingErr *MissingLaunchInputError
+	if !errors.As(err, &missingErr) || len(missingErr.Missing) != 3 {
+		t.Fatalf("BuildNodeFlags() without keys error = %v, want 3 missing key files", err)
+	}
+
+	for _, name := range []string{StakingKeyFile, StakingCertFile, SignerKeyFile} {
+		if err := paths.WriteNodeKeyFile(NetworkLocal, "node2", name, []byte("key")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	args, err := BuildNodeFlags(params)
+	if err != nil {
+		t.Fatalf("BuildNodeFlags() error = %v", err)
+	}
+	dirs := paths.NodeDataPaths(NetworkLocal, params.RunID, "node2")
+	got := strings.Join(args, " ")
+	for _, want := range []string{
+		"--db-dir=" + dirs.DBDir,
+		"--plugin-dir=" + pm.GetActiveDir(),
+		"--network-id=1337",
+		"--http-port=9632",
+		"--staking-port=9633",
+		"--bootstrap-ids=NodeID-1",
+		"--track-chains=chain-zoo",
+		"--staking-tls-key-file=" + paths.NodeStakingKey(NetworkLocal, "node2"),
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("BuildNodeFlags() = %s, missing %s", got, want)
+		}
+	}
+	if !Exists(filepath.Join(dirs.ChainConfigDir, "chain-zoo", ConfigFile)) {
+		t.Error("tracked chain config not copied to node")
+	}
+
+	params.TrackChains = map[string]string{"missing": "chain-x"}
+	if _, err := BuildNodeFlags(params); !errors.As(err, &missingErr) {
+		t.Errorf("BuildNodeFlags() with unknown chain error = %v, want MissingLaunchInputError", err)
+	}
+}
+
+func TestPathsRunLock(t *testing.T) {
+	paths := NewPaths(t.TempDir())
+	runID := "run_20250101_000000"
+	if err := os.MkdirAll(paths.NodeDir("local", runID, "node1"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	unlock, err := paths.TryLockRun("local", runID)
+	if err != nil {
+		t.Fatalf("TryLockRun() error = %v", err)
+	}
+	if !paths.IsRunLocked("local", runID) {
+		t.Error("IsRunLocked() = false after TryLockRun")
+	}
+	if _, err := paths.TryLockRun("local", runID); !errors.Is(err, ErrRunLocked) {
+		t.Errorf("second TryLockRun() error = %v, want ErrRunLocked", err)
+	}
+
+	// A locked run is not reused
+	runDir, err := paths.GetOrCreateRun("local")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(runDir) == runID {
+		t.Errorf("GetOrCreateRun() reused locked run %s", runID)
+	}
+	if !Exists(runDir) || paths.IsRunLocked("local", filepath.Base(runDir)) {
+		t.Error("GetOrCreateRun() did not create an unlocked run")
+	}
+	if err := os.Remove(runDir); err != nil {
+		t.Fatal(err)
+	}
+
+	// GetOrCreateLockedRun returns the new run locked
+	runDir, unlockNew, err := paths.GetOrCreateLockedRun("local")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(runDir) == runID {
+		t.Errorf("GetOrCreateLockedRun() reused locked run %s", runID)
+	}
+	if !paths.IsRunLocked("local", filepath.Base(runDir)) {
+		t.Error("GetOrCreateLockedRun() returned an unlocked run")
+	}
+	if err := unlockNew(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := unlock(); err != nil {
+		t.Fatalf("unlock() error = %v", err)
+	}
+	if paths.IsRunLocked("local", runID) {
+		t.Error("IsRunLocked() = true after unlock")
+	}
+
+	// A lock left by a dead process is stale and taken over
+	if err := os.WriteFile(paths.RunLockPath("local", runID), []byte("999999999"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if paths.IsRunLocked("local", runID) {
+		t.Error("IsRunLocked() = true for stale lock")
+	}
+	unlock, err = paths.TryLockRun("local", runID)
+	if err != nil {
+		t.Fatalf("TryLockRun() over stale lock error = %v", err)
+	}
+	if matches, _ := filepath.Glob(paths.RunLockPath("local", runID) + ".*"); len(matches) != 0 {
+		t.Errorf("lock takeover left files behind: %v", matches)
+	}
+
+	// Unlock leaves a lock that now records another process
+	if err := os.WriteFile(paths.RunLockPath("local", runID), []byte("1"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := unlock(); err == nil || !Exists(paths.RunLockPath("local", runID)) {
+		t.Errorf("unlock() of a taken-over lock error = %v, want error and lock kept", err)
+	}
+	if err := os.Remove(paths.RunLockPath("local", runID)); err != nil {
+		t.Fatal(err)
+	}
+
+	// GetOrCreateLockedRun locks the latest free run; a second caller gets
+	// another
+	first, unlockFirst, err := paths.GetOrCreateLockedRun("local")
+	if err != nil || filepath.Base(first) != runID {
+		t.Fatalf("GetOrCreateLockedRun() = %s, %v; want %s", first, err, runID)
+	}
+	defer unlockFirst()
+	second, unlockSecond, err := paths.GetOrCreateLockedRun("local")
+	if err != nil || second == first {
+		t.Fatalf("second GetOrCreateLockedRun() = %s, %v; want a different run", second, err)
+	}
+	_ = unlockSecond()
+}
+
+func TestFsckTree(t *testing.T) {
+	paths := NewPaths(t.TempDir())
+
+	// A clean tree has no findings
+	if err := paths.EnsureChainDir("good"); err != nil {
+		t.Fatal(err)
+	}
+	if err := paths.WriteFile(paths.ChainGenesis("good"), []byte(`{"config":{"chainId":1}}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := paths.WriteNodeKeyFile("local", "node1", StakingKeyFile, []byte("key")); err != nil {
+		t.Fatal(err)
+	}
+	if err := paths.EnsureDir(paths.NodeDir("local", "run_1", "node1")); err != nil {
+		t.Fatal(err)
+	}
+	findings, err := FsckTree(paths)
+	if err != nil || len(findings) != 0 {
+		t.Fatalf("FsckTree() on clean tree = %v, %v", findings, err)
+	}
+
+	// One problem per subsystem
+	if err := paths.EnsureChainDir("bad"); err != nil {
+		t.Fatal(err)
+	}
+	if err := paths.WriteFile(paths.ChainGenesis("bad"), []byte(`[]`)); err != nil {
+		t.Fatal(err)
+	}
+	pm, err := NewPluginPackageManager(paths.PluginsBaseDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	binary := filepath.Join(t.TempDir(), "evm")
+	if err := os.WriteFile(binary, testPluginBinary, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := pm.Install(context.Background(), &PluginManifest{Org: "luxfi", Name: "evm", Version: "v1.0.0", VMID: VMID("evm")}, binary); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(pm.PackagePath("luxfi", "evm", "v1.0.0"), "evm")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(paths.NodeStakingKey("local", "node1"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := paths.EnsureDir(paths.NetworkRunDir("local", "run_2")); err != nil {
+		t.Fatal(err)
+	}
+
+	findings, err = FsckTree(paths)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !FsckFailed(findings) {
+		t.Error("FsckFailed() = false, want true")
+	}
+	want := map[string]string{
+		"chains":   paths.ChainGenesis("bad"),
+		"plugins":  pm.PackagePath("luxfi", "evm", "v1.0.0"),
+		"keys":     paths.NodeStakingKey("local", "node1"),
+		"runs":     paths.NetworkRunDir("local", "run_2"),
+		"symlinks": pm.ActivePath(VMID("evm")),
+	}
+	got := make(map[string][]string)
+	for _, f := range findings {
+		got[f.Subsystem] = append(got[f.Subsystem], f.Path)
+	}
+	for subsystem, path := range want {
+		if !contains(got[subsystem], path) {
+			t.Errorf("%s findings = %q, want %q", subsystem, got[subsystem], path)
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("FsckTree() findings = %+v, want one subsystem each of %v", findings, want)
+	}
+}
+
+func TestRenderPluginManifests(t *testing.T) {
+	installed := time.Date(2025, 3, 1, 12, 30, 0, 0, time.UTC)
+	m := PluginManifest{
+		Org: "luxfi", Name: "evm", Version: "v1.2.0",
+		VMID: VMID("subnetevm"), VMName: "subnetevm",
+		Size: 48 * 1024 * 1024, InstalledAt: installed,
+	}
+
+	s := m.String()
+	for _, want := range []string{"luxfi/evm@v1.2.0", "VMID:        " + m.VMID, "Size:        48.0 MiB", "Installed:   2025-03-01 12:30:00"} {
+		if !strings.Contains(s, want) {
+			t.Errorf("String() missing %q:\n%s", want, s)
+		}
+	}
+
+	table := string(RenderTable([]PluginManifest{m, {Org: "acme", Name: "vm", Version: "v0.1.0", VMID: "short", Size: 512}}))
+	lines := strings.Split(strings.TrimSpace(table), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("RenderTable() = %q, want header and two rows", table)
+	}
+	if !strings.HasPrefix(lines[0], "PACKAGE") || !strings.Contains(lines[1], m.VMID[:12]+"...") {
+		t.Errorf("RenderTable() rows malformed:\n%s", table)
+	}
+	if !strings.Contains(lines[2], "512 B") || !strings.Contains(lines[2], "-") {
+		t.Errorf("RenderTable() second row = %q", lines[2])
+	}
+	if strings.Index(lines[1], "v1.2.0") != strings.Index(lines[2], "v0.1.0") {
+		t.Error("RenderTable() columns are not aligned")
+	}
+
+	for size, want := range map[int64]string{0: "0 B", 1536: "1.5 KiB", 5 << 30: "5.0 GiB", 3 << 40: "3072.0 GiB"} {
+		if got := formatSize(size); got != want {
+			t.Errorf("formatSize(%d) = %s, want %s", size, got, want)
+		}
+	}
+}
+
+func TestPluginPackageManagerInstallDev(t *testing.T) {
+	pm, err := NewPluginPackageManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	// The test binary is a real executable for the current platform
+	binary, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pm.InstallDev(ctx, binary, "luxfi", "evm", "v0.0.0-dev", "subnetevm"); err != nil {
+		t.Fatalf("InstallDev() error = %v", err)
+	}
+
+	m, err := pm.GetManifest("luxfi", "evm", "v0.0.0-dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.VMID != VMID("subnetevm") || m.VMName != "subnetevm" {
+		t.Errorf("manifest VMID = %s (%s), want VMID(subnetevm)", m.VMID, m.VMName)
+	}
+	if want := runtime.GOOS + "/" + runtime.GOARCH; m.Platform != want {
+		t.Errorf("Platform = %q, want %q", m.Platform, want)
+	}
+	if target, err := os.Readlink(filepath.Join(pm.PackagePath("luxfi", "evm", "v0.0.0-dev"), "evm")); err != nil || target != binary {
+		t.Errorf("package binary link = %q, %v; want %q", target, err, binary)
+	}
+
+	script := filepath.Join(t.TempDir(), "vm.sh")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := pm.InstallDev(ctx, script, "luxfi", "evm", "v0.0.1-dev", "subnetevm"); err == nil {
+		t.Error("InstallDev() accepted a non-executable format")
+	}
+}
+
+func TestPluginPackageManagerBuildInfo(t *testing.T) {
+	pm, err := NewPluginPackageManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	binary := filepath.Join(t.TempDir(), "evm")
+	if err := os.WriteFile(binary, testPluginBinary, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// Without build info the manifest omits the fields entirely
+	plain := &PluginManifest{Org: "luxfi", Name: "evm", Version: "v1.0.0", VMID: VMID("evm")}
+	if err := pm.Install(ctx, plain, binary); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(pm.PackagePath("luxfi", "evm", "v1.0.0"), "manifest.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"built_by", "build_commit", "build_time", "tool_version"} {
+		if strings.Contains(string(data), key) {
+			t.Errorf("manifest without build info contains %q:\n%s", key, data)
+		}
+	}
+
+	built := time.Date(2025, 6, 1, 8, 0, 0, 0, time.UTC)
+	info := BuildInfo{BuiltBy: "ci", BuildCommit: "0123456789abcdef", BuildTime: built, ToolVersion: "go1.25.0"}
+	m := &PluginManifest{Org: "luxfi", Name: "evm", Version: "v1.1.0", VMID: VMID("evm")}
+	if err := pm.Install(ctx, m, binary, WithBuildInfo(info)); err != nil {
+		t.Fatal(err)
+	}
+	got, err := pm.GetManifest("luxfi", "evm", "v1.1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.BuiltBy != "ci" || got.BuildCommit != info.BuildCommit || !got.BuildTime.Equal(built) || got.ToolVersion != "go1.25.0" {
+		t.Errorf("GetManifest() build info = %+v, want %+v", got.BuildInfo, info)
+	}
+	if !strings.Contains(got.String(), "Commit:      0123456") {
+		t.Errorf("String() missing short commit:\n%s", got.String())
+	}
+	table := string(RenderTable([]PluginManifest{*got, *plain}))
+	if lines := strings.Split(strings.TrimSpace(table), "\n"); !strings.Contains(lines[1], "0123456 ") || strings.Contains(lines[1], "01234567") {
+		t.Errorf("RenderTable() row = %q, want short commit", lines[1])
+	}
+}
+
+func TestValidateWithWarnings(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.DataDir = t.TempDir()
+	warnings, err := cfg.ValidateWithWarnings()
+	if err != nil || len(warnings) != 0 {
+		t.Fatalf("ValidateWithWarnings() = %v, %v; want no warnings", warnings, err)
+	}
+
+	cfg.DataDir = filepath.Join(t.TempDir(), "missing")
+	cfg.Log.Compress = true
+	cfg.Log.MaxAge = 0
+	cfg.Log.ShowColors = true
+	cfg.Log.Format = "json"
+	warnings, err = cfg.ValidateWithWarnings()
+	if err != nil {
+		t.Fatalf("ValidateWithWarnings() error = %v", err)
+	}
+	if len(warnings) != 3 {
+		t.Fatalf("ValidateWithWarnings() warnings = %q, want 3", warnings)
+	}
+	for i, want := range []string{"max-age", "show-colors", "does not exist"} {
+		if !strings.Contains(warnings[i], want) {
+			t.Errorf("warning %d = %q, want it to mention %q", i, warnings[i], want)
+		}
+	}
+
+	cfg.Log.Level = "loud"
+	warnings, err = cfg.ValidateWithWarnings()
+	if err == nil || len(warnings) != 3 {
+		t.Errorf("ValidateWithWarnings() = %d warnings, %v; want warnings and an error", len(warnings), err)
+	}
+}
+
+func TestPluginPackageManagerRelativeSymlinks(t *testing.T) {
+	root := t.TempDir()
+	binary := filepath.Join(t.TempDir(), "vm")
+	if err := os.WriteFile(binary, testPluginBinary, 0755); err != nil {
+		t.Fatal(err)
+	}
+	m := func() *PluginManifest {
+		return &PluginManifest{Org: "luxfi", Name: "evm", Version: "v1.0.0", VMID: VMID("vm-x")}
+	}
+
+	// Absolute targets by default
+	abs, err := NewPluginPackageManager(filepath.Join(root, "abs"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := abs.Install(context.Background(), m(), binary); err != nil {
+		t.Fatal(err)
+	}
+	if target, err := os.Readlink(abs.ActivePath(VMID("vm-x"))); err != nil || !filepath.IsAbs(target) {
+		t.Errorf("default VMID symlink = %q, %v; want absolute", target, err)
+	}
+
+	oldDir := filepath.Join(root, "rel")
+	pm, err := NewPluginPackageManager(oldDir, WithRelativeSymlinks())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pm.Install(context.Background(), m(), binary); err != nil {
+		t.Fatal(err)
+	}
+	want := filepath.Join("..", packagesDir, "luxfi", "evm", "v1.0.0", "evm")
+	for _, link := range []string{pm.ActivePath(VMID("vm-x")), pm.AliasPath("evm")} {
+		if target, err := os.Readlink(link); err != nil || target != want {
+			t.Errorf("Readlink(%s) = %q, %v; want %q", link, target, err, want)
+		}
+	}
+
+	// The tree still resolves after being moved
+	newDir := filepath.Join(root, "moved")
+	if err := os.Rename(oldDir, newDir); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Stat(filepath.Join(newDir, activeDir, VMID("vm-x"))); err != nil || info.Size() != int64(len(testPluginBinary)) {
+		t.Errorf("moved VMID symlink does not resolve: %v", err)
+	}
+}
+
+func TestPluginPackageManagerActivateAllLatest(t *testing.T) {
+	pm, err := NewPluginPackageManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	binary := filepath.Join(t.TempDir(), "vm")
+	if err := os.WriteFile(binary, testPluginBinary, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range []*PluginManifest{
+		{Org: "luxfi", Name: "evm", Version: "v1.10.0", VMID: VMID("vm-evm")},
+		{Org: "luxfi", Name: "evm", Version: "v1.2.0", VMID: VMID("vm-evm")},
+		{Org: "luxfi", Name: "timestampvm", Version: "v0.2.0", VMID: VMID("vm-ts")},
+		{Org: "luxfi", Name: "timestampvm", Version: "v0.1.0", VMID: VMID("vm-ts")},
+		{Org: "luxfi", Name: "xvm", Version: "v0.3.0", VMID: VMID("vm-x")},
+	} {
+		if err := pm.Install(ctx, m, binary); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := pm.Pin("luxfi", "timestampvm", "v0.1.0"); err != nil {
+		t.Fatal(err)
+	}
+	// A package whose manifest is gone cannot be resolved
+	if err := os.Remove(filepath.Join(pm.PackagePath("luxfi", "xvm", "v0.3.0"), "manifest.json")); err != nil {
+		t.Fatal(err)
+	}
+	pm.invalidateManifest("luxfi", "xvm", "v0.3.0")
+
+	activated, err := pm.ActivateAllLatest(ctx)
+	if err == nil || !strings.Contains(err.Error(), "luxfi/xvm") {
+		t.Errorf("ActivateAllLatest() error = %v, want luxfi/xvm reported", err)
+	}
+	want := map[string]string{"luxfi/evm": "v1.10.0", "luxfi/timestampvm": "v0.1.0"}
+	if len(activated) != len(want) {
+		t.Errorf("ActivateAllLatest() = %v, want %v", activated, want)
+	}
+	for pkgKey, version := range want {
+		if activated[pkgKey] != version {
+			t.Errorf("activated[%s] = %q, want %q", pkgKey, activated[pkgKey], version)
+		}
+	}
+	if ref := pm.registry.Active[VMID("vm-evm")]; ref != "luxfi/evm@v1.10.0" {
+		t.Errorf("active evm = %s, want v1.10.0", ref)
+	}
+}
+
+func TestPluginPackageManagerApplyActiveSet(t *testing.T) {
+	pm, err := NewPluginPackageManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	binary := filepath.Join(t.TempDir(), "vm")
+	if err := os.WriteFile(binary, testPluginBinary, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range []*PluginManifest{
+		{Org: "luxfi", Name: "evm", Version: "v1.2.0", VMID: VMID("vm-evm")},
+		{Org: "luxfi", Name: "evm", Version: "v1.0.0", VMID: VMID("vm-evm")},
+		{Org: "luxfi", Name: "timestampvm", Version: "v0.2.0", VMID: VMID("vm-ts"), Aliases: []string{"ts"}},
+		{Org: "luxfi", Name: "timestampvm", Version: "v0.1.0", VMID: VMID("vm-ts")},
+	} {
+		if err := pm.Install(ctx, m, binary); err != nil {
+			t.Fatal(err)
+		}
+	}
+	activeRefs := func() map[string]string {
+		refs := make(map[string]string)
+		for vmid, ref := range pm.registry.Active {
+			refs[vmid] = ref
+		}
+		return refs
+	}
+	before := activeRefs()
+
+	// A version that isn't installed fails before anything changes
+	err = pm.ApplyActiveSet(ctx, map[string]string{"luxfi/evm": "v1.2.0", "luxfi/timestampvm": "v9.9.9"})
+	if !errors.Is(err, ErrPluginNotFound) {
+		t.Fatalf("ApplyActiveSet() error = %v, want ErrPluginNotFound", err)
+	}
+
+	// A failing activation rolls back those already applied
+	if err := os.MkdirAll(filepath.Join(pm.AliasPath("ts"), "blocker"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := pm.ApplyActiveSet(ctx, map[string]string{"luxfi/evm": "v1.2.0", "luxfi/timestampvm": "v0.2.0"}); err == nil {
+		t.Fatal("ApplyActiveSet() succeeded despite a failing activation")
+	}
+	if got := activeRefs(); len(got) != len(before) || got[VMID("vm-evm")] != before[VMID("vm-evm")] || got[VMID("vm-ts")] != before[VMID("vm-ts")] {
+		t.Errorf("active set after rollback = %v, want %v", got, before)
+	}
+	if target, _ := os.Readlink(pm.ActivePath(VMID("vm-evm"))); !strings.Contains(target, "v1.0.0") {
+		t.Errorf("vm-evm symlink after rollback = %s, want v1.0.0", target)
+	}
+
+	if err := os.RemoveAll(pm.AliasPath("ts")); err != nil {
+		t.Fatal(err)
+	}
+	if err := pm.ApplyActiveSet(ctx, map[string]string{"luxfi/evm": "v1.2.0", "luxfi/timestampvm": "v0.2.0"}); err != nil {
+		t.Fatalf("ApplyActiveSet() error = %v", err)
+	}
+	if got := activeRefs(); got[VMID("vm-evm")] != "luxfi/evm@v1.2.0" || got[VMID("vm-ts")] != "luxfi/timestampvm@v0.2.0" {
+		t.Errorf("active set = %v", got)
+	}
+}
+
+func TestPluginPackageManagerManifestCache(t *testing.T) {
+	pm, err := NewPluginPackageManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	binary := filepath.Join(t.TempDir(), "vm")
+	if err := os.WriteFile(binary, testPluginBinary, 0755); err != nil {
+		t.Fatal(err)
+	}
+	m := &PluginManifest{Org: "luxfi", Name: "evm", Version: "v1.0.0", VMID: VMID("vm-evm"), Description: "first"}
+	if err := pm.Install(context.Background(), m, binary); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := pm.GetManifest("luxfi", "evm", "v1.0.0"); err != nil || got.Description != "first" {
+		t.Fatalf("GetManifest() = %+v, %v", got, err)
+	}
+
+	// An edit on disk is only seen with FreshManifest
+	manifestPath := filepath.Join(pm.PackagePath("luxfi", "evm", "v1.0.0"), "manifest.json")
+	data, _ := os.ReadFile(manifestPath)
+	if err := os.WriteFile(manifestPath, bytes.Replace(data, []byte("first"), []byte("edited"), 1), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := pm.GetManifest("luxfi", "evm", "v1.0.0"); got.Description != "first" {
+		t.Errorf("cached Description = %s, want first", got.Description)
+	}
+	if got, _ := pm.GetManifest("luxfi", "evm", "v1.0.0", FreshManifest()); got.Description != "edited" {
+		t.Errorf("fresh Description = %s, want edited", got.Description)
+	}
+
+	// Reinstalling invalidates the entry
+	m.Description = "second"
+	if err := pm.Install(context.Background(), m, binary); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := pm.GetManifest("luxfi", "evm", "v1.0.0"); got.Description != "second" {
+		t.Errorf("Description after reinstall = %s, want second", got.Description)
+	}
+
+	if err := pm.Uninstall(context.Background(), "luxfi", "evm", "v1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pm.GetManifest("luxfi", "evm", "v1.0.0"); err == nil {
+		t.Error("GetManifest() served an uninstalled package from the cache")
+	}
+}
+
+func BenchmarkPluginPackageManagerList(b *testing.B) {
+	pm, err := NewPluginPackageManager(b.TempDir())
+	if err != nil {
+		b.Fatal(err)
+	}
+	binary := filepath.Join(b.TempDir(), "vm")
+	if err := os.WriteFile(binary, testPluginBinary, 0755); err != nil {
+		b.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		name := fmt.Sprintf("vm%d", i)
+		m := &PluginManifest{Org: "luxfi", Name: name, Version: "v1.0.0", VMID: VMID(name)}
+		if err := pm.Install(context.Background(), m, binary); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := pm.List(context.Background()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestPluginPackageManagerLockfile(t *testing.T) {
+	ctx := context.Background()
+	binary := filepath.Join(t.TempDir(), "vm")
+	if err := os.WriteFile(binary, testPluginBinary, 0755); err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(testPluginBinary)
+	}))
+	defer srv.Close()
+
+	idx := &RemoteIndex{Packages: []RemotePackage{{
+		Org:      "luxfi",
+		Name:     "evm",
+		Versions: []RemoteRelease{{Version: "v2.0.0", URL: srv.URL, VMID: VMID("vm-evm")}},
+	}}}
+	pm, err := NewPluginPackageManager(t.TempDir(), WithRemoteIndex(idx))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range []*PluginManifest{
+		{Org: "luxfi", Name: "evm", Version: "v1.0.0", VMID: VMID("vm-evm")},
+		{Org: "luxfi", Name: "evm", Version: "v1.1.0", VMID: VMID("vm-evm")},
+		{Org: "luxfi", Name: "dex", Version: "v1.0.0", VMID: VMID("vm-dex")},
+	} {
+		if err := pm.Install(ctx, m, binary); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	lockPath := filepath.Join(t.TempDir(), "plugins.lock")
+	if err := pm.WriteLockfile(lockPath); err != nil {
+		t.Fatalf("WriteLockfile() error = %v", err)
+	}
+	lock, err := ReadLockfile(lockPath)
+	if err != nil || len(lock.Plugins) != 2 || lock.Plugins[VMID("vm-evm")] != "luxfi/evm@v1.1.0" {
+		t.Fatalf("ReadLockfile() = %+v, %v", lock, err)
+	}
+
+	// Lock evm at an older version and drop dex
+	writeLock := func(ref string) {
+		data := fmt.Sprintf(`{"version": 1, "plugins": {%q: %q}}`, VMID("vm-evm"), ref)
+		if err := os.WriteFile(lockPath, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeLock("luxfi/evm@v1.0.0")
+	if err := pm.ApplyLockfile(ctx, lockPath); err != nil {
+		t.Fatalf("ApplyLockfile() error = %v", err)
+	}
+	if ref := pm.registry.Active[VMID("vm-evm")]; ref != "luxfi/evm@v1.0.0" {
+		t.Errorf("active evm = %s, want v1.0.0", ref)
+	}
+	if _, ok := pm.registry.Active[VMID("vm-dex")]; ok || Exists(pm.ActivePath(VMID("vm-dex"))) {
+		t.Error("dex still active after ApplyLockfile")
+	}
+
+	// Missing versions come from the remote index
+	writeLock("luxfi/evm@v2.0.0")
+	if err := pm.ApplyLockfile(ctx, lockPath); err != nil {
+		t.Fatalf("ApplyLockfile() with remote version error = %v", err)
+	}
+	if ref := pm.registry.Active[VMID("vm-evm")]; ref != "luxfi/evm@v2.0.0" {
+		t.Errorf("active evm = %s, want v2.0.0", ref)
+	}
+
+	writeLock("luxfi/evm@v3.0.0")
+	if err := pm.ApplyLockfile(ctx, lockPath); !errors.Is(err, ErrPluginNotFound) {
+		t.Errorf("ApplyLockfile() with unknown version error = %v, want ErrPluginNotFound", err)
+	}
+	pm.remoteIndex = nil
+	if err := pm.ApplyLockfile(ctx, lockPath); !errors.Is(err, ErrPluginNotFound) {
+		t.Errorf("ApplyLockfile() without index error = %v, want ErrPluginNotFound", err)
+	}
+
+	// PlanLockfile previews the changes without making them
+	for _, m := range []*PluginManifest{
+		{Org: "acme", Name: "aaa", Version: "v1.0.0", VMID: VMID("vm-aaa")},
+		{Org: "acme", Name: "zzz", Version: "v1.0.0", VMID: VMID("vm-zzz")},
+	} {
+		if err := pm.Install(ctx, m, binary); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeLock("luxfi/evm@v1.0.0")
+	plan, err := pm.PlanLockfile(lockPath)
+	if err != nil {
+		t.Fatalf("PlanLockfile() error = %v", err)
+	}
+	var steps []string
+	for _, action := range plan {
+		steps = append(steps, action.String())
+	}
+	first, last := "acme/aaa@v1.0.0", "acme/zzz@v1.0.0"
+	if VMID("vm-aaa") > VMID("vm-zzz") {
+		first, last = last, first
+	}
+	want := "activate luxfi/evm@v1.0.0 (was luxfi/evm@v2.0.0),deactivate " + first + ",deactivate " + last
+	if got := strings.Join(steps, ","); got != want {
+		t.Errorf("PlanLockfile() = %s, want %s", got, want)
+	}
+	if ref := pm.registry.Active[VMID("vm-evm")]; ref != "luxfi/evm@v2.0.0" {
+		t.Errorf("PlanLockfile() changed the active evm to %s", ref)
+	}
+
+	// A failed deactivation rolls back the deactivations and activations
+	// already made
+	lastVMID := plan[len(plan)-1].VMID
+	if err := os.Remove(pm.ActivePath(lastVMID)); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(pm.ActivePath(lastVMID), "busy"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := pm.ApplyLockfile(ctx, lockPath); err == nil {
+		t.Fatal("ApplyLockfile() succeeded with an undeletable VMID link")
+	}
+	if ref := pm.registry.Active[VMID("vm-evm")]; ref != "luxfi/evm@v2.0.0" {
+		t.Errorf("active evm = %s after rollback, want v2.0.0", ref)
+	}
+	firstVMID := plan[1].VMID
+	if pm.registry.Active[firstVMID] == "" {
+		t.Errorf("%s left deactivated after rollback", firstVMID)
+	}
+	if _, err := os.Lstat(pm.ActivePath(firstVMID)); err != nil {
+		t.Errorf("symlink for %s not restored after rollback: %v", firstVMID, err)
+	}
+}
+
+func TestPluginPackageManagerListStream(t *testing.T) {
+	pm, err := NewPluginPackageManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	binary := filepath.Join(t.TempDir(), "vm")
+	if err := os.WriteFile(binary, testPluginBinary, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range []*PluginManifest{
+		{Org: "luxfi", Name: "evm", Version: "v1.0.0", VMID: VMID("vm-evm")},
+		{Org: "luxfi", Name: "evm", Version: "v1.10.0", VMID: VMID("vm-evm")},
+		{Org: "acme", Name: "dex", Version: "v0.1.0", VMID: VMID("vm-dex")},
+	} {
+		if err := pm.Install(context.Background(), m, binary); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := pm.ListStream(context.Background(), &buf); err != nil {
+		t.Fatalf("ListStream() error = %v", err)
+	}
+	var refs []string
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var m PluginManifest
+		if err := json.Unmarshal([]byte(line), &m); err != nil {
+			t.Fatalf("line %q is not a manifest: %v", line, err)
+		}
+		refs = append(refs, m.Org+"/"+m.Name+"@"+m.Version)
+	}
+	if got := strings.Join(refs, ","); got != "acme/dex@v0.1.0,luxfi/evm@v1.10.0,luxfi/evm@v1.0.0" {
+		t.Errorf("ListStream() order = %s", got)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := pm.ListStream(ctx, io.Discard); !errors.Is(err, context.Canceled) {
+		t.Errorf("ListStream() with cancelled ctx error = %v", err)
+	}
+}
+
+func TestPluginPackageManagerCompact(t *testing.T) {
+	baseDir := t.TempDir()
+	pm, err := NewPluginPackageManager(baseDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	binary := filepath.Join(t.TempDir(), "vm")
+	if err := os.WriteFile(binary, testPluginBinary, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, version := range []string{"v1.2.0", "v1.0.0"} {
+		m := &PluginManifest{Org: "luxfi", Name: "evm", Version: version, VMID: VMID("vm-evm")}
+		if err := pm.Install(context.Background(), m, binary); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pm.registry.Plugins["luxfi/evm"] = []string{"v1.2.0", "v1.10.0", "v1.0.0", "v1.2.0"}
+	pm.registry.Plugins["luxfi/gone"] = nil
+	pm.registry.Active[VMID("vm-gone")] = "luxfi/gone@v1.0.0"
+	if err := pm.saveRegistry(); err != nil {
+		t.Fatal(err)
+	}
+	goneLink := pm.ActivePath(VMID("vm-gone"))
+	if err := os.Symlink(filepath.Join(pm.PackagePath("luxfi", "gone", "v1.0.0"), "gone"), goneLink); err != nil {
+		t.Fatal(err)
+	}
+	pm.registry.Aliases["gone"] = "luxfi/gone@v1.0.0"
+	if err := pm.saveRegistry(); err != nil {
+		t.Fatal(err)
+	}
+	goneAlias := pm.AliasPath("gone")
+	if err := os.Symlink(filepath.Join(pm.PackagePath("luxfi", "gone", "v1.0.0"), "gone"), goneAlias); err != nil {
+		t.Fatal(err)
+	}
+
+	pm, err = NewPluginPackageManager(baseDir, WithCompactOnLoad())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(pm.registry.Plugins["luxfi/evm"], ","); got != "v1.0.0,v1.2.0,v1.10.0" {
+		t.Errorf("versions = %s, want v1.0.0,v1.2.0,v1.10.0", got)
+	}
+	if _, ok := pm.registry.Plugins["luxfi/gone"]; ok {
+		t.Error("empty package key was not removed")
+	}
+	if _, ok := pm.registry.Active[VMID("vm-gone")]; ok {
+		t.Error("active entry for a missing package was not dropped")
+	}
+	if pm.registry.Active[VMID("vm-evm")] == "" {
+		t.Error("valid active entry was dropped")
+	}
+	if _, err := os.Lstat(goneLink); !os.IsNotExist(err) {
+		t.Errorf("symlink for the dropped active entry still exists: %v", err)
+	}
+	if _, err := os.Lstat(goneAlias); !os.IsNotExist(err) {
+		t.Errorf("alias for the dropped active entry still exists: %v", err)
+	}
+	if _, ok := pm.registry.Aliases["gone"]; ok {
+		t.Error("alias for the dropped active entry still registered")
+	}
+	if _, err := os.Lstat(pm.AliasPath("evm")); err != nil {
+		t.Errorf("alias for a valid active entry was removed: %v", err)
+	}
+	if _, err := os.Lstat(pm.ActivePath(VMID("vm-evm"))); err != nil {
+		t.Errorf("symlink for a valid active entry was removed: %v", err)
+	}
+
+	report, err := pm.Compact()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Changed() {
+		t.Errorf("Compact() on a compacted registry changed it: %+v", report)
+	}
+}
+
+func TestLoaderConflictingConfigFiles(t *testing.T) {
+	first, second := t.TempDir(), t.TempDir()
+	if err := os.WriteFile(filepath.Join(first, "config.yaml"), []byte("log:\n  level: warn\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(second, "config.json"), []byte(`{"log": {"level": "debug"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	loader := NewLoader(WithConfigPaths(first, second))
+	cfg, err := loader.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Log.Level != "warn" || loader.GetConfigFilePath() != filepath.Join(first, "config.yaml") {
+		t.Fatalf("Load() used %s (level %s), want the first search path", loader.GetConfigFilePath(), cfg.Log.Level)
+	}
+
+	shadowed := loader.ConflictingConfigFiles()
+	if len(shadowed) != 1 || shadowed[0] != filepath.Join(second, "config.json") {
+		t.Errorf("ConflictingConfigFiles() = %v, want [%s]", shadowed, filepath.Join(second, "config.json"))
+	}
+	if warnings := loader.Warnings(); len(warnings) != 1 || !strings.Contains(warnings[0], "config.json is ignored") {
+		t.Errorf("Warnings() = %v, want a shadowed config warning", warnings)
+	}
+
+	// An explicit config file is intentional, so nothing is reported
+	loader = NewLoader(WithConfigPaths(first, second), WithConfigFile(filepath.Join(second, "config.json")))
+	if _, err := loader.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if shadowed := loader.ConflictingConfigFiles(); len(shadowed) != 0 {
+		t.Errorf("ConflictingConfigFiles() with explicit file = %v, want none", shadowed)
+	}
+}
+
+func TestGenesisAlloc(t *testing.T) {
+	genesis := []byte(`{
+  "config": {"chainId": 200200},
+  "gasLimit": "0x7A1200",
+  "alloc": {
+    "0x9011E888251AB053B7bD1cdB598Db4f9DEd94714": {"balance": "0x295BE96E64066972000000"},
+    "8db97C7cEcE249c2b98bDC0226Cc4C2A57BF52FC": {
+      "balance": "1000",
+      "code": "0x6080",
+      "storage": {"0x00": "0x01"},
+      "nonce": "0x1"
+    }
+  }
+}`)
+
+	alloc, err := GenesisAlloc(genesis)
+	if err != nil {
+		t.Fatalf("GenesisAlloc() error = %v", err)
+	}
+	funded := alloc["0x9011E888251AB053B7bD1cdB598Db4f9DEd94714"]
+	want, _ := new(big.Int).SetString("295BE96E64066972000000", 16)
+	if funded.Balance.Cmp(want) != 0 {
+		t.Errorf("balance = %s, want %s", funded.Balance, want)
+	}
+	contract := alloc["8db97C7cEcE249c2b98bDC0226Cc4C2A57BF52FC"]
+	if contract.Balance.Int64() != 1000 || !bytes.Equal(contract.Code, []byte{0x60, 0x80}) || contract.Storage["0x00"] != "0x01" {
+		t.Errorf("contract account = %+v", contract)
+	}
+
+	// Fund a new address and write it back
+	alloc["0x0100000000000000000000000000000000000000"] = GenesisAccount{Balance: big.NewInt(255)}
+	updated, err := SetGenesisAlloc(genesis, alloc)
+	if err != nil {
+		t.Fatalf("SetGenesisAlloc() error = %v", err)
+	}
+	if id, err := GetChainIDFromGenesis(updated); err != nil || id != 200200 {
+		t.Errorf("chain ID after SetGenesisAlloc = %d, %v", id, err)
+	}
+	if !bytes.Contains(updated, []byte(`"gasLimit": "0x7A1200"`)) || !bytes.Contains(updated, []byte(`"nonce": "0x1"`)) {
+		t.Errorf("SetGenesisAlloc() dropped fields:\n%s", updated)
+	}
+	roundTrip, err := GenesisAlloc(updated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(roundTrip) != 3 || roundTrip["0x0100000000000000000000000000000000000000"].Balance.Int64() != 255 {
+		t.Errorf("round trip alloc = %v", roundTrip)
+	}
+
+	for _, bad := range []string{
+		`{"alloc": {"0xzz": {"balance": "1"}}}`,
+		`{"alloc": {"0x01": {"balance": "-1"}}}`,
+		`{"alloc": {"0x01": {"code": "0x60"}}}`,
+	} {
+		if _, err := GenesisAlloc([]byte(bad)); err == nil {
+			t.Errorf("GenesisAlloc(%s) succeeded, want error", bad)
+		}
+	}
+}
+
+// Run with -race to check that a shared manager is safe across goroutines
+func TestPluginPackageManagerConcurrentAccess(t *testing.T) {
+	pm, err := NewPluginPackageManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	binary := filepath.Join(t.TempDir(), "vm")
+	if err := os.WriteFile(binary, testPluginBinary, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	const workers = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, workers*3)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			name := fmt.Sprintf("vm%d", i)
+			m := &PluginManifest{Org: "luxfi", Name: name, Version: "v1.0.0", VMID: VMID("vm-" + name)}
+			if err := pm.Install(ctx, m, binary); err != nil {
+				errs <- err
+				return
+			}
+			if err := pm.Activate(ctx, "luxfi", name, "v1.0.0"); err != nil {
+				errs <- err
+			}
+			if _, err := pm.List(ctx); err != nil {
+				errs <- err
+			}
+			_, _ = pm.FindByVMID("vm-" + name)
+			_, _ = pm.ListActive(ctx)
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	manifests, err := pm.List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(manifests) != workers {
+		t.Errorf("List() = %d packages, want %d", len(manifests), workers)
+	}
+
+	// The registry on disk reflects every install
+	reloaded, err := NewPluginPackageManager(pm.baseDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reloaded.registry.Active) != workers {
+		t.Errorf("reloaded registry has %d active plugins, want %d", len(reloaded.registry.Active), workers)
+	}
+}
+
+func TestPluginPackageManagerMigrateAndExportLegacy(t *testing.T) {
+	pm, err := NewPluginPackageManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	binary := filepath.Join(t.TempDir(), "evm")
+	if err := os.WriteFile(binary, testPluginBinary, 0755); err != nil {
+		t.Fatal(err)
+	}
+	legacyDir := t.TempDir()
+	vmid := VMID("subnetevm")
+	if err := os.Symlink(binary, filepath.Join(legacyDir, vmid)); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(t.TempDir(), "gone"), filepath.Join(legacyDir, "danglingvmid")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(legacyDir, "subdir"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(legacyDir, "README"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := pm.MigrateFromLegacy(ctx, legacyDir)
+	if err != nil {
+		t.Fatalf("MigrateFromLegacy() error = %v", err)
+	}
+	if len(report.Migrated) != 1 || report.Migrated[0].VMID != vmid || report.Migrated[0].Package == "" {
+		t.Errorf("Migrated = %+v", report.Migrated)
+	}
+	if len(report.Failed) != 1 || report.Failed[0].VMID != "danglingvmid" || report.Failed[0].Reason == "" {
+		t.Errorf("Failed = %+v", report.Failed)
+	}
+	if len(report.Skipped) != 2 {
+		t.Errorf("Skipped = %+v, want README and subdir", report.Skipped)
+	}
+
+	// A VMID whose name collides with the first after case folding gets its
+	// own package instead of overwriting the first one
+	swapped := []byte(vmid)
+	for i := 1; i < 8; i++ {
+		if c := swapped[i] ^ 0x20; strings.IndexByte("123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz", c) >= 0 && c > '9' {
+			swapped[i] = c
+			break
+		}
+	}
+	raw := base58.Decode(string(swapped))
+	if len(raw) != 37 {
+		t.Fatalf("decoded %d bytes, want 37", len(raw))
+	}
+	twin := base58.CheckEncode(raw[1:33], 0)
+	if twin == vmid || !strings.EqualFold(twin[:8], vmid[:8]) {
+		t.Fatalf("%s does not collide with %s after case folding", twin, vmid)
+	}
+	twinDir := t.TempDir()
+	if err := os.Symlink(binary, filepath.Join(twinDir, twin)); err != nil {
+		t.Fatal(err)
+	}
+	report, err = pm.MigrateFromLegacy(ctx, twinDir)
+	if err != nil || len(report.Migrated) != 1 {
+		t.Fatalf("MigrateFromLegacy() = %+v, %v", report, err)
+	}
+	first := strings.ToLower(vmid[:8])
+	if got := report.Migrated[0].Package; got != "legacy/"+first+"-2@v0.0.0" {
+		t.Errorf("colliding VMID migrated as %s, want legacy/%s-2@v0.0.0", got, first)
+	}
+	if m, err := pm.GetManifest("legacy", first, "v0.0.0"); err != nil || m.VMID != vmid {
+		t.Errorf("first migrated package = %+v, %v; want vmid %s", m, err, vmid)
+	}
+
+	// Migrating the same VMID again reuses its package
+	report, err = pm.MigrateFromLegacy(ctx, twinDir)
+	if err != nil || len(report.Migrated)+len(report.Failed) != 1 {
+		t.Fatalf("second MigrateFromLegacy() = %+v, %v", report, err)
+	}
+	if _, ok := pm.registry.Plugins["legacy/"+first+"-3"]; ok {
+		t.Error("re-migrating a VMID created another package")
+	}
+
+	exportDir := filepath.Join(t.TempDir(), "legacy")
+	if err := pm.ExportLegacy(exportDir); err != nil {
+		t.Fatalf("ExportLegacy() error = %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(exportDir, vmid))
+	if err != nil || !bytes.Equal(data, testPluginBinary) {
+		t.Errorf("exported %s = %q, %v", vmid, data, err)
+	}
+
+	// Re-exporting replaces the symlinks but never regular files
+	if err := pm.ExportLegacy(exportDir); err != nil {
+		t.Fatalf("second ExportLegacy() error = %v", err)
+	}
+	if err := os.Remove(filepath.Join(exportDir, vmid)); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(exportDir, vmid), []byte("keep"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := pm.ExportLegacy(exportDir); err == nil {
+		t.Error("ExportLegacy() replaced a regular file")
+	}
+}
+
+func TestPluginPackageManagerWhich(t *testing.T) {
+	pm, err := NewPluginPackageManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	binary := filepath.Join(t.TempDir(), "vm")
+	if err := os.WriteFile(binary, testPluginBinary, 0755); err != nil {
+		t.Fatal(err)
+	}
+	evmID := VMID("subnetevm")
+	for _, m := range []*PluginManifest{
+		{Org: "luxfi", Name: "evm", Version: "v1.0.0", VMID: evmID, VMName: "subnetevm", Aliases: []string{"cevm"}},
+		{Org: "luxfi", Name: "evm", Version: "v1.2.0", VMID: evmID, VMName: "subnetevm", Aliases: []string{"cevm"}},
+		{Org: "acme", Name: "tools", Version: "v0.1.0", VMID: VMID("vm-tools")},
+		{Org: "acme", Name: "tools", Version: "v0.2.0", VMID: VMID("vm-tools")},
+	} {
+		if err := pm.Install(ctx, m, binary); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Roll evm back so active and newest differ
+	if err := pm.Activate(ctx, "luxfi", "evm", "v1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	if err := pm.Uninstall(ctx, "acme", "tools", "v0.2.0"); err != nil {
+		t.Fatal(err)
+	}
+
+	binaryOf := func(org, name, version string) string {
+		return filepath.Join(pm.PackagePath(org, name, version), name)
+	}
+	for ref, want := range map[string]string{
+		"luxfi/evm@v1.2.0": binaryOf("luxfi", "evm", "v1.2.0"),
+		"luxfi/evm":        binaryOf("luxfi", "evm", "v1.0.0"),
+		"evm":              binaryOf("luxfi", "evm", "v1.0.0"),
+		"cevm@v1.2.0":      binaryOf("luxfi", "evm", "v1.2.0"),
+		"subnetevm":        binaryOf("luxfi", "evm", "v1.0.0"),
+		evmID:              binaryOf("luxfi", "evm", "v1.0.0"),
+		"acme/tools":       binaryOf("acme", "tools", "v0.1.0"),
+	} {
+		got, err := pm.Which(ref)
+		if err != nil {
+			t.Errorf("Which(%q) error = %v", ref, err)
+			continue
+		}
+		if !filepath.IsAbs(got) || got != want {
+			t.Errorf("Which(%q) = %s, want %s", ref, got, want)
+		}
+	}
+
+	for _, ref := range []string{"luxfi/evm@v9.9.9", "nope", "acme/missing", VMID("vm-none")} {
+		if _, err := pm.Which(ref); !errors.Is(err, ErrPluginNotFound) {
+			t.Errorf("Which(%q) error = %v, want ErrPluginNotFound", ref, err)
+		}
+	}
+	if _, err := pm.Which("evm@"); err == nil || errors.Is(err, ErrPluginNotFound) {
+		t.Errorf("Which(evm@) error = %v, want an invalid reference error", err)
+	}
+}
+
+func TestValidatePackageRef(t *testing.T) {
+	for _, tc := range []struct {
+		org, name string
+		ok        bool
+	}{
+		{"luxfi", "evm", true},
+		{"lux-fi", "timestamp-vm2", true},
+		{"luxfi", "a/b", false},
+		{"lux/fi", "evm", false},
+		{"LuxFi", "evm", false},
+		{"luxfi", "my vm", false},
+		{"luxfi", "-evm", false},
+		{"luxfi", "evm.v2", false},
+		{"", "evm", false},
+	} {
+		if err := ValidatePackageRef(tc.org, tc.name); (err == nil) != tc.ok {
+			t.Errorf("ValidatePackageRef(%q, %q) error = %v, want ok=%v", tc.org, tc.name, err, tc.ok)
+		}
+	}
+
+	if org, name, err := NormalizePackageRef(" LuxFi ", "EVM"); err != nil || org != "luxfi" || name != "evm" {
+		t.Errorf("NormalizePackageRef() = %q, %q, %v", org, name, err)
+	}
+
+	pm, err := NewPluginPackageManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	binary := filepath.Join(t.TempDir(), "vm")
+	if err := os.WriteFile(binary, testPluginBinary, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	m := &PluginManifest{Org: "LuxFi", Name: "EVM", Version: "v1.0.0", VMID: VMID("vm-evm")}
+	if err := pm.Install(ctx, m, binary); err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+	if _, err := pm.GetManifest("luxfi", "evm", "v1.0.0"); err != nil {
+		t.Errorf("Install() did not normalize org/name: %v", err)
+	}
+
+	for _, bad := range []*PluginManifest{
+		{Org: "luxfi", Name: "a/b", Version: "v1.0.0", VMID: VMID("vm-ab")},
+		{Org: "luxfi", Name: "my vm", Version: "v1.0.0", VMID: VMID("vm-my")},
+	} {
+		if err := pm.Install(ctx, bad, binary); err == nil {
+			t.Errorf("Install(%s/%s) succeeded, want error", bad.Org, bad.Name)
+		}
+		if err := pm.Link(ctx, bad, binary); err == nil {
+			t.Errorf("Link(%s/%s) succeeded, want error", bad.Org, bad.Name)
+		}
+	}
+
+	manifests, err := pm.List(ctx)
+	if err != nil || len(manifests) != 1 {
+		t.Errorf("List() = %d packages, %v; want only the valid install", len(manifests), err)
+	}
+}
+
+func TestLogfmtEncoder(t *testing.T) {
+	factory := NewLogFactory(LogConfig{Level: "info", Format: "logfmt"})
+	cfg := factory.encoderConfig()
+	cfg.TimeKey = "" // Keep output deterministic
+
+	var buf bytes.Buffer
+	core := zapcore.NewCore(factory.createEncoder(cfg), zapcore.AddSync(&buf), zapcore.InfoLevel)
+	logger := zap.New(core).Named("node").With(zap.String("chain", "C"))
+
+	logger.Info("block accepted",
+		zap.Uint64("height", 42),
+		zap.String("hash", "0xabc"),
+		zap.String("note", `say "hi"`),
+		zap.Duration("took", 1500*time.Millisecond),
+		zap.Bool("final", true),
+		zap.Strings("peers", []string{"a", "b"}),
+		zap.String("empty", ""),
+	)
+
+	want := `level=INFO logger=node msg="block accepted" chain=C height=42 hash=0xabc note="say \"hi\"" took=1.5s final=true peers="[\"a\",\"b\"]" empty=""` + "\n"
+	if got := buf.String(); got != want {
+		t.Errorf("logfmt output:\n got: %s\nwant: %s", got, want)
+	}
+
+	buf.Reset()
+	logger.With(zap.Namespace("req")).Warn("slow", zap.Int("ms", 900))
+	if got := buf.String(); !strings.HasSuffix(got, "chain=C req.ms=900\n") {
+		t.Errorf("namespaced output = %q", got)
+	}
+
+	cfgLogfmt := DefaultConfig()
+	cfgLogfmt.Log.Format = "logfmt"
+	if err := cfgLogfmt.Validate(); err != nil {
+		t.Errorf("Validate() with logfmt format error = %v", err)
+	}
+	if got := factory.Outputs()[0].Format; got != "logfmt" {
+		t.Errorf("console format = %s, want logfmt", got)
+	}
+}
+
+func TestLogFactoryEncoderKeys(t *testing.T) {
+	factory := NewLogFactory(LogConfig{
+		Level: "info", Format: "json",
+		TimeKey: "@timestamp", LevelKey: "severity", MessageKey: "message",
+		TimeLayout: time.RFC3339,
+	})
+	cfg := factory.encoderConfig()
+
+	var buf bytes.Buffer
+	core := zapcore.NewCore(factory.createEncoder(cfg), zapcore.AddSync(&buf), zapcore.InfoLevel)
+	when := time.Date(2025, 5, 1, 10, 0, 0, 0, time.UTC)
+	if err := core.Write(zapcore.Entry{Level: zapcore.InfoLevel, Time: when, Message: "hello"}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	var line map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("output %q is not JSON: %v", buf.String(), err)
+	}
+	if line["@timestamp"] != "2025-05-01T10:00:00Z" || line["severity"] != "INFO" || line["message"] != "hello" {
+		t.Errorf("custom keys output = %v", line)
+	}
+
+	// Defaults are unchanged when the fields are empty
+	def := NewLogFactory(LogConfig{}).encoderConfig()
+	if def.TimeKey != "time" || def.LevelKey != "level" || def.MessageKey != "msg" {
+		t.Errorf("default keys = %s/%s/%s", def.TimeKey, def.LevelKey, def.MessageKey)
+	}
+
+	bad := DefaultConfig()
+	bad.Log.MessageKey = "level"
+	if err := bad.Validate(); err == nil {
+		t.Error("Validate() accepted colliding encoder keys")
+	}
+
+	t.Setenv("LUX_LOG_TIME_KEY", "ts")
+	loaded, err := NewLoader(WithConfigPaths(t.TempDir())).Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Log.TimeKey != "ts" {
+		t.Errorf("Log.TimeKey from env = %q, want ts", loaded.Log.TimeKey)
+	}
+}
+
+func TestLoaderResolvePathsRelativeToConfig(t *testing.T) {
+	dir := t.TempDir()
+	config := `{"data-dir": "/srv/lux", "plugin-dir": "plugins", "log": {"directory": "./logs"}}`
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "config.dev.json"), []byte(`{"log": {"directory": "dev-logs"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := NewLoader(WithConfigPaths(dir)).Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.PluginDir != "plugins" {
+		t.Errorf("PluginDir without option = %s, want it unchanged", cfg.PluginDir)
+	}
+
+	cfg, err = NewLoader(WithConfigPaths(dir), ResolvePathsRelativeToConfig()).Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.PluginDir != filepath.Join(dir, "plugins") || cfg.Log.Directory != filepath.Join(dir, "logs") {
+		t.Errorf("paths = %s, %s; want them under %s", cfg.PluginDir, cfg.Log.Directory, dir)
+	}
+	if cfg.DataDir != "/srv/lux" {
+		t.Errorf("absolute DataDir = %s, want unchanged", cfg.DataDir)
+	}
+
+	// Profile values resolve too; env values stay relative to the CWD
+	t.Setenv("LUX_PLUGIN_DIR", "env-plugins")
+	cfg, err = NewLoader(WithConfigPaths(dir), WithProfile("dev"), ResolvePathsRelativeToConfig()).Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Log.Directory != filepath.Join(dir, "dev-logs") || cfg.PluginDir != "env-plugins" {
+		t.Errorf("paths = %s, %s; want profile path resolved and env path untouched", cfg.Log.Directory, cfg.PluginDir)
+	}
+}
+
+func TestPathsDiskUsage(t *testing.T) {
+	paths := NewPaths(t.TempDir())
+	write := func(path string, size int) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, make([]byte, size), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(paths.ChainGenesis("zoo"), 100)
+	write(filepath.Join(paths.NodeDir("local", "run_1", "node1"), "db", "data"), 1000)
+	if err := os.Symlink(paths.ChainGenesis("zoo"), filepath.Join(paths.ChainDir("zoo"), "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	usage, err := paths.DiskUsage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usage.Dirs[ChainsDir] != 100 || usage.Dirs[NetworksDir] != 1000 || usage.Dirs[PluginsDir] != 0 || usage.Total != 1100 {
+		t.Errorf("DiskUsage() = %+v", usage)
+	}
+
+	// A new file in a nested directory is picked up
+	dbDir := filepath.Join(paths.NodeDir("local", "run_1", "node1"), "db")
+	write(filepath.Join(dbDir, "more"), 500)
+	if size, err := paths.DirSize(paths.NetworksBaseDir()); err != nil || size != 1500 {
+		t.Errorf("DirSize() after adding a file = %d, %v; want 1500", size, err)
+	}
+
+	// An unchanged directory mtime reuses the cached listing
+	info, err := os.Stat(dbDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	write(filepath.Join(dbDir, "hidden"), 700)
+	if err := os.Chtimes(dbDir, info.ModTime(), info.ModTime()); err != nil {
+		t.Fatal(err)
+	}
+	if size, err := paths.DirSize(paths.NetworksBaseDir()); err != nil || size != 1500 {
+		t.Errorf("DirSize() with unchanged mtime = %d, %v; want cached 1500", size, err)
+	}
+
+	// Removing a subtree drops it
+	if err := os.RemoveAll(paths.NetworkDir("local")); err != nil {
+		t.Fatal(err)
+	}
+	if size, err := paths.DirSize(paths.NetworksBaseDir()); err != nil || size != 0 {
+		t.Errorf("DirSize() after removal = %d, %v; want 0", size, err)
+	}
+	if len(paths.sizes.entries) != 3 {
+		t.Errorf("cache holds %d entries after removal, want chains/, chains/zoo/, and networks/ only", len(paths.sizes.entries))
+	}
+}
+
+func TestLoadNodeChainConfigs(t *testing.T) {
+	cm := NewChainManager(NewPaths(t.TempDir()))
+	if err := cm.SaveChain(&ChainConfig{
+		Name:    "zoo",
+		Genesis: []byte(`{"config":{"chainId":200200}}`),
+		Config:  []byte(`{"eth-apis":["eth"]}`),
+		Upgrade: []byte(`{"precompileUpgrades":[]}`),
+		Extra:   map[string][]byte{"allowlist.json": []byte(`[]`)},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := cm.SaveChain(&ChainConfig{Name: "bare", Genesis: []byte(`{}`), Config: []byte(`{}`)}); err != nil {
+		t.Fatal(err)
+	}
+
+	nodeDir := t.TempDir()
+	for chainID, name := range map[string]string{"2ZooChainID": "zoo", "2BareChainID": "bare"} {
+		if err := cm.CopyChainConfigsToNode(name, chainID, nodeDir); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	chainConfigDir := filepath.Join(nodeDir, NodeConfigsDir, NodeChainConfigsDir)
+	configs, err := LoadNodeChainConfigs(chainConfigDir)
+	if err != nil {
+		t.Fatalf("LoadNodeChainConfigs() error = %v", err)
+	}
+	if len(configs) != 2 {
+		t.Fatalf("LoadNodeChainConfigs() = %d chains, want 2", len(configs))
+	}
+	zoo := configs["2ZooChainID"]
+	if zoo.Name != "2ZooChainID" || string(zoo.Config) != `{"eth-apis":["eth"]}` || string(zoo.Upgrade) != `{"precompileUpgrades":[]}` {
+		t.Errorf("zoo = %+v", zoo)
+	}
+	if string(zoo.Extra["allowlist.json"]) != "[]" || zoo.Genesis != nil {
+		t.Errorf("zoo extras = %v, genesis = %s", zoo.Extra, zoo.Genesis)
+	}
+	if bare := configs["2BareChainID"]; bare.Upgrade != nil || bare.Extra != nil {
+		t.Errorf("bare = %+v, want only config", bare)
+	}
+
+	if configs, err := LoadNodeChainConfigs(filepath.Join(t.TempDir(), "missing")); err != nil || configs != nil {
+		t.Errorf("LoadNodeChainConfigs(missing) = %v, %v", configs, err)
+	}
+}
+
+func TestWalksHonorCancelledContext(t *testing.T) {
+	paths := NewPaths(t.TempDir())
+	cm := NewChainManager(paths)
+	if err := cm.SaveChain(&ChainConfig{Name: "zoo", Genesis: []byte(`{}`), Config: []byte(`{}`)}); err != nil {
+		t.Fatal(err)
+	}
+	nodeDir := paths.NodeDir("local", "run_20250101_000000", "node1")
+	if err := os.MkdirAll(nodeDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := cm.CopyChainConfigsToNode("zoo", "chain-id", nodeDir); err != nil {
+		t.Fatal(err)
+	}
+
+	pm, err := NewPluginPackageManager(paths.PluginsBaseDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	binary := filepath.Join(t.TempDir(), "vm")
+	if err := os.WriteFile(binary, testPluginBinary, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := pm.Install(context.Background(), &PluginManifest{Org: "luxfi", Name: "evm", Version: "v1.0.0", VMID: VMID("vm-evm")}, binary); err != nil {
+		t.Fatal(err)
+	}
+	legacyDir := t.TempDir()
+	if err := os.Symlink(binary, filepath.Join(legacyDir, "legacyvmid")); err != nil {
+		t.Fatal(err)
+	}
+	flat := NewPluginManagerWithDir(legacyDir)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	walks := map[string]func() error{
+		"ListChainsContext": func() error { _, err := cm.ListChainsContext(ctx); return err },
+		"LoadNodeChainConfigsContext": func() error {
+			_, err := LoadNodeChainConfigsContext(ctx, filepath.Join(nodeDir, NodeConfigsDir, NodeChainConfigsDir))
+			return err
+		},
+		"ListNodesContext":                        func() error { _, err := paths.ListNodesContext(ctx, "local", "run_20250101_000000"); return err },
+		"FindLatestRunContext":                    func() error { _, err := paths.FindLatestRunContext(ctx, "local"); return err },
+		"DiskUsageContext":                        func() error { _, err := paths.DiskUsageContext(ctx); return err },
+		"DirSizeContext":                          func() error { _, err := paths.DirSizeContext(ctx, paths.BaseDir); return err },
+		"MigrateBaseDirContext":                   func() error { _, err := paths.MigrateBaseDirContext(ctx, t.TempDir()); return err },
+		"PluginPackageManager.List":               func() error { _, err := pm.List(ctx); return err },
+		"PluginPackageManager.ListActive":         func() error { _, err := pm.ListActive(ctx); return err },
+		"PluginPackageManager.VerifyAll":          func() error { _, err := pm.VerifyAll(ctx); return err },
+		"PluginPackageManager.RelinkAll":          func() error { _, err := pm.RelinkAll(ctx); return err },
+		"PluginPackageManager.CheckVMIDConflicts": func() error { _, err := pm.CheckVMIDConflicts(ctx); return err },
+		"PluginPackageManager.MigrateFromLegacy":  func() error { _, err := pm.MigrateFromLegacy(ctx, legacyDir); return err },
+		"DefaultPluginManager.List":               func() error { _, err := flat.List(ctx); return err },
+	}
+	for name, walk := range walks {
+		if err := walk(); !errors.Is(err, context.Canceled) {
+			t.Errorf("%s error = %v, want context.Canceled", name, err)
+		}
+	}
+
+	// Nothing was moved by the cancelled migration
+	if !Exists(paths.ChainGenesis("zoo")) {
+		t.Error("cancelled MigrateBaseDirContext moved the chains tree")
+	}
+}
+
+func TestNodeIDFromCert(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{SerialNumber: big.NewInt(1), NotBefore: time.Now(), NotAfter: time.Now().Add(time.Hour)}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+
+	nodeID, err := NodeIDFromCert(certPEM)
+	if err != nil {
+		t.Fatalf("NodeIDFromCert() error = %v", err)
+	}
+	digest := sha256.Sum256(der)
+	h := ripemd160.New()
+	h.Write(digest[:])
+	want := h.Sum(nil)
+
+	if !strings.HasPrefix(nodeID, "NodeID-") {
+		t.Fatalf("NodeIDFromCert() = %s, want NodeID- prefix", nodeID)
+	}
+	decoded := base58.Decode(strings.TrimPrefix(nodeID, "NodeID-"))
+	if len(decoded) != 24 || !bytes.Equal(decoded[:20], want) {
+		t.Fatalf("NodeIDFromCert() payload = %x, want %x", decoded, want)
+	}
+	checksum := sha256.Sum256(decoded[:20])
+	if !bytes.Equal(decoded[20:], checksum[28:]) {
+		t.Errorf("NodeIDFromCert() checksum = %x, want %x", decoded[20:], checksum[28:])
+	}
+
+	paths := NewPaths(t.TempDir())
+	if err := paths.WriteNodeKeyFile("local", "node1", StakingCertFile, certPEM); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := paths.NodeIDForNode("local", "node1"); err != nil || got != nodeID {
+		t.Errorf("NodeIDForNode() = %s, %v; want %s", got, err, nodeID)
+	}
+	if _, err := paths.NodeIDForNode("local", "node2"); err == nil {
+		t.Error("NodeIDForNode() succeeded without a certificate")
+	}
+	if _, err := NodeIDFromCert([]byte("not a cert")); err == nil {
+		t.Error("NodeIDFromCert() accepted invalid PEM")
+	}
+}
+
+func TestLogLevelConversions(t *testing.T) {
+	for _, level := range []LogLevel{LogLevelDebug, LogLevelInfo, LogLevelWarn, LogLevelError, LogLevelFatal, LogLevelOff} {
+		if got := FromZapLevel(ToZapLevel(level)); got != level {
+			t.Errorf("FromZapLevel(ToZapLevel(%s)) = %s", level, got)
+		}
+	}
+	if got := ToZapLevel(LogLevelTrace); got != zapcore.DebugLevel {
+		t.Errorf("ToZapLevel(trace) = %v, want debug", got)
+	}
+	if got := FromZapLevel(zapcore.PanicLevel); got != LogLevelError {
+		t.Errorf("FromZapLevel(panic) = %s, want error", got)
+	}
+
+	if level, err := ParseLogLevel("verbo"); err != nil || level != LogLevelVerbo {
+		t.Errorf("ParseLogLevel(verbo) = %s, %v", level, err)
+	}
+	if _, err := ParseLogLevel("loud"); err == nil {
+		t.Error("ParseLogLevel() accepted an unknown level")
+	}
+}
+
+func TestTelemetryConfig(t *testing.T) {
+	cfg := DefaultConfig()
+	s := spec.MustSpec()
+	if want := s.GetFlag("tracing-sample-rate").Default; cfg.Telemetry.SampleRate != want {
+		t.Errorf("SampleRate = %v, want spec default %v", cfg.Telemetry.SampleRate, want)
+	}
+	if want := s.GetFlag("api-metrics-enabled").Default; cfg.Telemetry.MetricsEnabled != want {
+		t.Errorf("MetricsEnabled = %v, want spec default %v", cfg.Telemetry.MetricsEnabled, want)
+	}
+	if cfg.Telemetry.TracingEnabled {
+		t.Error("TracingEnabled defaulted to true, want false for the disabled exporter")
+	}
+
+	t.Setenv("LUX_TELEMETRY_SAMPLE_RATE", "0.5")
+	loaded, err := NewLoader(WithConfigPaths(t.TempDir())).Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Telemetry.SampleRate != 0.5 || loaded.Telemetry.MetricsEnabled != cfg.Telemetry.MetricsEnabled {
+		t.Errorf("loaded telemetry = %+v", loaded.Telemetry)
+	}
+
+	cfg.Telemetry.SampleRate = 1.5
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() accepted a sample rate above 1")
+	}
+	cfg.Telemetry.SampleRate = 1
+	cfg.Telemetry.MetricsPort = 70000
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() accepted an out-of-range metrics port")
+	}
+}
+
+func TestNodeArgsForCategories(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.PluginDir = t.TempDir()
+	cfg.Network.ID = 96368
+	cfg.Node.StakingPort = 9651
+
+	args := ToNodeArgs(cfg)
+	for _, want := range []string{"--network-id=96368", "--staking-port=9651", "--plugin-dir=" + cfg.PluginDir, "--tracing-exporter-type=disabled"} {
+		if !contains(args, want) {
+			t.Errorf("ToNodeArgs() = %v, missing %s", args, want)
+		}
+	}
+
+	args, err := NodeArgsForCategories(cfg, spec.CategoryNetwork, spec.CategoryStaking)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := strings.Join(args, " "), "--network-id=96368 --staking-port=9651"; got != want {
+		t.Errorf("NodeArgsForCategories(network, staking) = %s, want %s", got, want)
+	}
+}
+
+func TestPluginPackageManagerTrash(t *testing.T) {
+	pm, err := NewPluginPackageManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	binary := filepath.Join(t.TempDir(), "vm")
+	if err := os.WriteFile(binary, testPluginBinary, 0755); err != nil {
+		t.Fatal(err)
+	}
+	m := &PluginManifest{Org: "luxfi", Name: "evm", Version: "v1.0.0", VMID: VMID("vm-evm")}
+	if err := pm.Install(ctx, m, binary, WithImmutable()); err != nil {
+		t.Fatal(err)
+	}
+
+	// A failed move to the trash leaves the package active
+	trashRoot := filepath.Join(pm.baseDir, trashDir)
+	if err := os.WriteFile(trashRoot, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := pm.Uninstall(ctx, "luxfi", "evm", "v1.0.0", WithTrash()); err == nil {
+		t.Fatal("Uninstall(WithTrash) succeeded without a trash directory")
+	}
+	if pm.registry.Active[VMID("vm-evm")] != "luxfi/evm@v1.0.0" || !IsSymlink(pm.ActivePath(VMID("vm-evm"))) {
+		t.Error("failed Uninstall(WithTrash) removed the package's links")
+	}
+	if err := os.Remove(trashRoot); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := pm.Uninstall(ctx, "luxfi", "evm", "v1.0.0", WithTrash()); err != nil {
+		t.Fatalf("Uninstall(WithTrash) error = %v", err)
+	}
+	if Exists(pm.PackagePath("luxfi", "evm", "v1.0.0")) || pm.registry.Active[VMID("vm-evm")] != "" {
+		t.Fatal("trashed package is still installed")
+	}
+	trash, err := pm.ListTrash()
+	if err != nil || len(trash) != 1 {
+		t.Fatalf("ListTrash() = %v, %v, want one entry", trash, err)
+	}
+	// Write permission is restored so the immutable package can be moved
+	if info, err := os.Stat(trash[0].Path); err != nil {
+		t.Error(err)
+	} else if info.Mode().Perm()&0200 == 0 {
+		t.Errorf("trashed package mode = %v, want owner-writable", info.Mode())
+	}
+
+	if err := pm.RestoreTrashed("luxfi", "evm", "v1.0.0"); err != nil {
+		t.Fatalf("RestoreTrashed() error = %v", err)
+	}
+	if pm.registry.Active[VMID("vm-evm")] != "luxfi/evm@v1.0.0" {
+		t.Error("RestoreTrashed() did not reactivate the package")
+	}
+	if _, err := os.Stat(pm.ActivePath(VMID("vm-evm"))); err != nil {
+		t.Errorf("VMID symlink not restored: %v", err)
+	}
+	if err := pm.RestoreTrashed("luxfi", "evm", "v1.0.0"); !errors.Is(err, ErrPluginNotFound) {
+		t.Errorf("RestoreTrashed() with empty trash error = %v, want ErrPluginNotFound", err)
+	}
+
+	if err := pm.Uninstall(ctx, "luxfi", "evm", "v1.0.0", WithTrash()); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := pm.PurgeTrash(time.Hour); err != nil || n != 0 {
+		t.Errorf("PurgeTrash(1h) = %d, %v, want nothing purged", n, err)
+	}
+	if n, err := pm.PurgeTrash(0); err != nil || n != 1 {
+		t.Errorf("PurgeTrash(0) = %d, %v, want 1", n, err)
+	}
+	if trash, _ := pm.ListTrash(); len(trash) != 0 {
+		t.Errorf("trash not empty after purge: %v", trash)
+	}
+}
+
+func TestPluginPackageManagerRejectsInvalidBinaries(t *testing.T) {
+	pm, err := NewPluginPackageManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	htmlPage := []byte("<!DOCTYPE html><html><body>404 Not Found</body></html>\n" + strings.Repeat(" ", 64))
+	script := []byte("#!/bin/sh\nexec python3 plugin.py \"$@\"\n" + strings.Repeat("#", 64))
+	for name, content := range map[string][]byte{"empty": nil, "html": htmlPage, "script": script} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, content, 0755); err != nil {
+			t.Fatal(err)
+		}
+		m := &PluginManifest{Org: "luxfi", Name: name, Version: "v1.0.0", VMID: VMID("vm-" + name)}
+		if err := pm.Install(ctx, m, path); !errors.Is(err, ErrInvalidPluginBinary) {
+			t.Errorf("Install(%s) error = %v, want ErrInvalidPluginBinary", name, err)
+		}
+		if Exists(pm.PackagePath("luxfi", name, "v1.0.0")) {
+			t.Errorf("Install(%s) left a package directory behind", name)
+		}
+	}
+
+	// Exotic plugins can opt out of the format check, but not the size check
+	m := &PluginManifest{Org: "luxfi", Name: "script", Version: "v1.0.0", VMID: VMID("vm-script")}
+	if err := pm.Install(ctx, m, filepath.Join(dir, "script"), WithoutFormatCheck()); err != nil {
+		t.Errorf("Install(WithoutFormatCheck) error = %v", err)
+	}
+	m = &PluginManifest{Org: "luxfi", Name: "empty", Version: "v1.0.0", VMID: VMID("vm-empty")}
+	if err := pm.Install(ctx, m, filepath.Join(dir, "empty"), WithoutFormatCheck()); !errors.Is(err, ErrInvalidPluginBinary) {
+		t.Errorf("Install(empty, WithoutFormatCheck) error = %v, want ErrInvalidPluginBinary", err)
+	}
+}
+
+func TestPluginPackageManagerCheckChainVMs(t *testing.T) {
+	pm, err := NewPluginPackageManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	binary := filepath.Join(t.TempDir(), "vm")
+	if err := os.WriteFile(binary, testPluginBinary, 0755); err != nil {
+		t.Fatal(err)
+	}
+	m := &PluginManifest{Org: "luxfi", Name: "evm", Version: "v1.0.0", VMID: VMID("vm-evm")}
+	if err := pm.Install(context.Background(), m, binary); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := pm.CheckChainVMs(map[string]string{"zoo": VMID("vm-evm"), "hanzo": VMID("vm-evm"), "spc": VMID("vm-spc")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Active) != 1 || report.Active[0] != VMID("vm-evm") {
+		t.Errorf("Active = %v, want [vm-evm]", report.Active)
+	}
+	if len(report.Missing) != 1 || report.Missing[0].VMID != VMID("vm-spc") || report.Missing[0].Chains[0] != "spc" {
+		t.Errorf("Missing = %+v, want vm-spc for spc", report.Missing)
+	}
+	if err := report.Err(); !errors.Is(err, ErrPluginNotFound) || !strings.Contains(err.Error(), "chain spc needs VM "+VMID("vm-spc")) {
+		t.Errorf("Err() = %v", err)
+	}
+
+	// An active entry whose binary is gone counts as missing
+	if err := os.Remove(filepath.Join(pm.PackagePath("luxfi", "evm", "v1.0.0"), "evm")); err != nil {
+		t.Fatal(err)
+	}
+	report, err = pm.CheckChainVMs(map[string]string{"zoo": VMID("vm-evm")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Active) != 0 || len(report.Missing) != 1 {
+		t.Errorf("report with missing binary = %+v", report)
+	}
+}
+
+func TestPresetConfig(t *testing.T) {
+	mainnet, err := PresetConfig("mainnet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	local, err := PresetConfig("Local")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if local.Network.Name != NetworkLocal || local.Network.ID != LocalID {
+		t.Errorf("local network = %+v", local.Network)
+	}
+	if want := filepath.Join(mainnet.DataDir, NetworkLocal); local.DataDir != want {
+		t.Errorf("local DataDir = %s, want %s", local.DataDir, want)
+	}
+	if local.Paths().ChainsBaseDir() == mainnet.Paths().ChainsBaseDir() {
+		t.Error("local and mainnet share a chains directory")
+	}
+	if local.PluginDir != mainnet.PluginDir {
+		t.Error("plugin directory should stay shared across networks")
+	}
+	if err := local.Validate(); err != nil {
+		t.Errorf("local preset is invalid: %v", err)
+	}
+
+	shared, err := PresetConfig("testnet", WithSharedDataDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shared.DataDir != mainnet.DataDir {
+		t.Errorf("shared testnet DataDir = %s, want %s", shared.DataDir, mainnet.DataDir)
+	}
+
+	if _, err := PresetConfig("nonet"); err == nil {
+		t.Error("PresetConfig() accepted an unknown network")
+	}
+}
+
+func TestValidateVMID(t *testing.T) {
+	id := sha256.Sum256([]byte("vm"))
+	for _, vmid := range []string{VMID(VMNameLuxEVM), cb58Encode(id[:])} {
+		if err := ValidateVMID(vmid); err != nil {
+			t.Errorf("ValidateVMID(%s) error = %v", vmid, err)
+		}
+	}
+
+	valid := VMID(VMNameLuxEVM)
+	typo := valid[:10] + "1" + valid[11:]
+	for _, vmid := range []string{"", "vm-evm", typo, cb58Encode(id[:20]), "0OIl"} {
+		if err := ValidateVMID(vmid); !errors.Is(err, ErrInvalidVMID) {
+			t.Errorf("ValidateVMID(%q) error = %v, want ErrInvalidVMID", vmid, err)
+		}
+	}
+
+	pm, err := NewPluginPackageManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	binary := filepath.Join(t.TempDir(), "vm")
+	if err := os.WriteFile(binary, testPluginBinary, 0755); err != nil {
+		t.Fatal(err)
+	}
+	m := &PluginManifest{Org: "luxfi", Name: "evm", Version: "v1.0.0", VMID: typo}
+	if err := pm.Install(context.Background(), m, binary); !errors.Is(err, ErrInvalidVMID) {
+		t.Errorf("Install() with a typo'd VMID error = %v, want ErrInvalidVMID", err)
+	}
+	if _, err := os.Lstat(pm.ActivePath(typo)); !os.IsNotExist(err) {
+		t.Error("Install() created a symlink for a malformed VMID")
+	}
+}
+
+func TestFetchIndex(t *testing.T) {
+	sum := sha256.Sum256(testPluginBinary)
+	checksum := hex.EncodeToString(sum[:])
+	vmid := VMID("subnetevm")
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/evm", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(testPluginBinary)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	index := fmt.Sprintf(`{"packages": [{"org": "luxfi", "name": "evm", "versions": [
+		{"version": "v1.2.0", "url": "%[1]s/evm", "checksum": "%[2]s", "vmid": "%[3]s"},
+		{"version": "v1.0.0", "url": "%[1]s/evm", "checksum": "%[2]s", "vmid": "%[3]s"},
+		{"version": "v1.3.0-rc.1", "url": "%[1]s/evm", "vmid": "%[3]s"}
+	]}]}`, srv.URL, checksum, vmid)
+	mux.HandleFunc("/index.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(index))
+	})
+
+	idx, err := FetchIndex(context.Background(), srv.URL+"/index.json")
+	if err != nil {
+		t.Fatalf("FetchIndex() error = %v", err)
+	}
+	if got := strings.Join(idx.Versions("luxfi", "evm"), ","); got != "v1.0.0,v1.2.0,v1.3.0-rc.1" {
+		t.Errorf("Versions() = %s", got)
+	}
+	release, err := idx.Resolve("luxfi", "evm", "latest")
+	if err != nil || release.Version != "v1.2.0" || release.Checksum != checksum {
+		t.Fatalf("Resolve(latest) = %+v, %v", release, err)
+	}
+	if _, err := idx.Resolve("luxfi", "evm", "v9.0.0"); !errors.Is(err, ErrPluginNotFound) {
+		t.Errorf("Resolve(v9.0.0) error = %v, want ErrPluginNotFound", err)
+	}
+
+	// The index feeds InstallFromURL and UpgradePlan
+	pm, err := NewPluginPackageManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	old, _ := idx.Resolve("luxfi", "evm", "v1.0.0")
+	if err := pm.InstallFromURL(context.Background(), old.Manifest(), old.URL, old.Checksum); err != nil {
+		t.Fatalf("InstallFromURL() error = %v", err)
+	}
+	plan, err := pm.UpgradePlan(idx.Available())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan) != 1 || plan[0].Status != UpgradeAvailable || plan[0].Latest != "v1.2.0" {
+		t.Errorf("UpgradePlan() = %+v", plan)
+	}
+
+	if _, err := ReadIndex(strings.NewReader(`{"packages": [{"org": "luxfi", "name": "evm", "versions": [{"version": "v1", "url": "x", "vmid": "bad"}]}]}`)); err == nil {
+		t.Error("ReadIndex() accepted an invalid release")
+	}
+}
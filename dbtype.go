@@ -0,0 +1,62 @@
+// Copyright (C) 2024-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package config
+
+import (
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+// DefaultDBType is the database backend used when nothing else is chosen
+const DefaultDBType = "badgerdb"
+
+// DBTypes are the supported database backends
+var DBTypes = []string{"badgerdb", "leveldb", "pebbledb", "memdb"}
+
+// IsSupportedDBType reports whether dbType is one of DBTypes
+func IsSupportedDBType(dbType string) bool {
+	return contains(DBTypes, dbType)
+}
+
+// DetectPreferredDBType recommends a database backend for the running
+// platform. A binary built with a backend's name as a build tag (e.g.
+// -tags pebbledb) prefers that backend. Otherwise wasm targets, which have
+// no persistent filesystem, get memdb and 32-bit targets, where badgerdb's
+// memory maps don't fit, get leveldb. Everything else uses DefaultDBType.
+func DetectPreferredDBType() string {
+	return preferredDBType(runtime.GOOS, runtime.GOARCH, buildTags())
+}
+
+// preferredDBType implements DetectPreferredDBType
+func preferredDBType(goos, goarch string, tags []string) string {
+	for _, tag := range tags {
+		if IsSupportedDBType(tag) {
+			return tag
+		}
+	}
+
+	switch {
+	case goos == "js" || goos == "wasip1" || goarch == "wasm":
+		return "memdb"
+	case contains([]string{"386", "arm", "mips", "mipsle"}, goarch):
+		return "leveldb"
+	default:
+		return DefaultDBType
+	}
+}
+
+// buildTags returns the -tags the running binary was built with
+func buildTags() []string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return nil
+	}
+	for _, setting := range info.Settings {
+		if setting.Key == "-tags" && setting.Value != "" {
+			return strings.Split(setting.Value, ",")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+// Copyright (C) 2024-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/luxfi/config/spec"
+)
+
+// DefaultMismatch is a key whose package default differs from the spec default
+type DefaultMismatch struct {
+	Key     string      `json:"key"`
+	Package interface{} `json:"package"`
+	Spec    interface{} `json:"spec"`
+}
+
+// CompareDefaults reports every LuxConfig-backed flag present in the node
+// spec whose DefaultConfig value disagrees with the spec's default.
+// Spec defaults are normalized before comparing: $HOME and other env vars
+// are expanded, $LUXD_DATA_DIR is replaced with the package's data dir, and
+// network names are resolved to their registered IDs.
+func CompareDefaults() ([]DefaultMismatch, error) {
+	s, err := spec.Spec()
+	if err != nil {
+		return nil, fmt.Errorf("error loading config spec: %w", err)
+	}
+
+	cfg := DefaultConfig()
+	var mismatches []DefaultMismatch
+	for _, entry := range sampleEntries(cfg) {
+		f := s.GetFlag(entry.flag)
+		if f == nil {
+			continue
+		}
+		specDefault := normalizeSpecDefault(f.Default, cfg.DataDir, entry.value)
+		if fmt.Sprint(specDefault) != fmt.Sprint(entry.value) {
+			mismatches = append(mismatches, DefaultMismatch{Key: entry.flag, Package: entry.value, Spec: f.Default})
+		}
+	}
+
+	return mismatches, nil
+}
+
+// normalizeSpecDefault puts a spec default in the form of the package value
+func normalizeSpecDefault(value interface{}, dataDir string, packageValue interface{}) interface{} {
+	str, ok := value.(string)
+	if !ok {
+		return value
+	}
+
+	if _, isID := packageValue.(uint32); isID {
+		if id, ok := LookupNetworkID(str); ok {
+			return id
+		}
+	}
+
+	return os.Expand(str, func(name string) string {
+		if name == LuxNodeDataDirVar {
+			return dataDir
+		}
+		return os.Getenv(name)
+	})
+}
@@ -0,0 +1,169 @@
+// Copyright (C) 2021-2025, Lux Industries Inc. All rights reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DiskUsage is the space used under the base directory
+type DiskUsage struct {
+	Dirs  map[string]int64 `json:"dirs"` // Bytes per top-level directory (chains, networks, ...)
+	Total int64            `json:"total"`
+}
+
+// dirSizeEntry caches the direct contents of one directory
+type dirSizeEntry struct {
+	modTime time.Time
+	files   int64    // Total size of regular files directly in the directory
+	subdirs []string // Names of subdirectories
+}
+
+// dirSizeCache holds dirSizeEntry values keyed by directory path
+type dirSizeCache struct {
+	mu      sync.Mutex
+	entries map[string]dirSizeEntry
+}
+
+// sizeCacheMu guards lazy creation of Paths.sizes
+var sizeCacheMu sync.Mutex
+
+// sizeCache returns the Paths' size cache, creating it if needed
+func (p *Paths) sizeCache() *dirSizeCache {
+	sizeCacheMu.Lock()
+	defer sizeCacheMu.Unlock()
+	if p.sizes == nil {
+		p.sizes = &dirSizeCache{entries: make(map[string]dirSizeEntry)}
+	}
+	return p.sizes
+}
+
+// DiskUsage reports the bytes used by each top-level directory under
+// BaseDir. Missing directories count as zero. See DirSize for caching.
+func (p *Paths) DiskUsage() (*DiskUsage, error) {
+	return p.DiskUsageContext(context.Background())
+}
+
+// DiskUsageContext is DiskUsage, returning ctx.Err() if ctx is cancelled
+// during the walk
+func (p *Paths) DiskUsageContext(ctx context.Context) (*DiskUsage, error) {
+	usage := &DiskUsage{Dirs: make(map[string]int64, len(baseSubdirs))}
+	for _, dir := range baseSubdirs {
+		size, err := p.DirSizeContext(ctx, filepath.Join(p.BaseDir, dir))
+		if err != nil {
+			return nil, err
+		}
+		usage.Dirs[dir] = size
+		usage.Total += size
+	}
+	return usage, nil
+}
+
+// DirSize returns the total size of regular files under dir; symlinks are
+// not followed and a missing dir has size zero. Each directory's direct
+// contents are cached in memory keyed by its mtime, so repeated calls only
+// list directories that gained, lost, or renamed entries since the last
+// call. Because a file written in place does not change its directory's
+// mtime, size changes of existing files may be missed until an entry in
+// that directory changes.
+func (p *Paths) DirSize(dir string) (int64, error) {
+	return p.DirSizeContext(context.Background(), dir)
+}
+
+// DirSizeContext is DirSize, returning ctx.Err() if ctx is cancelled
+// during the walk
+func (p *Paths) DirSizeContext(ctx context.Context, dir string) (int64, error) {
+	c := p.sizeCache()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.size(ctx, filepath.Clean(dir))
+}
+
+// size returns the cached or freshly scanned size of dir's subtree
+func (c *dirSizeCache) size(ctx context.Context, dir string) (int64, error) {
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	default:
+	}
+
+	info, err := os.Lstat(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			c.forget(dir)
+			return 0, nil
+		}
+		return 0, err
+	}
+	if !info.IsDir() {
+		return 0, fmt.Errorf("not a directory: %s", dir)
+	}
+
+	entry, ok := c.entries[dir]
+	if !ok || !entry.modTime.Equal(info.ModTime()) {
+		fresh, err := scanDirSize(dir, info.ModTime())
+		if err != nil {
+			return 0, err
+		}
+		for _, name := range entry.subdirs {
+			if !contains(fresh.subdirs, name) {
+				c.forget(filepath.Join(dir, name))
+			}
+		}
+		entry = fresh
+		c.entries[dir] = entry
+	}
+
+	total := entry.files
+	for _, name := range entry.subdirs {
+		size, err := c.size(ctx, filepath.Join(dir, name))
+		if err != nil {
+			return 0, err
+		}
+		total += size
+	}
+	return total, nil
+}
+
+// forget drops the cached entries for dir and everything below it
+func (c *dirSizeCache) forget(dir string) {
+	prefix := dir + string(filepath.Separator)
+	for path := range c.entries {
+		if path == dir || strings.HasPrefix(path, prefix) {
+			delete(c.entries, path)
+		}
+	}
+}
+
+// scanDirSize lists dir, summing its regular files and noting subdirectories
+func scanDirSize(dir string, modTime time.Time) (dirSizeEntry, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return dirSizeEntry{}, err
+	}
+
+	entry := dirSizeEntry{modTime: modTime}
+	for _, e := range entries {
+		switch {
+		case e.IsDir():
+			entry.subdirs = append(entry.subdirs, e.Name())
+		case e.Type().IsRegular():
+			info, err := e.Info()
+			if err != nil {
+				if os.IsNotExist(err) {
+					continue // Removed while scanning
+				}
+				return dirSizeEntry{}, err
+			}
+			entry.files += info.Size()
+		}
+	}
+	return entry, nil
+}
@@ -0,0 +1,180 @@
+// Copyright (C) 2024-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/luxfi/config/spec"
+	"github.com/spf13/pflag"
+)
+
+// ConfigSource identifies the layer a configuration value came from
+type ConfigSource string
+
+const (
+	SourceDefault ConfigSource = "default"
+	SourceFile    ConfigSource = "file"
+	SourceProfile ConfigSource = "profile"
+	SourceEnv     ConfigSource = "env"
+	SourceFlag    ConfigSource = "flag"
+)
+
+// KeySource describes where a single key's final value came from
+type KeySource struct {
+	Key    string       `json:"key"`
+	Value  interface{}  `json:"value"`
+	Source ConfigSource `json:"source"`
+	EnvVar string       `json:"env_var,omitempty"` // Set when Source is SourceEnv
+}
+
+// Explanation is a structured account of how the last Load assembled the
+// configuration, suitable for dumping on --debug-config
+type Explanation struct {
+	// SearchPaths are the directories searched for a config file, in order
+	SearchPaths []string `json:"search_paths"`
+
+	// Files are the config files merged, in the order they were applied
+	Files []string `json:"files"`
+
+	// Profile is the selected profile name, if any
+	Profile string `json:"profile,omitempty"`
+
+	// Keys lists every known key with its final value and source, sorted by key
+	Keys []KeySource `json:"keys"`
+}
+
+// Source returns the layer that supplied the current value of key,
+// following precedence: flag > env > profile > file > default
+func (l *Loader) Source(key string) ConfigSource {
+	return l.source(key, l.flagsByConfigKey())
+}
+
+// source implements Source. flagKeys maps config keys to flag names, as
+// returned by flagsByConfigKey, so callers resolving many keys build it once.
+func (l *Loader) source(key string, flagKeys map[string]string) ConfigSource {
+	key = strings.ToLower(key)
+
+	if l.flagSet != nil {
+		if f := l.lookupFlag(key, flagKeys); f != nil && f.Changed {
+			return SourceFlag
+		}
+	}
+	// Viper ignores empty environment variables
+	if os.Getenv(envVarFor(key)) != "" {
+		return SourceEnv
+	}
+	if l.profileKeys[key] {
+		return SourceProfile
+	}
+	if l.v.InConfig(key) {
+		return SourceFile
+	}
+	return SourceDefault
+}
+
+// flagsByConfigKey maps config keys to the flag names that set them, the
+// inverse of flagConfigKeys. It is nil when no flags are bound.
+func (l *Loader) flagsByConfigKey() map[string]string {
+	if l.flagSet == nil {
+		return nil
+	}
+	flags := make(map[string]string)
+	for flag, configKey := range flagConfigKeys() {
+		flags[configKey] = flag
+	}
+	return flags
+}
+
+// lookupFlag returns the bound flag for key, which is either a flag name or
+// the config key a flag sets (see BindFlags)
+func (l *Loader) lookupFlag(key string, flagKeys map[string]string) *pflag.Flag {
+	if f := l.flagSet.Lookup(key); f != nil {
+		return f
+	}
+	if flag, ok := flagKeys[key]; ok {
+		return l.flagSet.Lookup(flag)
+	}
+	return nil
+}
+
+// Explain reports which config files were merged and the source of every
+// key's value. Call it after Load.
+func (l *Loader) Explain() *Explanation {
+	e := &Explanation{
+		SearchPaths: l.SearchPaths(),
+		Files:       []string{},
+		Profile:     l.Profile(),
+	}
+	if file := l.GetConfigFilePath(); file != "" && !l.notFound {
+		e.Files = append(e.Files, file)
+	}
+	if l.profileFile != "" {
+		e.Files = append(e.Files, l.profileFile)
+	}
+
+	flagKeys := l.flagsByConfigKey()
+	keys := l.v.AllKeys()
+	sort.Strings(keys)
+	for _, key := range keys {
+		ks := KeySource{Key: key, Value: l.Get(key), Source: l.source(key, flagKeys)}
+		if ks.Source == SourceEnv {
+			ks.EnvVar = envVarFor(key)
+		}
+		e.Keys = append(e.Keys, ks)
+	}
+
+	return e
+}
+
+// DeprecatedUsage is a deprecated node flag set by the loaded configuration
+type DeprecatedUsage struct {
+	Key        string       `json:"key"`
+	Source     ConfigSource `json:"source"`
+	EnvVar     string       `json:"env_var,omitempty"` // Set when Source is SourceEnv
+	Message    string       `json:"message,omitempty"`
+	ReplacedBy string       `json:"replaced_by,omitempty"`
+}
+
+// DeprecatedInUse returns the deprecated spec flags explicitly set by the
+// config file, profile, environment, or flags of the last Load, sorted by
+// key. Deprecated flags left at their defaults are not reported.
+func (l *Loader) DeprecatedInUse() ([]DeprecatedUsage, error) {
+	s, err := spec.Spec()
+	if err != nil {
+		return nil, fmt.Errorf("error loading config spec: %w", err)
+	}
+
+	flagKeys := l.flagsByConfigKey()
+	var usages []DeprecatedUsage
+	for _, f := range s.DeprecatedFlags() {
+		source := l.source(f.Key, flagKeys)
+		if source == SourceDefault {
+			continue
+		}
+		u := DeprecatedUsage{
+			Key:        f.Key,
+			Source:     source,
+			Message:    f.DeprecatedMessage,
+			ReplacedBy: f.ReplacedBy,
+		}
+		if source == SourceEnv {
+			u.EnvVar = envVarFor(f.Key)
+		}
+		usages = append(usages, u)
+	}
+
+	sort.Slice(usages, func(i, j int) bool {
+		return usages[i].Key < usages[j].Key
+	})
+	return usages, nil
+}
+
+// envVarFor returns the environment variable viper consults for key
+func envVarFor(key string) string {
+	return strings.NewReplacer("-", "_", ".", "_").Replace(strings.ToUpper(EnvPrefix + "_" + key))
+}
@@ -34,6 +34,13 @@ const (
 	StakingPortKey = "staking-port"
 	DBTypeKey      = "db-type"
 
+	// Telemetry
+	MetricsEnabledKey    = "metrics-enabled"
+	MetricsPortKey       = "metrics-port"
+	TracingEnabledKey    = "tracing-enabled"
+	TracingEndpointKey   = "tracing-endpoint"
+	TracingSampleRateKey = "tracing-sample-rate"
+
 	// Config file
 	ConfigFileKey = "config-file"
 )
@@ -47,7 +54,7 @@ func AddGlobalFlags(fs *pflag.FlagSet) {
 
 	// Logging
 	fs.String(LogLevelKey, "info", "Log level (verbo, debug, trace, info, warn, error, fatal, off)")
-	fs.String(LogFormatKey, "terminal", "Log format (terminal, json, plain)")
+	fs.String(LogFormatKey, "terminal", "Log format (terminal, json, plain, logfmt)")
 	fs.String(LogDirKey, "", "Log directory (default: $DATA_DIR/logs)")
 
 	// Config file
@@ -78,12 +85,23 @@ func AddNodeFlags(fs *pflag.FlagSet) {
 	fs.String(DBTypeKey, "badgerdb", "Database type (badgerdb, leveldb, pebbledb, memdb)")
 }
 
+// AddTelemetryFlags adds metrics and tracing flags
+func AddTelemetryFlags(fs *pflag.FlagSet) {
+	telemetry := defaultTelemetry()
+	fs.Bool(MetricsEnabledKey, telemetry.MetricsEnabled, "Expose the metrics API")
+	fs.Int(MetricsPortKey, telemetry.MetricsPort, "Metrics port (0 = serve on the HTTP port)")
+	fs.Bool(TracingEnabledKey, telemetry.TracingEnabled, "Enable OpenTelemetry tracing")
+	fs.String(TracingEndpointKey, telemetry.TracingEndpoint, "Endpoint to send trace data to")
+	fs.Float64(TracingSampleRateKey, telemetry.SampleRate, "Fraction of traces to sample (0 to 1)")
+}
+
 // AddAllFlags adds all available flags
 func AddAllFlags(fs *pflag.FlagSet) {
 	AddGlobalFlags(fs)
 	AddLogFlags(fs)
 	AddNetworkFlags(fs)
 	AddNodeFlags(fs)
+	AddTelemetryFlags(fs)
 }
 
 // FlagDescription provides descriptions for flags
@@ -91,7 +109,7 @@ var FlagDescriptions = map[string]string{
 	DataDirKey:            "Base directory for all Lux data including plugins, logs, database, and configuration files",
 	PluginDirKey:          "Directory containing VM plugin binaries. Defaults to $DATA_DIR/plugins if not specified",
 	LogLevelKey:           "Minimum log level to output. Available levels: verbo, debug, trace, info, warn, error, fatal, off",
-	LogFormatKey:          "Output format for logs. 'terminal' for colored output, 'json' for structured logs, 'plain' for uncolored text",
+	LogFormatKey:          "Output format for logs. 'terminal' for colored output, 'json' for structured logs, 'plain' for uncolored text, 'logfmt' for key=value lines",
 	LogDirKey:             "Directory where log files are written. Defaults to $DATA_DIR/logs if not specified",
 	LogMaxSizeKey:         "Maximum size of a single log file in megabytes before rotation",
 	LogMaxFilesKey:        "Maximum number of old log files to retain after rotation",
@@ -105,6 +123,11 @@ var FlagDescriptions = map[string]string{
 	HTTPPortKey:           "Port for HTTP API server",
 	StakingPortKey:        "Port for staking and P2P connections",
 	DBTypeKey:             "Database backend type. Options: badgerdb (default), leveldb, pebbledb, memdb",
+	MetricsEnabledKey:     "Whether to expose the Prometheus metrics API",
+	MetricsPortKey:        "Port for the metrics API. 0 serves metrics on the HTTP API port",
+	TracingEnabledKey:     "Whether to export OpenTelemetry traces",
+	TracingEndpointKey:    "Endpoint to send trace data to. Empty uses the exporter's default endpoint",
+	TracingSampleRateKey:  "Fraction of traces to sample, from 0 (never) to 1 (always)",
 	ConfigFileKey:         "Path to configuration file. Supports JSON, YAML, and TOML formats",
 }
 
@@ -0,0 +1,282 @@
+// Copyright (C) 2021-2025, Lux Industries Inc. All rights reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package config
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// FsckSeverity is how serious a FsckFinding is
+type FsckSeverity string
+
+const (
+	FsckError   FsckSeverity = "error"
+	FsckWarning FsckSeverity = "warning"
+)
+
+// FsckFinding is a single problem found by FsckTree
+type FsckFinding struct {
+	Severity  FsckSeverity `json:"severity"`
+	Subsystem string       `json:"subsystem"` // chains, plugins, keys, runs, or symlinks
+	Path      string       `json:"path,omitempty"`
+	Message   string       `json:"message"`
+}
+
+// FsckTree checks the consistency of the whole data tree under
+// paths.BaseDir: chain genesis and config files, the plugin registry
+// against the packages on disk, node key permissions, run directories, and
+// dangling symlinks. Findings are grouped by subsystem in that order. The
+// error is only set when a directory cannot be read at all; a missing
+// subsystem directory is not a finding. Used by "lux fsck".
+func FsckTree(paths *Paths) ([]FsckFinding, error) {
+	if paths == nil {
+		return nil, fmt.Errorf("paths is required")
+	}
+
+	var findings []FsckFinding
+	for _, check := range []func(*Paths) ([]FsckFinding, error){
+		fsckChains, fsckPlugins, fsckKeys, fsckRuns, fsckSymlinks,
+	} {
+		found, err := check(paths)
+		if err != nil {
+			return findings, err
+		}
+		findings = append(findings, found...)
+	}
+	return findings, nil
+}
+
+// FsckFailed reports whether any finding is an error
+func FsckFailed(findings []FsckFinding) bool {
+	for _, f := range findings {
+		if f.Severity == FsckError {
+			return true
+		}
+	}
+	return false
+}
+
+// readDirIfExists is os.ReadDir, treating a missing directory as empty
+func readDirIfExists(dir string) ([]os.DirEntry, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+	return entries, nil
+}
+
+// fsckChains validates each chain's genesis and config
+func fsckChains(paths *Paths) ([]FsckFinding, error) {
+	entries, err := readDirIfExists(paths.ChainsBaseDir())
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []FsckFinding
+	add := func(severity FsckSeverity, path, format string, args ...interface{}) {
+		findings = append(findings, FsckFinding{Severity: severity, Subsystem: "chains", Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+
+		genesisPath := paths.ChainGenesis(name)
+		genesis, err := os.ReadFile(genesisPath)
+		switch {
+		case os.IsNotExist(err):
+			add(FsckWarning, paths.ChainDir(name), "chain directory has no %s", GenesisFile)
+		case err != nil:
+			add(FsckError, genesisPath, "failed to read genesis: %v", err)
+		default:
+			if err := ValidateGenesis(genesis); err != nil {
+				add(FsckError, genesisPath, "%v", err)
+			}
+		}
+
+		configPath := paths.ChainConfig(name)
+		if data, err := os.ReadFile(configPath); err == nil {
+			if err := ValidateChainConfig(data); err != nil {
+				add(FsckError, configPath, "%v", err)
+			}
+		} else if !os.IsNotExist(err) {
+			add(FsckError, configPath, "failed to read chain config: %v", err)
+		}
+	}
+	return findings, nil
+}
+
+// fsckPlugins verifies every registered package and reports VMIDs claimed
+// by more than one package. Trees without a plugin registry are skipped.
+func fsckPlugins(paths *Paths) ([]FsckFinding, error) {
+	baseDir := paths.PluginsBaseDir()
+	registryPath := filepath.Join(baseDir, registryFile)
+	if !Exists(registryPath) {
+		return nil, nil
+	}
+
+	pm, err := NewPluginPackageManager(baseDir)
+	if err != nil {
+		return []FsckFinding{{Severity: FsckError, Subsystem: "plugins", Path: registryPath, Message: err.Error()}}, nil
+	}
+
+	ctx := context.Background()
+	results, err := pm.VerifyAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var findings []FsckFinding
+	for _, r := range results {
+		if !r.OK {
+			findings = append(findings, FsckFinding{
+				Severity:  FsckError,
+				Subsystem: "plugins",
+				Path:      pm.PackagePath(r.Org, r.Name, r.Version),
+				Message:   fmt.Sprintf("%s/%s@%s: %s", r.Org, r.Name, r.Version, r.Reason),
+			})
+		}
+	}
+
+	conflicts, err := pm.CheckVMIDConflicts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range conflicts {
+		findings = append(findings, FsckFinding{
+			Severity:  FsckError,
+			Subsystem: "plugins",
+			Path:      pm.ActivePath(c.VMID),
+			Message:   fmt.Sprintf("vmid %s is declared by %v", c.VMID, c.Packages),
+		})
+	}
+	return findings, nil
+}
+
+// fsckKeys checks that node key directories and private key files are no
+// more permissive than the permission policy. Skipped on Windows, where
+// Unix permission bits are not meaningful.
+func fsckKeys(paths *Paths) ([]FsckFinding, error) {
+	if runtime.GOOS == "windows" {
+		return nil, nil
+	}
+	policy := paths.Policy()
+
+	networks, err := readDirIfExists(paths.KeysBaseDir())
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []FsckFinding
+	check := func(path string, severity FsckSeverity, mode os.FileMode) {
+		info, err := os.Lstat(path)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				findings = append(findings, FsckFinding{Severity: FsckError, Subsystem: "keys", Path: path, Message: err.Error()})
+			}
+			return
+		}
+		if perm := info.Mode().Perm(); perm&^mode != 0 {
+			findings = append(findings, FsckFinding{
+				Severity:  severity,
+				Subsystem: "keys",
+				Path:      path,
+				Message:   fmt.Sprintf("mode %04o is more permissive than %04o", perm, mode),
+			})
+		}
+	}
+	for _, network := range networks {
+		if !network.IsDir() {
+			continue
+		}
+		nodes, err := readDirIfExists(paths.NetworkKeysDir(network.Name()))
+		if err != nil {
+			return nil, err
+		}
+		for _, node := range nodes {
+			if !node.IsDir() {
+				continue
+			}
+			check(paths.NodeKeysDir(network.Name(), node.Name()), FsckWarning, policy.KeyDirMode)
+			check(paths.NodeStakingKey(network.Name(), node.Name()), FsckError, policy.KeyFileMode)
+			check(paths.NodeSignerKey(network.Name(), node.Name()), FsckError, policy.KeyFileMode)
+		}
+	}
+	return findings, nil
+}
+
+// fsckRuns reports runs without node directories and stale run locks
+func fsckRuns(paths *Paths) ([]FsckFinding, error) {
+	networks, err := readDirIfExists(paths.NetworksBaseDir())
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []FsckFinding
+	for _, network := range networks {
+		if !network.IsDir() {
+			continue
+		}
+		runs, err := readDirIfExists(paths.NetworkRunsDir(network.Name()))
+		if err != nil {
+			return nil, err
+		}
+		for _, run := range runs {
+			if !run.IsDir() {
+				continue
+			}
+			runDir := paths.NetworkRunDir(network.Name(), run.Name())
+
+			nodes, err := paths.ListNodes(network.Name(), run.Name())
+			if err != nil {
+				return nil, err
+			}
+			if len(nodes) == 0 {
+				findings = append(findings, FsckFinding{Severity: FsckWarning, Subsystem: "runs", Path: runDir, Message: "run has no node directories"})
+			}
+
+			lockPath := paths.RunLockPath(network.Name(), run.Name())
+			if Exists(lockPath) && !lockHeld(lockPath) {
+				findings = append(findings, FsckFinding{Severity: FsckWarning, Subsystem: "runs", Path: lockPath, Message: "stale run lock"})
+			}
+		}
+	}
+	return findings, nil
+}
+
+// fsckSymlinks reports symlinks anywhere in the tree whose target is
+// missing or that form a loop
+func fsckSymlinks(paths *Paths) ([]FsckFinding, error) {
+	var findings []FsckFinding
+	err := filepath.WalkDir(paths.BaseDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == paths.BaseDir {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if d.Type()&fs.ModeSymlink == 0 {
+			return nil
+		}
+
+		target, err := ResolveSymlink(path)
+		if err != nil {
+			findings = append(findings, FsckFinding{Severity: FsckError, Subsystem: "symlinks", Path: path, Message: err.Error()})
+			return nil
+		}
+		if _, err := os.Stat(target); os.IsNotExist(err) {
+			findings = append(findings, FsckFinding{Severity: FsckError, Subsystem: "symlinks", Path: path, Message: "dangling symlink to " + target})
+		}
+		return nil
+	})
+	if err != nil {
+		return findings, fmt.Errorf("failed to walk %s: %w", paths.BaseDir, err)
+	}
+	return findings, nil
+}
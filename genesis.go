@@ -0,0 +1,211 @@
+// Copyright (C) 2021-2025, Lux Industries Inc. All rights reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package config
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// GenesisAccount is an account entry in an EVM genesis alloc
+type GenesisAccount struct {
+	Balance *big.Int          // Balance in wei
+	Code    []byte            // Contract bytecode, if any
+	Storage map[string]string // Storage slot to value, as hex strings
+
+	// extra holds fields other than balance, code, and storage (e.g. nonce)
+	// so they survive a SetGenesisAlloc round trip
+	extra map[string]json.RawMessage
+}
+
+// GenesisAlloc parses the alloc section of an EVM genesis, keyed by address.
+// Balances may be hex (0x-prefixed) or decimal strings.
+func GenesisAlloc(genesis []byte) (map[string]GenesisAccount, error) {
+	var g struct {
+		Alloc map[string]map[string]json.RawMessage `json:"alloc"`
+	}
+	if err := json.Unmarshal(genesis, &g); err != nil {
+		return nil, fmt.Errorf("failed to parse genesis: %w", err)
+	}
+
+	alloc := make(map[string]GenesisAccount, len(g.Alloc))
+	for addr, fields := range g.Alloc {
+		if err := validateHex(addr, 20); err != nil {
+			return nil, fmt.Errorf("invalid alloc address %q: %w", addr, err)
+		}
+		account, err := parseGenesisAccount(fields)
+		if err != nil {
+			return nil, fmt.Errorf("invalid alloc entry %s: %w", addr, err)
+		}
+		alloc[addr] = account
+	}
+	return alloc, nil
+}
+
+// ValidateGenesis checks that genesis is a JSON object and that its alloc
+// section, if any, parses
+func ValidateGenesis(genesis []byte) error {
+	var g map[string]json.RawMessage
+	if err := json.Unmarshal(genesis, &g); err != nil {
+		return fmt.Errorf("genesis must be a JSON object: %w", err)
+	}
+	if g == nil {
+		return fmt.Errorf("genesis must be a JSON object")
+	}
+	_, err := GenesisAlloc(genesis)
+	return err
+}
+
+// SetGenesisAlloc returns genesis with its alloc section replaced by alloc.
+// All other genesis fields are kept as they were.
+func SetGenesisAlloc(genesis []byte, alloc map[string]GenesisAccount) ([]byte, error) {
+	var g map[string]json.RawMessage
+	if err := json.Unmarshal(genesis, &g); err != nil {
+		return nil, fmt.Errorf("failed to parse genesis: %w", err)
+	}
+
+	entries := make(map[string]map[string]json.RawMessage, len(alloc))
+	for addr, account := range alloc {
+		if err := validateHex(addr, 20); err != nil {
+			return nil, fmt.Errorf("invalid alloc address %q: %w", addr, err)
+		}
+		fields, err := account.fields()
+		if err != nil {
+			return nil, fmt.Errorf("invalid alloc entry %s: %w", addr, err)
+		}
+		entries[addr] = fields
+	}
+
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal alloc: %w", err)
+	}
+	g["alloc"] = data
+
+	out, err := json.MarshalIndent(g, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal genesis: %w", err)
+	}
+	return out, nil
+}
+
+// parseGenesisAccount decodes the fields of one alloc entry
+func parseGenesisAccount(fields map[string]json.RawMessage) (GenesisAccount, error) {
+	var account GenesisAccount
+	for key, raw := range fields {
+		switch key {
+		case "balance":
+			var s string
+			if err := json.Unmarshal(raw, &s); err != nil {
+				return account, fmt.Errorf("balance must be a string: %w", err)
+			}
+			balance, err := parseBalance(s)
+			if err != nil {
+				return account, err
+			}
+			account.Balance = balance
+		case "code":
+			var s string
+			if err := json.Unmarshal(raw, &s); err != nil {
+				return account, fmt.Errorf("code must be a string: %w", err)
+			}
+			code, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+			if err != nil {
+				return account, fmt.Errorf("invalid code: %w", err)
+			}
+			account.Code = code
+		case "storage":
+			if err := json.Unmarshal(raw, &account.Storage); err != nil {
+				return account, fmt.Errorf("storage must map strings to strings: %w", err)
+			}
+			for slot, value := range account.Storage {
+				if err := validateHex(slot, 32); err != nil {
+					return account, fmt.Errorf("invalid storage slot %q: %w", slot, err)
+				}
+				if err := validateHex(value, 32); err != nil {
+					return account, fmt.Errorf("invalid storage value for slot %s: %w", slot, err)
+				}
+			}
+		default:
+			if account.extra == nil {
+				account.extra = make(map[string]json.RawMessage)
+			}
+			account.extra[key] = raw
+		}
+	}
+	if account.Balance == nil {
+		return account, fmt.Errorf("missing balance")
+	}
+	return account, nil
+}
+
+// fields encodes the account as alloc entry fields
+func (a GenesisAccount) fields() (map[string]json.RawMessage, error) {
+	if a.Balance == nil || a.Balance.Sign() < 0 {
+		return nil, fmt.Errorf("balance must be set and non-negative")
+	}
+
+	fields := make(map[string]json.RawMessage, len(a.extra)+3)
+	for key, raw := range a.extra {
+		fields[key] = raw
+	}
+	set := func(key string, v any) error {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		fields[key] = data
+		return nil
+	}
+
+	if err := set("balance", "0x"+a.Balance.Text(16)); err != nil {
+		return nil, err
+	}
+	if len(a.Code) > 0 {
+		if err := set("code", "0x"+hex.EncodeToString(a.Code)); err != nil {
+			return nil, err
+		}
+	}
+	if len(a.Storage) > 0 {
+		if err := set("storage", a.Storage); err != nil {
+			return nil, err
+		}
+	}
+	return fields, nil
+}
+
+// parseBalance parses a hex (0x-prefixed) or decimal balance
+func parseBalance(s string) (*big.Int, error) {
+	balance, ok := new(big.Int), false
+	if hexDigits, isHex := strings.CutPrefix(strings.ToLower(s), "0x"); isHex {
+		balance, ok = balance.SetString(hexDigits, 16)
+	} else {
+		balance, ok = balance.SetString(s, 10)
+	}
+	if !ok || balance.Sign() < 0 {
+		return nil, fmt.Errorf("invalid balance %q", s)
+	}
+	return balance, nil
+}
+
+// validateHex checks that s is an optionally 0x-prefixed hex string of at
+// most maxBytes bytes
+func validateHex(s string, maxBytes int) error {
+	digits := strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X")
+	if digits == "" {
+		return fmt.Errorf("empty hex string")
+	}
+	if len(digits) > maxBytes*2 {
+		return fmt.Errorf("longer than %d bytes", maxBytes)
+	}
+	for _, c := range digits {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", c) {
+			return fmt.Errorf("invalid hex character %q", c)
+		}
+	}
+	return nil
+}
@@ -4,9 +4,11 @@ go 1.25.5
 
 require (
 	github.com/btcsuite/btcutil v1.0.2
+	github.com/klauspost/compress v1.18.0
 	github.com/spf13/pflag v1.0.10
 	github.com/spf13/viper v1.21.0
 	go.uber.org/zap v1.27.1
+	golang.org/x/crypto v0.47.0
 	gopkg.in/natefinch/lumberjack.v2 v2.2.1
 )
 
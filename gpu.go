@@ -92,8 +92,18 @@ var (
 	globalGPUConfig     GPUConfig
 	globalGPUConfigOnce sync.Once
 	globalGPUConfigSet  bool
+	globalGPUMu         sync.RWMutex
+
+	gpuReloadHandlers    []gpuReloadHandler
+	nextGPUReloadHandler int
 )
 
+// gpuReloadHandler is a handler registered with RegisterGPUReloadHandler
+type gpuReloadHandler struct {
+	id int
+	fn func(GPUConfig)
+}
+
 // SetGlobalGPUConfig sets the global GPU configuration.
 // This should be called once during node initialization before any GPU accelerators are created.
 func SetGlobalGPUConfig(cfg GPUConfig) error {
@@ -103,15 +113,76 @@ func SetGlobalGPUConfig(cfg GPUConfig) error {
 			setErr = err
 			return
 		}
+		globalGPUMu.Lock()
 		globalGPUConfig = cfg
 		globalGPUConfigSet = true
+		globalGPUMu.Unlock()
 	})
 	return setErr
 }
 
+// ForceSetGlobalGPUConfig replaces the global GPU configuration even if it was
+// already set. It is safe to call on config reload: registered reload handlers
+// are invoked only when the new configuration differs from the previous one.
+func ForceSetGlobalGPUConfig(cfg GPUConfig) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	// Mark the once as done so a later SetGlobalGPUConfig cannot clobber this
+	globalGPUConfigOnce.Do(func() {})
+
+	globalGPUMu.Lock()
+	prev := globalGPUConfig
+	if !globalGPUConfigSet {
+		prev = DefaultGPUConfig()
+	}
+	globalGPUConfig = cfg
+	globalGPUConfigSet = true
+	handlers := make([]func(GPUConfig), 0, len(gpuReloadHandlers))
+	for _, h := range gpuReloadHandlers {
+		handlers = append(handlers, h.fn)
+	}
+	globalGPUMu.Unlock()
+
+	if prev == cfg {
+		return nil
+	}
+	for _, h := range handlers {
+		h(cfg)
+	}
+	return nil
+}
+
+// RegisterGPUReloadHandler registers a handler invoked with the new GPU
+// configuration whenever ForceSetGlobalGPUConfig changes it.
+// Accelerators that can be retuned at runtime should register here.
+// The returned function removes the handler.
+func RegisterGPUReloadHandler(h func(GPUConfig)) func() {
+	globalGPUMu.Lock()
+	defer globalGPUMu.Unlock()
+
+	id := nextGPUReloadHandler
+	nextGPUReloadHandler++
+	gpuReloadHandlers = append(gpuReloadHandlers, gpuReloadHandler{id: id, fn: h})
+
+	return func() {
+		globalGPUMu.Lock()
+		defer globalGPUMu.Unlock()
+		for i, handler := range gpuReloadHandlers {
+			if handler.id == id {
+				gpuReloadHandlers = append(gpuReloadHandlers[:i:i], gpuReloadHandlers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
 // GetGlobalGPUConfig returns the global GPU configuration.
 // If not set, returns the default configuration.
 func GetGlobalGPUConfig() GPUConfig {
+	globalGPUMu.RLock()
+	defer globalGPUMu.RUnlock()
 	if !globalGPUConfigSet {
 		return DefaultGPUConfig()
 	}
@@ -5,13 +5,19 @@ package config
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"reflect"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
+
+	"github.com/luxfi/config/spec"
 )
 
 const (
@@ -23,21 +29,43 @@ const (
 
 	// DefaultDataDir is the default data directory
 	DefaultDataDir = "~/.lux"
-)
 
-var (
-	// globalConfig is the singleton configuration instance
-	globalConfig *LuxConfig
-	configOnce   sync.Once
-	configMutex  sync.RWMutex
+	// ProfileEnvVar selects a config profile when WithProfile is not used
+	ProfileEnvVar = "LUX_PROFILE"
 )
 
+// configExts are the config file extensions recognized for profiles
+var configExts = []string{"json", "yaml", "yml", "toml"}
+
+// configOnce guards the lazy load performed by Global
+var configOnce sync.Once
+
+// globalLoadErr is the error from the lazy load, if it failed
+var globalLoadErr error
+
+// globalFallback is the DefaultConfig stored after a failed lenient lazy
+// load; GlobalE reports globalLoadErr while it is still the global config
+var globalFallback *LuxConfig
+
+// strictGlobal makes a failed lazy load fatal instead of using defaults
+var strictGlobal atomic.Bool
+
 // Loader handles configuration loading from all sources
 type Loader struct {
 	v           *viper.Viper
 	flagSet     *pflag.FlagSet
 	configPaths []string
-	configFile  string // Explicit config file path
+	configFile  string          // Explicit config file path
+	packedFile  string          // Compressed config file read by the last Load
+	notFound    bool            // Set by Load when no config file was found
+	profile     string          // Named profile merged over the base config
+	profileFile string          // Profile config file that was merged
+	profileKeys map[string]bool // Keys set by the merged profile
+	shadowed    []string        // Config files found but not used by the last Load
+	relPaths    bool            // Resolve relative file paths against the file's directory
+	detectDB    bool            // Default db-type to DetectPreferredDBType
+	warnings    []string
+	coerceErrs  []spec.Violation // Values the last Load could not coerce
 }
 
 // LoaderOption is a functional option for the Loader
@@ -57,15 +85,37 @@ func WithConfigPaths(paths ...string) LoaderOption {
 	}
 }
 
+// WithProfile selects a named config profile. The profile file
+// config.<name>.{json,yaml,yml,toml} is merged over the base config.
+func WithProfile(name string) LoaderOption {
+	return func(l *Loader) {
+		l.profile = name
+	}
+}
+
+// ResolvePathsRelativeToConfig resolves relative path values (plugin-dir,
+// log directory, and other *-dir and *-file keys) set in a config or
+// profile file against that file's directory instead of the working
+// directory. Absolute and ~ paths, and paths from env or flags, are
+// unaffected.
+func ResolvePathsRelativeToConfig() LoaderOption {
+	return func(l *Loader) {
+		l.relPaths = true
+	}
+}
+
+// WithDetectedDBType defaults db-type to DetectPreferredDBType instead of
+// badgerdb. An explicitly set db-type is unaffected.
+func WithDetectedDBType() LoaderOption {
+	return func(l *Loader) {
+		l.detectDB = true
+	}
+}
+
 // NewLoader creates a new configuration loader
 func NewLoader(opts ...LoaderOption) *Loader {
-	v := viper.New()
-	v.SetEnvPrefix(EnvPrefix)
-	v.SetEnvKeyReplacer(strings.NewReplacer("-", "_", ".", "_"))
-	v.AutomaticEnv()
-
 	l := &Loader{
-		v:           v,
+		v:           newViper(),
 		configPaths: defaultConfigPaths(),
 	}
 
@@ -76,6 +126,15 @@ func NewLoader(opts ...LoaderOption) *Loader {
 	return l
 }
 
+// newViper returns a viper instance that reads LUX_-prefixed env vars
+func newViper() *viper.Viper {
+	v := viper.New()
+	v.SetEnvPrefix(EnvPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer("-", "_", ".", "_"))
+	v.AutomaticEnv()
+	return v
+}
+
 // defaultConfigPaths returns the default configuration search paths
 func defaultConfigPaths() []string {
 	paths := []string{}
@@ -100,38 +159,203 @@ func defaultConfigPaths() []string {
 	return paths
 }
 
-// BindFlags binds CLI flags to the configuration
+// BindFlags binds CLI flags to the configuration. Each flag is bound under
+// its own name and, for flags such as --log-level that set a nested config
+// key, under that key too. Only flags set on the command line override the
+// config file and env; unset flags never replace a configured value with
+// their default.
 func (l *Loader) BindFlags(fs *pflag.FlagSet) error {
 	l.flagSet = fs
-	return l.v.BindPFlags(fs)
+	return bindFlags(l.v, fs)
+}
+
+// bindFlags binds fs to v as described in BindFlags
+func bindFlags(v *viper.Viper, fs *pflag.FlagSet) error {
+	if err := v.BindPFlags(fs); err != nil {
+		return err
+	}
+
+	keys := flagConfigKeys()
+	var err error
+	fs.VisitAll(func(f *pflag.Flag) {
+		if key, ok := keys[f.Name]; ok && key != f.Name && err == nil {
+			err = v.BindPFlag(key, f)
+		}
+	})
+	return err
+}
+
+// flagConfigKeys maps flag names to the config keys they set
+func flagConfigKeys() map[string]string {
+	keys := make(map[string]string)
+	for _, e := range sampleEntries(DefaultConfig()) {
+		if e.section == "" {
+			keys[e.flag] = e.key
+		} else {
+			keys[e.flag] = e.section + "." + e.key
+		}
+	}
+	return keys
+}
+
+// BindAndValidate binds fs, loads the configuration, and validates every
+// flag known to the node spec against its constraints. Constraint violations
+// are returned alongside the typed config; err is only set when binding or
+// loading fails.
+func (l *Loader) BindAndValidate(fs *pflag.FlagSet) (*LuxConfig, []spec.Violation, error) {
+	if err := l.BindFlags(fs); err != nil {
+		return nil, nil, fmt.Errorf("error binding flags: %w", err)
+	}
+
+	cfg, err := l.Load()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s, err := spec.Spec()
+	if err != nil {
+		return nil, nil, fmt.Errorf("error loading config spec: %w", err)
+	}
+
+	values := make(map[string]interface{})
+	fs.VisitAll(func(f *pflag.Flag) {
+		if s.KnownKey(f.Name) {
+			values[f.Name] = l.Get(f.Name)
+		}
+	})
+
+	return cfg, s.Validate(values), nil
 }
 
 // Load loads configuration from all sources following precedence:
 // CLI Flags > Environment Variables > Config File > Defaults
+//
+// The search paths are also checked for gzip (.gz) and zstd (.zst)
+// compressed config files such as config.json.gz; an uncompressed file in
+// the same directory takes precedence.
 func (l *Loader) Load() (*LuxConfig, error) {
+	// Start from a fresh viper so nothing from an earlier Load leaks in
+	if err := l.resetViper(); err != nil {
+		return nil, err
+	}
+
 	// Set defaults first
 	l.setDefaults()
 
-	// Configure viper for config file
+	// Configure viper for config file (type is inferred from the extension)
 	l.v.SetConfigName(ConfigFileName)
-	l.v.SetConfigType("json") // Default type, viper auto-detects yaml/toml
 
 	// Add search paths
-	for _, path := range l.configPaths {
-		l.v.AddConfigPath(expandPath(path))
+	for _, path := range l.SearchPaths() {
+		l.v.AddConfigPath(path)
 	}
 
-	// Use explicit config file if set
+	// Use explicit config file if set; compressed files are decoded here
+	// since viper cannot read them
+	l.packedFile = ""
 	if l.configFile != "" {
-		l.v.SetConfigFile(expandPath(l.configFile))
+		if path := expandPath(l.configFile); isCompressedConfig(path) {
+			l.packedFile = path
+		} else {
+			l.v.SetConfigFile(path)
+		}
+	} else {
+		l.packedFile = l.findPackedConfig()
 	}
 
 	// Try to read config file (optional - missing file is OK)
-	if err := l.v.ReadInConfig(); err != nil {
+	l.notFound = false
+	l.warnings = nil
+	if l.packedFile != "" {
+		if err := l.readCompressedConfig(l.packedFile); err != nil {
+			return nil, err
+		}
+	} else if err := l.v.ReadInConfig(); err != nil {
 		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
 			// Only return error if it's not a "file not found" error
 			return nil, fmt.Errorf("error reading config file: %w", err)
 		}
+		l.notFound = true
+	}
+
+	// Record config files shadowed by the one that was used
+	l.shadowed = nil
+	if l.configFile == "" && !l.notFound {
+		used := l.GetConfigFilePath()
+		l.shadowed = l.shadowedConfigFiles(used)
+		for _, path := range l.shadowed {
+			l.warnings = append(l.warnings, fmt.Sprintf("config file %s is ignored because %s takes precedence", path, used))
+		}
+	}
+
+	// Merge the selected profile over the base config
+	l.profileFile = ""
+	l.profileKeys = nil
+	if profile := l.Profile(); profile != "" {
+		if err := l.mergeProfile(profile); err != nil {
+			return nil, err
+		}
+	}
+
+	return l.finishLoad()
+}
+
+// LoadFrom loads configuration from r instead of searching for a config
+// file. format is the content type (json, yaml, yml, or toml). Defaults,
+// environment variables, and bound flags apply as with Load; profiles do
+// not, since they are located on disk. Nothing read by an earlier Load on
+// the same Loader carries over.
+func (l *Loader) LoadFrom(r io.Reader, format string) (*LuxConfig, error) {
+	format = strings.ToLower(strings.TrimPrefix(format, "."))
+	if !contains(configExts, format) {
+		return nil, fmt.Errorf("unsupported config format %q: must be one of %s", format, strings.Join(configExts, ", "))
+	}
+
+	// Start from a fresh viper so nothing read by an earlier Load leaks in
+	if err := l.resetViper(); err != nil {
+		return nil, err
+	}
+	l.setDefaults()
+
+	l.notFound = false
+	l.warnings = nil
+	l.packedFile = ""
+	l.profileFile = ""
+	l.profileKeys = nil
+	l.shadowed = nil
+
+	// Parse with a separate viper so the format doesn't stick to later Loads
+	rv := viper.New()
+	rv.SetConfigType(format)
+	if err := rv.ReadConfig(r); err != nil {
+		return nil, fmt.Errorf("error reading config: %w", err)
+	}
+	if err := l.v.MergeConfigMap(rv.AllSettings()); err != nil {
+		return nil, fmt.Errorf("error merging config: %w", err)
+	}
+
+	return l.finishLoad()
+}
+
+// resetViper replaces the loader's viper with a fresh one, rebinding the
+// flags passed to BindFlags
+func (l *Loader) resetViper() error {
+	v := newViper()
+	if l.flagSet != nil {
+		if err := bindFlags(v, l.flagSet); err != nil {
+			return fmt.Errorf("error binding flags: %w", err)
+		}
+	}
+	l.v = v
+	return nil
+}
+
+// finishLoad coerces, unmarshals, normalizes, and validates the merged
+// configuration once all sources have been read
+func (l *Loader) finishLoad() (*LuxConfig, error) {
+	// Coerce string values of spec flags into their declared types
+	if err := l.coerceSpecValues(); err != nil {
+		return nil, err
 	}
 
 	// Unmarshal into struct
@@ -140,19 +364,92 @@ func (l *Loader) Load() (*LuxConfig, error) {
 		return nil, fmt.Errorf("error unmarshaling config: %w", err)
 	}
 
-	// Expand paths
-	cfg.DataDir = expandPath(cfg.DataDir)
-	cfg.PluginDir = expandPath(cfg.PluginDir)
-	cfg.Log.Directory = expandPath(cfg.Log.Directory)
+	// Expand env vars in all string values, and ~ in paths
+	expandConfig(&cfg)
+	if l.relPaths {
+		l.resolveRelativePaths(reflect.ValueOf(&cfg).Elem(), "")
+	}
+
+	// Canonicalize known network names so paths don't split on case
+	if name, folded := NormalizeNetworkName(cfg.Network.Name); folded {
+		l.warnings = append(l.warnings, fmt.Sprintf("network name %q normalized to %q", cfg.Network.Name, name))
+		cfg.Network.Name = name
+	}
 
 	// Validate configuration
 	if err := cfg.Validate(); err != nil {
 		return nil, fmt.Errorf("config validation failed: %w", err)
 	}
+	if err := runConfigValidators(&cfg); err != nil {
+		return nil, fmt.Errorf("config validation failed: %w", err)
+	}
 
 	return &cfg, nil
 }
 
+// coerceSpecValues converts string values of keys typed by the node spec
+// (typically from env vars and flags) into the spec's declared types and
+// writes them back, so both Unmarshal and Get see the typed values. A config
+// key set by a spec flag (see flagConfigKeys) takes that flag's type. Values
+// that cannot be coerced are left as they are and reported per key by
+// CoercionErrors and Warnings.
+func (l *Loader) coerceSpecValues() error {
+	s, err := spec.Spec()
+	if err != nil {
+		return fmt.Errorf("error loading config spec: %w", err)
+	}
+
+	specFlags := make(map[string]string)
+	for flag, key := range flagConfigKeys() {
+		if s.KnownKey(flag) {
+			specFlags[key] = flag
+		}
+	}
+
+	l.coerceErrs = nil
+	keys := l.v.AllKeys()
+	sort.Strings(keys)
+	for _, key := range keys {
+		flag := key
+		if !s.KnownKey(key) {
+			var ok bool
+			if flag, ok = specFlags[key]; !ok {
+				continue
+			}
+		}
+
+		value, ok := l.v.Get(key).(string)
+		if !ok {
+			continue
+		}
+		f := s.GetFlag(flag)
+		coerced, err := f.Coerce(value)
+		if err != nil {
+			v := spec.Violation{Key: key, Value: value, Message: fmt.Sprintf("expected %s: %v", f.Type, err)}
+			l.coerceErrs = append(l.coerceErrs, v)
+			l.warnings = append(l.warnings, "invalid config value: "+v.Error())
+			continue
+		}
+		if _, still := coerced.(string); !still {
+			l.v.Set(key, coerced)
+		}
+	}
+	return nil
+}
+
+// CoercionErrors returns the values of the last Load that could not be
+// converted to their spec type, sorted by key. Such values are passed
+// through unchanged.
+func (l *Loader) CoercionErrors() []spec.Violation {
+	return l.coerceErrs
+}
+
+// Get returns the loaded value for a key. Keys known to the node spec are
+// returned as their spec type (e.g. time.Duration for duration flags).
+func (l *Loader) Get(key string) interface{} {
+	return l.v.Get(key)
+}
+
 // setDefaults sets default values for all configuration options
 func (l *Loader) setDefaults() {
 	// Get the data directory (may be set via env or flag)
@@ -176,6 +473,10 @@ func (l *Loader) setDefaults() {
 	l.v.SetDefault("log.compress", false) // Don't compress by default
 	l.v.SetDefault("log.show-caller", false)
 	l.v.SetDefault("log.show-colors", true)
+	l.v.SetDefault("log.time-key", "")
+	l.v.SetDefault("log.level-key", "")
+	l.v.SetDefault("log.message-key", "")
+	l.v.SetDefault("log.time-layout", "")
 
 	// Network defaults (mainnet)
 	l.v.SetDefault("network.id", 96369)
@@ -185,7 +486,87 @@ func (l *Loader) setDefaults() {
 	// Node defaults
 	l.v.SetDefault("node.http-port", 9630)
 	l.v.SetDefault("node.staking-port", 9631)
-	l.v.SetDefault("node.db-type", "badgerdb")
+	dbType := DefaultDBType
+	if l.detectDB {
+		dbType = DetectPreferredDBType()
+	}
+	l.v.SetDefault("node.db-type", dbType)
+
+	// Telemetry defaults (seeded from the node spec)
+	telemetry := defaultTelemetry()
+	l.v.SetDefault("telemetry.metrics-enabled", telemetry.MetricsEnabled)
+	l.v.SetDefault("telemetry.metrics-port", telemetry.MetricsPort)
+	l.v.SetDefault("telemetry.tracing-enabled", telemetry.TracingEnabled)
+	l.v.SetDefault("telemetry.tracing-endpoint", telemetry.TracingEndpoint)
+	l.v.SetDefault("telemetry.sample-rate", telemetry.SampleRate)
+}
+
+// expandConfig expands environment variables ($VAR and ${VAR}) in every
+// string field of cfg. Path fields (data-dir, plugin-dir, log.directory, and
+// any other field whose key ends in -dir or -file) also expand a leading ~.
+func expandConfig(cfg *LuxConfig) {
+	expandStrings(reflect.ValueOf(cfg).Elem())
+}
+
+// expandStrings walks struct fields, expanding string values in place
+func expandStrings(v reflect.Value) {
+	t := v.Type()
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		if !field.CanSet() {
+			continue
+		}
+		switch field.Kind() {
+		case reflect.Struct:
+			expandStrings(field)
+		case reflect.String:
+			if isPathKey(t.Field(i).Tag.Get("mapstructure")) {
+				field.SetString(expandPath(field.String()))
+			} else {
+				field.SetString(os.ExpandEnv(field.String()))
+			}
+		}
+	}
+}
+
+// resolveRelativePaths joins relative path values that came from a config
+// or profile file with that file's directory
+func (l *Loader) resolveRelativePaths(v reflect.Value, prefix string) {
+	t := v.Type()
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		tag := t.Field(i).Tag.Get("mapstructure")
+		if !field.CanSet() || tag == "" {
+			continue
+		}
+		key := prefix + tag
+		switch field.Kind() {
+		case reflect.Struct:
+			l.resolveRelativePaths(field, key+".")
+		case reflect.String:
+			path := field.String()
+			if !isPathKey(tag) || path == "" || filepath.IsAbs(path) {
+				continue
+			}
+			var file string
+			switch l.Source(key) {
+			case SourceFile:
+				if !l.notFound {
+					file = l.GetConfigFilePath()
+				}
+			case SourceProfile:
+				file = l.profileFile
+			}
+			if file != "" {
+				field.SetString(filepath.Join(filepath.Dir(file), path))
+			}
+		}
+	}
+}
+
+// isPathKey reports whether a config key names a filesystem path
+func isPathKey(key string) bool {
+	return key == "directory" || strings.HasSuffix(key, "-dir") || strings.HasSuffix(key, "-file")
 }
 
 // expandPath expands ~ and environment variables in paths
@@ -213,31 +594,217 @@ func expandPath(path string) string {
 
 // GetConfigFilePath returns the path of the config file that was loaded
 func (l *Loader) GetConfigFilePath() string {
+	if l.packedFile != "" {
+		return l.packedFile
+	}
 	return l.v.ConfigFileUsed()
 }
 
+// ConflictingConfigFiles returns the config files found in the search paths
+// by the last Load that were shadowed by the file actually used
+// (GetConfigFilePath). It is empty when an explicit config file was set.
+func (l *Loader) ConflictingConfigFiles() []string {
+	return l.shadowed
+}
+
+// shadowedConfigFiles lists config files in the search paths other than used
+func (l *Loader) shadowedConfigFiles(used string) []string {
+	usedAbs, _ := filepath.Abs(used)
+	seen := make(map[string]bool)
+	var files []string
+	for _, dir := range l.SearchPaths() {
+		for _, ext := range configExts {
+			for _, suffix := range append([]string{""}, compressedExts...) {
+				path := filepath.Join(dir, ConfigFileName+"."+ext+suffix)
+				abs, err := filepath.Abs(path)
+				if err != nil || abs == usedAbs || seen[abs] || !Exists(path) {
+					continue
+				}
+				seen[abs] = true
+				files = append(files, path)
+			}
+		}
+	}
+	return files
+}
+
+// SearchPaths returns the config search directories in the order they are
+// tried, with ~ and environment variables expanded
+func (l *Loader) SearchPaths() []string {
+	paths := make([]string, 0, len(l.configPaths))
+	for _, path := range l.configPaths {
+		paths = append(paths, expandPath(path))
+	}
+	return paths
+}
+
+// Warnings returns non-fatal issues found during the last Load
+func (l *Loader) Warnings() []string {
+	return l.warnings
+}
+
+// Profile returns the selected profile name, from WithProfile or LUX_PROFILE
+func (l *Loader) Profile() string {
+	if l.profile != "" {
+		return l.profile
+	}
+	return os.Getenv(ProfileEnvVar)
+}
+
+// GetProfileFilePath returns the path of the profile file that was merged
+func (l *Loader) GetProfileFilePath() string {
+	return l.profileFile
+}
+
+// Profiles returns the names of all profiles found in the search paths
+func (l *Loader) Profiles() []string {
+	seen := make(map[string]bool)
+	for _, dir := range l.profileDirs() {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+			if name := profileName(entry.Name()); name != "" {
+				seen[name] = true
+			}
+		}
+	}
+
+	profiles := make([]string, 0, len(seen))
+	for name := range seen {
+		profiles = append(profiles, name)
+	}
+	sort.Strings(profiles)
+	return profiles
+}
+
+// mergeProfile finds the first profile file in the search paths and merges it
+func (l *Loader) mergeProfile(profile string) error {
+	for _, dir := range l.profileDirs() {
+		for _, ext := range configExts {
+			path := filepath.Join(dir, ConfigFileName+"."+profile+"."+ext)
+			if !Exists(path) {
+				continue
+			}
+
+			pv := viper.New()
+			pv.SetConfigFile(path)
+			if err := pv.ReadInConfig(); err != nil {
+				return fmt.Errorf("error reading profile %q: %w", profile, err)
+			}
+			if err := l.v.MergeConfigMap(pv.AllSettings()); err != nil {
+				return fmt.Errorf("error merging profile %q: %w", profile, err)
+			}
+			l.profileFile = path
+			l.profileKeys = make(map[string]bool)
+			for _, key := range pv.AllKeys() {
+				l.profileKeys[key] = true
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("config profile %q not found in search paths", profile)
+}
+
+// profileDirs returns the directories searched for profile files.
+// The directory of an explicit config file is searched first.
+func (l *Loader) profileDirs() []string {
+	dirs := l.SearchPaths()
+	if l.configFile != "" {
+		dirs = append([]string{filepath.Dir(expandPath(l.configFile))}, dirs...)
+	}
+	return dirs
+}
+
+// profileName extracts the profile from a config.<profile>.<ext> file name
+func profileName(fileName string) string {
+	prefix := ConfigFileName + "."
+	if !strings.HasPrefix(fileName, prefix) {
+		return ""
+	}
+	rest := strings.TrimPrefix(fileName, prefix)
+	ext := filepath.Ext(rest)
+	if !contains(configExts, strings.TrimPrefix(ext, ".")) {
+		return ""
+	}
+	return strings.TrimSuffix(rest, ext)
+}
+
+// ConfigFileNotFound reports whether the last Load found no config file in
+// any of the SearchPaths and fell back to env, flags, and defaults
+func (l *Loader) ConfigFileNotFound() bool {
+	return l.notFound
+}
+
 // Global returns the global configuration instance (singleton)
-// This lazily loads configuration on first call
+// This lazily loads configuration on first call. If loading fails it falls
+// back to DefaultConfig, or panics when SetStrictGlobal(true) is in effect.
 func Global() *LuxConfig {
+	cfg, err := GlobalE()
+	if err != nil && strictGlobal.Load() {
+		panic(fmt.Sprintf("config: failed to load global configuration: %v", err))
+	}
+	return cfg
+}
+
+// GlobalE is Global, returning the lazy load's error instead of hiding it.
+// In lenient mode the config is DefaultConfig when the error is non-nil;
+// in strict mode it is nil. The error is returned on every call for as long
+// as the fallback config from the failed load is the global config, not
+// only to the caller that triggered the load.
+func GlobalE() (*LuxConfig, error) {
+	if cfg := globalStore.Get(); cfg != nil {
+		return cfg, globalErrFor(cfg)
+	}
 	configOnce.Do(func() {
 		loader := NewLoader()
-		var err error
-		globalConfig, err = loader.Load()
+		cfg, err := loader.Load()
 		if err != nil {
-			// Fall back to defaults on error
-			// Log warning would go here
-			globalConfig = DefaultConfig()
+			globalLoadErr = err
+			if strictGlobal.Load() {
+				return
+			}
+			cfg = DefaultConfig()
+			globalFallback = cfg
 		}
+		// SetGlobal may have raced ahead of the lazy load
+		globalStore.setIfEmpty(cfg)
 	})
-	return globalConfig
+	cfg := globalStore.Get()
+	if cfg == nil {
+		return nil, globalLoadErr
+	}
+	return cfg, globalErrFor(cfg)
+}
+
+// globalErrFor returns the lazy load error if cfg is the fallback stored
+// after that load failed
+func globalErrFor(cfg *LuxConfig) error {
+	if globalLoadErr != nil && cfg == globalFallback {
+		return globalLoadErr
+	}
+	return nil
+}
+
+// SetStrictGlobal controls whether a failed lazy load in Global is fatal.
+// When strict, Global panics and GlobalE returns a nil config with the
+// error instead of falling back to mainnet defaults. It must be called
+// before the first Global or GlobalE call: the load runs once under a
+// sync.Once, and a lenient load that already fell back to defaults is not
+// revisited.
+func SetStrictGlobal(strict bool) {
+	strictGlobal.Store(strict)
 }
 
 // SetGlobal sets the global configuration instance
-// This should be called early in application startup
+// This should be called early in application startup; later calls notify
+// GlobalStore subscribers
 func SetGlobal(cfg *LuxConfig) {
-	configMutex.Lock()
-	defer configMutex.Unlock()
-	globalConfig = cfg
+	globalStore.Set(cfg)
 }
 
 // DefaultConfig returns the default configuration
@@ -265,9 +832,45 @@ func DefaultConfig() *LuxConfig {
 		Node: NodeConfig{
 			HTTPPort:    9630,
 			StakingPort: 9631,
-			DBType:      "badgerdb",
+			DBType:      DefaultDBType,
 		},
+		Telemetry: defaultTelemetry(),
+	}
+}
+
+// defaultTelemetry returns the telemetry defaults, taking metrics and tracing
+// settings from the node spec where it has a corresponding key
+func defaultTelemetry() TelemetryConfig {
+	cfg := TelemetryConfig{
+		MetricsEnabled: true,
+		SampleRate:     0.1,
 	}
+	s, err := spec.Spec()
+	if err != nil {
+		return cfg
+	}
+
+	if f := s.GetFlag("api-metrics-enabled"); f != nil {
+		if enabled, ok := f.Default.(bool); ok {
+			cfg.MetricsEnabled = enabled
+		}
+	}
+	if f := s.GetFlag("tracing-exporter-type"); f != nil {
+		if exporter, ok := f.Default.(string); ok {
+			cfg.TracingEnabled = exporter != "" && exporter != "disabled"
+		}
+	}
+	if f := s.GetFlag("tracing-endpoint"); f != nil {
+		if endpoint, ok := f.Default.(string); ok {
+			cfg.TracingEndpoint = endpoint
+		}
+	}
+	if f := s.GetFlag("tracing-sample-rate"); f != nil {
+		if rate, ok := f.Default.(float64); ok {
+			cfg.SampleRate = rate
+		}
+	}
+	return cfg
 }
 
 // MustLoad loads configuration and panics on error
@@ -0,0 +1,166 @@
+// Copyright (C) 2021-2025, Lux Industries Inc. All rights reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package config
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/spf13/viper"
+)
+
+// compressedExts are the config file extensions decompressed transparently
+var compressedExts = []string{".gz", ".zst"}
+
+// isCompressedConfig reports whether path names a compressed config file
+func isCompressedConfig(path string) bool {
+	return contains(compressedExts, strings.ToLower(filepath.Ext(path)))
+}
+
+// innerConfigFormat returns the config format of a possibly compressed
+// path from its inner extension, e.g. json for config.json.gz
+func innerConfigFormat(path string) (string, error) {
+	if isCompressedConfig(path) {
+		path = strings.TrimSuffix(path, filepath.Ext(path))
+	}
+	format := strings.ToLower(strings.TrimPrefix(filepath.Ext(path), "."))
+	if !contains(configExts, format) {
+		return "", fmt.Errorf("unsupported config format %q for %s: must be one of %s", format, path, strings.Join(configExts, ", "))
+	}
+	return format, nil
+}
+
+// readCompressedConfig decompresses the config file at path and merges it
+// into the loader's viper
+func (l *Loader) readCompressedConfig(path string) error {
+	format, err := innerConfigFormat(path)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("error reading config file: %w", err)
+	}
+	defer f.Close()
+
+	r, err := decompressReader(path, f)
+	if err != nil {
+		return fmt.Errorf("error reading config file: %w", err)
+	}
+	defer r.Close()
+
+	rv := viper.New()
+	rv.SetConfigType(format)
+	if err := rv.ReadConfig(r); err != nil {
+		return fmt.Errorf("error reading config file: %w", err)
+	}
+	if err := l.v.MergeConfigMap(rv.AllSettings()); err != nil {
+		return fmt.Errorf("error merging config: %w", err)
+	}
+	return nil
+}
+
+// findPackedConfig returns the first compressed config file in the search
+// paths, or "" if an uncompressed config file comes first. Within a
+// directory an uncompressed file takes precedence.
+func (l *Loader) findPackedConfig() string {
+	for _, dir := range l.SearchPaths() {
+		for _, ext := range viper.SupportedExts {
+			if Exists(filepath.Join(dir, ConfigFileName+"."+ext)) {
+				return ""
+			}
+		}
+		for _, ext := range configExts {
+			for _, cext := range compressedExts {
+				if path := filepath.Join(dir, ConfigFileName+"."+ext+cext); Exists(path) {
+					return path
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// decompressReader wraps r in a decompressor chosen by path's extension
+func decompressReader(path string, r io.Reader) (io.ReadCloser, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".gz":
+		return gzip.NewReader(r)
+	case ".zst":
+		d, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return d.IOReadCloser(), nil
+	default:
+		return io.NopCloser(r), nil
+	}
+}
+
+// compressWriter wraps w in a compressor chosen by path's extension
+func compressWriter(path string, w io.Writer) (io.WriteCloser, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".gz":
+		return gzip.NewWriter(w), nil
+	case ".zst":
+		return zstd.NewWriter(w)
+	default:
+		return nopWriteCloser{w}, nil
+	}
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+// Save writes the merged settings of the last Load to path. The format is
+// taken from the extension; a .gz or .zst suffix compresses the output,
+// e.g. config.json.gz is gzipped JSON.
+func (l *Loader) Save(path string) error {
+	path = expandPath(path)
+	format, err := innerConfigFormat(path)
+	if err != nil {
+		return err
+	}
+
+	sv := viper.New()
+	sv.SetConfigType(format)
+	if err := sv.MergeConfigMap(l.v.AllSettings()); err != nil {
+		return fmt.Errorf("failed to prepare config: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create config file: %w", err)
+	}
+	w, err := compressWriter(path, f)
+	if err != nil {
+		f.Close()
+		os.Remove(path)
+		return err
+	}
+	if err := sv.WriteConfigTo(w); err != nil {
+		w.Close()
+		f.Close()
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
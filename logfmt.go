@@ -0,0 +1,284 @@
+// Copyright (C) 2024-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package config
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+var logfmtPool = buffer.NewPool()
+
+var _ zapcore.Encoder = (*logfmtEncoder)(nil)
+
+// logfmtEncoder is a zapcore.Encoder that writes entries as logfmt
+// key=value pairs. Values containing spaces, quotes, '=' or control
+// characters are quoted; arrays and objects are rendered as quoted JSON.
+type logfmtEncoder struct {
+	cfg       *zapcore.EncoderConfig
+	buf       *buffer.Buffer // Context fields added with With
+	namespace string         // Key prefix set by OpenNamespace
+}
+
+// newLogfmtEncoder creates a logfmt encoder using cfg's keys and encoders
+func newLogfmtEncoder(cfg zapcore.EncoderConfig) zapcore.Encoder {
+	return &logfmtEncoder{cfg: &cfg, buf: logfmtPool.Get()}
+}
+
+// Clone copies the encoder, including fields added so far
+func (e *logfmtEncoder) Clone() zapcore.Encoder {
+	clone := &logfmtEncoder{cfg: e.cfg, buf: logfmtPool.Get(), namespace: e.namespace}
+	_, _ = clone.buf.Write(e.buf.Bytes())
+	return clone
+}
+
+// EncodeEntry writes time, level, logger, caller, and message, followed by
+// context and entry fields and the stacktrace
+func (e *logfmtEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	line := &logfmtEncoder{cfg: e.cfg, buf: logfmtPool.Get()}
+
+	if e.cfg.TimeKey != "" && !ent.Time.IsZero() {
+		if e.cfg.EncodeTime != nil {
+			line.addEncoded(e.cfg.TimeKey, func(enc zapcore.PrimitiveArrayEncoder) { e.cfg.EncodeTime(ent.Time, enc) })
+		} else {
+			line.AddString(e.cfg.TimeKey, ent.Time.Format(time.RFC3339Nano))
+		}
+	}
+	if e.cfg.LevelKey != "" {
+		if e.cfg.EncodeLevel != nil {
+			line.addEncoded(e.cfg.LevelKey, func(enc zapcore.PrimitiveArrayEncoder) { e.cfg.EncodeLevel(ent.Level, enc) })
+		} else {
+			line.AddString(e.cfg.LevelKey, ent.Level.String())
+		}
+	}
+	if e.cfg.NameKey != "" && ent.LoggerName != "" {
+		if e.cfg.EncodeName != nil {
+			line.addEncoded(e.cfg.NameKey, func(enc zapcore.PrimitiveArrayEncoder) { e.cfg.EncodeName(ent.LoggerName, enc) })
+		} else {
+			line.AddString(e.cfg.NameKey, ent.LoggerName)
+		}
+	}
+	if e.cfg.CallerKey != "" && ent.Caller.Defined {
+		if e.cfg.EncodeCaller != nil {
+			line.addEncoded(e.cfg.CallerKey, func(enc zapcore.PrimitiveArrayEncoder) { e.cfg.EncodeCaller(ent.Caller, enc) })
+		} else {
+			line.AddString(e.cfg.CallerKey, ent.Caller.TrimmedPath())
+		}
+	}
+	if e.cfg.MessageKey != "" {
+		line.AddString(e.cfg.MessageKey, ent.Message)
+	}
+
+	if e.buf.Len() > 0 {
+		line.separate()
+		_, _ = line.buf.Write(e.buf.Bytes())
+	}
+	line.namespace = e.namespace
+	for _, field := range fields {
+		field.AddTo(line)
+	}
+	line.namespace = ""
+
+	if e.cfg.StacktraceKey != "" && ent.Stack != "" {
+		line.AddString(e.cfg.StacktraceKey, ent.Stack)
+	}
+
+	lineEnding := e.cfg.LineEnding
+	if lineEnding == "" {
+		lineEnding = zapcore.DefaultLineEnding
+	}
+	line.buf.AppendString(lineEnding)
+	return line.buf, nil
+}
+
+// separate writes a space before every pair but the first
+func (e *logfmtEncoder) separate() {
+	if e.buf.Len() > 0 {
+		e.buf.AppendByte(' ')
+	}
+}
+
+// addKey writes the (namespaced, sanitized) key and '='
+func (e *logfmtEncoder) addKey(key string) {
+	e.separate()
+	if e.namespace != "" {
+		key = e.namespace + "." + key
+	}
+	e.buf.AppendString(logfmtKey(key))
+	e.buf.AppendByte('=')
+}
+
+// addValue writes a key with a value, quoting it if needed
+func (e *logfmtEncoder) addValue(key, value string) {
+	e.addKey(key)
+	e.buf.AppendString(logfmtValue(value))
+}
+
+// addEncoded writes a key with the value produced by a zap primitive encoder
+func (e *logfmtEncoder) addEncoded(key string, encode func(zapcore.PrimitiveArrayEncoder)) {
+	var values logfmtPrimitives
+	encode(&values)
+	e.addValue(key, strings.Join(values, " "))
+}
+
+// addJSON writes a key with a structured value rendered as JSON
+func (e *logfmtEncoder) addJSON(key string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	e.addValue(key, string(data))
+	return nil
+}
+
+func (e *logfmtEncoder) AddArray(key string, arr zapcore.ArrayMarshaler) error {
+	m := zapcore.NewMapObjectEncoder()
+	if err := m.AddArray(key, arr); err != nil {
+		return err
+	}
+	return e.addJSON(key, m.Fields[key])
+}
+
+func (e *logfmtEncoder) AddObject(key string, obj zapcore.ObjectMarshaler) error {
+	m := zapcore.NewMapObjectEncoder()
+	if err := obj.MarshalLogObject(m); err != nil {
+		return err
+	}
+	return e.addJSON(key, m.Fields)
+}
+
+func (e *logfmtEncoder) AddReflected(key string, value interface{}) error {
+	return e.addJSON(key, value)
+}
+
+func (e *logfmtEncoder) OpenNamespace(key string) {
+	if e.namespace != "" {
+		key = e.namespace + "." + key
+	}
+	e.namespace = key
+}
+
+func (e *logfmtEncoder) AddBinary(key string, value []byte) {
+	e.addValue(key, base64.StdEncoding.EncodeToString(value))
+}
+func (e *logfmtEncoder) AddByteString(key string, value []byte) { e.addValue(key, string(value)) }
+func (e *logfmtEncoder) AddBool(key string, value bool)         { e.addValue(key, strconv.FormatBool(value)) }
+func (e *logfmtEncoder) AddComplex128(key string, value complex128) {
+	e.addValue(key, strconv.FormatComplex(value, 'g', -1, 128))
+}
+func (e *logfmtEncoder) AddComplex64(key string, value complex64) {
+	e.addValue(key, strconv.FormatComplex(complex128(value), 'g', -1, 64))
+}
+func (e *logfmtEncoder) AddDuration(key string, value time.Duration) {
+	if e.cfg.EncodeDuration == nil {
+		e.addValue(key, value.String())
+		return
+	}
+	e.addEncoded(key, func(enc zapcore.PrimitiveArrayEncoder) { e.cfg.EncodeDuration(value, enc) })
+}
+func (e *logfmtEncoder) AddFloat64(key string, value float64) {
+	e.addValue(key, formatFloat(value, 64))
+}
+func (e *logfmtEncoder) AddFloat32(key string, value float32) {
+	e.addValue(key, formatFloat(float64(value), 32))
+}
+func (e *logfmtEncoder) AddInt(key string, value int) { e.AddInt64(key, int64(value)) }
+func (e *logfmtEncoder) AddInt64(key string, value int64) {
+	e.addValue(key, strconv.FormatInt(value, 10))
+}
+func (e *logfmtEncoder) AddInt32(key string, value int32) { e.AddInt64(key, int64(value)) }
+func (e *logfmtEncoder) AddInt16(key string, value int16) { e.AddInt64(key, int64(value)) }
+func (e *logfmtEncoder) AddInt8(key string, value int8)   { e.AddInt64(key, int64(value)) }
+func (e *logfmtEncoder) AddString(key, value string)      { e.addValue(key, value) }
+func (e *logfmtEncoder) AddUint(key string, value uint)   { e.AddUint64(key, uint64(value)) }
+func (e *logfmtEncoder) AddUint64(key string, value uint64) {
+	e.addValue(key, strconv.FormatUint(value, 10))
+}
+func (e *logfmtEncoder) AddUint32(key string, value uint32)   { e.AddUint64(key, uint64(value)) }
+func (e *logfmtEncoder) AddUint16(key string, value uint16)   { e.AddUint64(key, uint64(value)) }
+func (e *logfmtEncoder) AddUint8(key string, value uint8)     { e.AddUint64(key, uint64(value)) }
+func (e *logfmtEncoder) AddUintptr(key string, value uintptr) { e.AddUint64(key, uint64(value)) }
+func (e *logfmtEncoder) AddTime(key string, value time.Time) {
+	if e.cfg.EncodeTime == nil {
+		e.addValue(key, value.Format(time.RFC3339Nano))
+		return
+	}
+	e.addEncoded(key, func(enc zapcore.PrimitiveArrayEncoder) { e.cfg.EncodeTime(value, enc) })
+}
+
+// logfmtPrimitives collects values appended by zap's level, time, duration,
+// caller, and name encoders
+type logfmtPrimitives []string
+
+func (p *logfmtPrimitives) AppendBool(v bool)         { *p = append(*p, strconv.FormatBool(v)) }
+func (p *logfmtPrimitives) AppendByteString(v []byte) { *p = append(*p, string(v)) }
+func (p *logfmtPrimitives) AppendComplex128(v complex128) {
+	*p = append(*p, strconv.FormatComplex(v, 'g', -1, 128))
+}
+func (p *logfmtPrimitives) AppendComplex64(v complex64)    { p.AppendComplex128(complex128(v)) }
+func (p *logfmtPrimitives) AppendFloat64(v float64)        { *p = append(*p, formatFloat(v, 64)) }
+func (p *logfmtPrimitives) AppendFloat32(v float32)        { *p = append(*p, formatFloat(float64(v), 32)) }
+func (p *logfmtPrimitives) AppendInt(v int)                { p.AppendInt64(int64(v)) }
+func (p *logfmtPrimitives) AppendInt64(v int64)            { *p = append(*p, strconv.FormatInt(v, 10)) }
+func (p *logfmtPrimitives) AppendInt32(v int32)            { p.AppendInt64(int64(v)) }
+func (p *logfmtPrimitives) AppendInt16(v int16)            { p.AppendInt64(int64(v)) }
+func (p *logfmtPrimitives) AppendInt8(v int8)              { p.AppendInt64(int64(v)) }
+func (p *logfmtPrimitives) AppendString(v string)          { *p = append(*p, v) }
+func (p *logfmtPrimitives) AppendUint(v uint)              { p.AppendUint64(uint64(v)) }
+func (p *logfmtPrimitives) AppendUint64(v uint64)          { *p = append(*p, strconv.FormatUint(v, 10)) }
+func (p *logfmtPrimitives) AppendUint32(v uint32)          { p.AppendUint64(uint64(v)) }
+func (p *logfmtPrimitives) AppendUint16(v uint16)          { p.AppendUint64(uint64(v)) }
+func (p *logfmtPrimitives) AppendUint8(v uint8)            { p.AppendUint64(uint64(v)) }
+func (p *logfmtPrimitives) AppendUintptr(v uintptr)        { p.AppendUint64(uint64(v)) }
+func (p *logfmtPrimitives) AppendDuration(v time.Duration) { *p = append(*p, v.String()) }
+func (p *logfmtPrimitives) AppendTime(v time.Time)         { *p = append(*p, v.Format(time.RFC3339Nano)) }
+
+// formatFloat formats a float, spelling out NaN and infinities
+func formatFloat(v float64, bitSize int) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, bitSize)
+}
+
+// logfmtKey replaces characters that would break key=value parsing
+func logfmtKey(key string) string {
+	if key == "" {
+		return "_"
+	}
+	return strings.Map(func(r rune) rune {
+		if r == '=' || r == '"' || unicode.IsSpace(r) || !unicode.IsPrint(r) {
+			return '_'
+		}
+		return r
+	}, key)
+}
+
+// logfmtValue quotes a value if it is empty or contains spaces, quotes,
+// '=', or non-printable characters
+func logfmtValue(value string) string {
+	if value == "" {
+		return `""`
+	}
+	for _, r := range value {
+		if r == '=' || r == '"' || r == '\\' || r == utf8.RuneError || unicode.IsSpace(r) || !unicode.IsPrint(r) {
+			return strconv.Quote(value)
+		}
+	}
+	return value
+}
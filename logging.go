@@ -4,9 +4,12 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
+	"syscall"
 	"time"
 
 	"go.uber.org/zap"
@@ -35,16 +38,150 @@ const (
 	LogFormatTerminal LogFormat = "terminal"
 	LogFormatJSON     LogFormat = "json"
 	LogFormatPlain    LogFormat = "plain"
+	LogFormatLogfmt   LogFormat = "logfmt"
 )
 
+// Default encoder settings, used when the LogConfig fields are empty
+const (
+	DefaultLogTimeKey    = "time"
+	DefaultLogLevelKey   = "level"
+	DefaultLogMessageKey = "msg"
+	DefaultLogTimeLayout = "2006-01-02T15:04:05.000Z07:00"
+)
+
+// OutputType identifies the kind of log destination
+type OutputType string
+
+const (
+	OutputConsole OutputType = "console"
+	OutputFile    OutputType = "file"
+)
+
+// OutputInfo describes one destination a factory's loggers write to
+type OutputInfo struct {
+	Type   OutputType `json:"type"`
+	Target string     `json:"target"`           // "stdout" or the log file path
+	Logger string     `json:"logger,omitempty"` // Logger name, for per-logger files
+	Level  string     `json:"level"`
+	Format string     `json:"format"`
+}
+
 // LogFactory creates configured loggers
 type LogFactory struct {
-	config LogConfig
+	config     LogConfig
+	bestEffort bool // Skip the log directory check, see WithBestEffortFileLogging
+
+	mu    sync.Mutex
+	files []OutputInfo   // File outputs of loggers created so far
+	cores []zapcore.Core // Cores of loggers created so far, for SyncAll
+}
+
+// LogFactoryOption configures a LogFactory
+type LogFactoryOption func(*LogFactory)
+
+// WithBestEffortFileLogging lets CreateLogger succeed when the log
+// directory cannot be created or written, logging to the console only
+func WithBestEffortFileLogging() LogFactoryOption {
+	return func(f *LogFactory) {
+		f.bestEffort = true
+	}
 }
 
 // NewLogFactory creates a new log factory from configuration
-func NewLogFactory(cfg LogConfig) *LogFactory {
-	return &LogFactory{config: cfg}
+func NewLogFactory(cfg LogConfig, opts ...LogFactoryOption) *LogFactory {
+	f := &LogFactory{config: cfg}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f
+}
+
+// ValidateOutputs checks that file logging, if configured, can work: the
+// log directory must be creatable and writable. CreateLogger runs it unless
+// the factory was created with WithBestEffortFileLogging.
+func (f *LogFactory) ValidateOutputs() error {
+	if f.config.Directory == "" {
+		return nil
+	}
+	if err := checkWritableDir(f.config.Directory); err != nil {
+		return fmt.Errorf("log directory unusable: %w", err)
+	}
+	return nil
+}
+
+// Outputs describes where the factory's loggers write: the console, plus the
+// log file of each logger created so far (file paths depend on the name)
+func (f *LogFactory) Outputs() []OutputInfo {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	outputs := []OutputInfo{{
+		Type:   OutputConsole,
+		Target: "stdout",
+		Level:  levelName(f.parseLevel()),
+		Format: f.consoleFormat(),
+	}}
+	return append(outputs, f.files...)
+}
+
+// Enabled returns the outputs from Outputs that would write a record logged
+// at level, judged by each output's own level. Passing LogLevelOff returns
+// nil, since no record is logged at that level.
+func (f *LogFactory) Enabled(level LogLevel) []OutputInfo {
+	if level == LogLevelOff {
+		return nil
+	}
+	recordLevel := ToZapLevel(level)
+
+	var enabled []OutputInfo
+	for _, output := range f.Outputs() {
+		if recordLevel >= ToZapLevel(LogLevel(output.Level)) {
+			enabled = append(enabled, output)
+		}
+	}
+	return enabled
+}
+
+// SyncAll flushes every logger created by the factory, including loggers
+// derived from them with Named or With, which share their cores. Call it
+// before the process exits. Errors from syncing a console that does not
+// support it (e.g. a pipe or terminal) are ignored.
+func (f *LogFactory) SyncAll() error {
+	f.mu.Lock()
+	cores := append([]zapcore.Core(nil), f.cores...)
+	f.mu.Unlock()
+
+	var errs []error
+	for _, core := range cores {
+		if err := core.Sync(); err != nil && !isUnsyncableConsole(err) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// isUnsyncableConsole reports whether err is the error returned when
+// syncing stdout attached to a pipe or terminal
+func isUnsyncableConsole(err error) bool {
+	return errors.Is(err, syscall.EINVAL) || errors.Is(err, syscall.ENOTTY)
+}
+
+// levelName returns the zap level name, or "off" for the disabled level
+func levelName(level zapcore.Level) string {
+	if level > zapcore.FatalLevel {
+		return string(LogLevelOff)
+	}
+	return level.String()
+}
+
+// consoleFormat returns the effective console format name
+func (f *LogFactory) consoleFormat() string {
+	switch LogFormat(f.config.Format) {
+	case LogFormatJSON, LogFormatPlain, LogFormatLogfmt:
+		return f.config.Format
+	default:
+		return string(LogFormatTerminal)
+	}
 }
 
 // NewLogFactoryFromGlobal creates a log factory from global config
@@ -54,6 +191,12 @@ func NewLogFactoryFromGlobal() *LogFactory {
 
 // CreateLogger creates a new logger with the given name
 func (f *LogFactory) CreateLogger(name string) (*zap.Logger, error) {
+	if !f.bestEffort {
+		if err := f.ValidateOutputs(); err != nil {
+			return nil, err
+		}
+	}
+
 	// Parse level
 	level := f.parseLevel()
 
@@ -69,6 +212,10 @@ func (f *LogFactory) CreateLogger(name string) (*zap.Logger, error) {
 	// Combine cores
 	core := zapcore.NewTee(cores...)
 
+	f.mu.Lock()
+	f.cores = append(f.cores, core)
+	f.mu.Unlock()
+
 	// Build logger options
 	opts := []zap.Option{
 		zap.AddStacktrace(zapcore.ErrorLevel),
@@ -82,7 +229,30 @@ func (f *LogFactory) CreateLogger(name string) (*zap.Logger, error) {
 
 // parseLevel converts string level to zapcore.Level
 func (f *LogFactory) parseLevel() zapcore.Level {
-	switch LogLevel(f.config.Level) {
+	return ToZapLevel(LogLevel(f.config.Level))
+}
+
+// logLevels are the known log levels, from most to least verbose
+var logLevels = []LogLevel{
+	LogLevelVerbo, LogLevelDebug, LogLevelTrace, LogLevelInfo,
+	LogLevelWarn, LogLevelError, LogLevelFatal, LogLevelOff,
+}
+
+// ParseLogLevel returns s as a LogLevel, failing if it is not a known level
+func ParseLogLevel(s string) (LogLevel, error) {
+	for _, level := range logLevels {
+		if string(level) == s {
+			return level, nil
+		}
+	}
+	return "", fmt.Errorf("invalid log level: %s", s)
+}
+
+// ToZapLevel converts a LogLevel to the zapcore.Level loggers filter on.
+// verbo and trace map to debug, off to a level above fatal, and unknown
+// levels to info.
+func ToZapLevel(level LogLevel) zapcore.Level {
+	switch level {
 	case LogLevelVerbo, LogLevelTrace, LogLevelDebug:
 		return zapcore.DebugLevel
 	case LogLevelInfo:
@@ -100,15 +270,37 @@ func (f *LogFactory) parseLevel() zapcore.Level {
 	}
 }
 
-// encoderConfig creates the encoder configuration
+// FromZapLevel converts a zapcore.Level back to its canonical LogLevel.
+// Levels below debug map to debug; dpanic and panic map to error so that
+// no entry enabled by level is dropped; levels above fatal map to off.
+func FromZapLevel(level zapcore.Level) LogLevel {
+	switch {
+	case level <= zapcore.DebugLevel:
+		return LogLevelDebug
+	case level == zapcore.InfoLevel:
+		return LogLevelInfo
+	case level == zapcore.WarnLevel:
+		return LogLevelWarn
+	case level < zapcore.FatalLevel:
+		return LogLevelError
+	case level == zapcore.FatalLevel:
+		return LogLevelFatal
+	default:
+		return LogLevelOff
+	}
+}
+
+// encoderConfig creates the encoder configuration. Key names and the time
+// layout come from LogConfig, falling back to the defaults.
 func (f *LogFactory) encoderConfig() zapcore.EncoderConfig {
+	timeKey, levelKey, messageKey := f.config.encoderKeys()
 	return zapcore.EncoderConfig{
-		TimeKey:        "time",
-		LevelKey:       "level",
+		TimeKey:        timeKey,
+		LevelKey:       levelKey,
 		NameKey:        "logger",
 		CallerKey:      "caller",
 		FunctionKey:    zapcore.OmitKey,
-		MessageKey:     "msg",
+		MessageKey:     messageKey,
 		StacktraceKey:  "stacktrace",
 		LineEnding:     zapcore.DefaultLineEnding,
 		EncodeLevel:    f.levelEncoder(),
@@ -125,6 +317,8 @@ func (f *LogFactory) createEncoder(cfg zapcore.EncoderConfig) zapcore.Encoder {
 		return zapcore.NewJSONEncoder(cfg)
 	case LogFormatPlain:
 		return zapcore.NewConsoleEncoder(cfg)
+	case LogFormatLogfmt:
+		return newLogfmtEncoder(cfg)
 	default: // terminal
 		if f.config.ShowColors {
 			cfg.EncodeLevel = zapcore.CapitalColorLevelEncoder
@@ -165,6 +359,16 @@ func (f *LogFactory) createCores(encoder zapcore.Encoder, cfg zapcore.EncoderCon
 				level,
 			)
 			cores = append(cores, fileCore)
+
+			f.mu.Lock()
+			f.files = append(f.files, OutputInfo{
+				Type:   OutputFile,
+				Target: logPath,
+				Logger: name,
+				Level:  levelName(level),
+				Format: string(LogFormatJSON),
+			})
+			f.mu.Unlock()
 		}
 	}
 
@@ -181,9 +385,44 @@ func (f *LogFactory) levelEncoder() zapcore.LevelEncoder {
 
 // timeEncoder returns the time encoder
 func (f *LogFactory) timeEncoder() zapcore.TimeEncoder {
+	layout := f.config.TimeLayout
+	if layout == "" {
+		layout = DefaultLogTimeLayout
+	}
 	return func(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
-		enc.AppendString(t.Format("2006-01-02T15:04:05.000Z07:00"))
+		enc.AppendString(t.Format(layout))
+	}
+}
+
+// encoderKeys returns the effective time, level, and message keys
+func (c LogConfig) encoderKeys() (timeKey, levelKey, messageKey string) {
+	timeKey, levelKey, messageKey = c.TimeKey, c.LevelKey, c.MessageKey
+	if timeKey == "" {
+		timeKey = DefaultLogTimeKey
+	}
+	if levelKey == "" {
+		levelKey = DefaultLogLevelKey
+	}
+	if messageKey == "" {
+		messageKey = DefaultLogMessageKey
+	}
+	return timeKey, levelKey, messageKey
+}
+
+// validateEncoderKeys fails if the effective encoder keys collide with each
+// other or with the logger and caller keys
+func (c LogConfig) validateEncoderKeys() error {
+	timeKey, levelKey, messageKey := c.encoderKeys()
+	seen := map[string]string{"logger": "logger", "caller": "caller"}
+	for _, k := range []struct{ name, key string }{
+		{"log.time-key", timeKey}, {"log.level-key", levelKey}, {"log.message-key", messageKey},
+	} {
+		if other, ok := seen[k.key]; ok {
+			return fmt.Errorf("invalid %s: %q is already used by %s", k.name, k.key, other)
+		}
+		seen[k.key] = k.name
 	}
+	return nil
 }
 
 // CreateNopLogger creates a no-op logger that discards all output
@@ -0,0 +1,239 @@
+// Copyright (C) 2021-2025, Lux Industries Inc. All rights reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// baseSubdirs are the top-level directories moved by MigrateBaseDir
+var baseSubdirs = []string{ChainsDir, NetworksDir, PluginsDir, KeysDir, SnapshotsDir}
+
+// MigrateBaseDir moves the chains, networks, plugins, keys, and snapshots
+// trees to newBase and returns a Paths rooted there. Each tree is renamed
+// when possible and copied then removed when the rename fails (e.g. across
+// filesystems). Absolute symlinks pointing into the old base directory, such
+// as the plugin current/ links, are rewritten to point into newBase.
+func (p *Paths) MigrateBaseDir(newBase string) (*Paths, error) {
+	return p.MigrateBaseDirContext(context.Background(), newBase)
+}
+
+// MigrateBaseDirContext is MigrateBaseDir, returning ctx.Err() if ctx is
+// cancelled while trees are copied or symlinks rewritten. The migration is
+// all or nothing: on any error, including cancellation, trees already moved
+// are moved back and their symlinks restored, so the old base directory is
+// left as it was. If that rollback also fails, both errors are returned.
+func (p *Paths) MigrateBaseDirContext(ctx context.Context, newBase string) (*Paths, error) {
+	oldBase, err := filepath.Abs(p.BaseDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve base directory: %w", err)
+	}
+	newBase, err = filepath.Abs(newBase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve new base directory: %w", err)
+	}
+	if oldBase == newBase {
+		return nil, fmt.Errorf("new base directory is the current base directory: %s", newBase)
+	}
+	if strings.HasPrefix(newBase, oldBase+string(filepath.Separator)) {
+		return nil, fmt.Errorf("new base directory %s is inside %s", newBase, oldBase)
+	}
+
+	// Refuse to merge into existing trees
+	for _, dir := range baseSubdirs {
+		if Exists(filepath.Join(newBase, dir)) {
+			return nil, fmt.Errorf("destination already contains %s: %s", dir, newBase)
+		}
+	}
+
+	migrated := &Paths{BaseDir: newBase, Permissions: p.Permissions, NodePrefixes: p.NodePrefixes}
+	if err := migrated.EnsureDir(newBase); err != nil {
+		return nil, fmt.Errorf("failed to create new base directory: %w", err)
+	}
+
+	var moved []string
+	fail := func(err error) (*Paths, error) {
+		if rbErr := rollbackMigration(moved, oldBase, newBase); rbErr != nil {
+			return nil, errors.Join(err, fmt.Errorf("failed to roll back migration: %w", rbErr))
+		}
+		return nil, err
+	}
+
+	for _, dir := range baseSubdirs {
+		select {
+		case <-ctx.Done():
+			return fail(ctx.Err())
+		default:
+		}
+
+		src := filepath.Join(oldBase, dir)
+		if !Exists(src) {
+			continue
+		}
+		if err := moveTree(ctx, src, filepath.Join(newBase, dir)); err != nil {
+			return fail(fmt.Errorf("failed to move %s: %w", dir, err))
+		}
+		moved = append(moved, dir)
+	}
+
+	if err := relinkTree(ctx, newBase, oldBase, newBase); err != nil {
+		return fail(fmt.Errorf("failed to rewrite symlinks: %w", err))
+	}
+
+	return migrated, nil
+}
+
+// rollbackMigration moves the given subdirectories of newBase back to
+// oldBase, newest first, and points any symlinks already rewritten into
+// newBase back at oldBase. It runs to completion even if the migration's
+// context was cancelled.
+func rollbackMigration(moved []string, oldBase, newBase string) error {
+	ctx := context.Background()
+	var errs []error
+	for i := len(moved) - 1; i >= 0; i-- {
+		src, dst := filepath.Join(newBase, moved[i]), filepath.Join(oldBase, moved[i])
+		if err := moveTree(ctx, src, dst); err != nil {
+			errs = append(errs, fmt.Errorf("failed to restore %s: %w", moved[i], err))
+			continue
+		}
+		if err := relinkTree(ctx, dst, src, dst); err != nil {
+			errs = append(errs, fmt.Errorf("failed to restore symlinks in %s: %w", moved[i], err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// moveTree renames src to dst, falling back to copy and remove
+func moveTree(ctx context.Context, src, dst string) error {
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+	if err := copyTree(ctx, src, dst); err != nil {
+		_ = removeTree(dst)
+		return err
+	}
+	return removeTree(src)
+}
+
+// removeTree removes path and everything under it, first restoring owner
+// write permission so read-only plugin packages can be deleted
+func removeTree(path string) error {
+	if err := makeWritable(path); err != nil {
+		return err
+	}
+	return os.RemoveAll(path)
+}
+
+// copyTree recursively copies src to dst, preserving modes and symlinks.
+// Directories are created writable and given their source modes once the
+// copy completes, so read-only directories can still be filled.
+func copyTree(ctx context.Context, src, dst string) error {
+	type dirMode struct {
+		path string
+		mode os.FileMode
+	}
+	var dirs []dirMode
+
+	err := filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		info, err := os.Lstat(path)
+		if err != nil {
+			return err
+		}
+		switch {
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case info.IsDir():
+			dirs = append(dirs, dirMode{target, info.Mode().Perm()})
+			return os.MkdirAll(target, info.Mode().Perm()|0700)
+		default:
+			return copyFileMode(path, target, info.Mode().Perm())
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	// Deepest first, so a parent is never made read-only before its children
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if err := os.Chmod(dirs[i].path, dirs[i].mode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyFileMode copies a regular file, creating dst with mode
+func copyFileMode(src, dst string, mode os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// relinkTree rewrites absolute symlinks under root whose targets start with
+// oldPrefix so they point at the same location under newPrefix
+func relinkTree(ctx context.Context, root, oldPrefix, newPrefix string) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if d.Type()&os.ModeSymlink == 0 {
+			return nil
+		}
+
+		target, err := os.Readlink(path)
+		if err != nil {
+			return err
+		}
+		if !filepath.IsAbs(target) {
+			return nil
+		}
+		rel, err := filepath.Rel(oldPrefix, target)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return nil
+		}
+
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		return os.Symlink(filepath.Join(newPrefix, rel), path)
+	})
+}
@@ -0,0 +1,114 @@
+// Copyright (C) 2024-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Network IDs of the well-known networks. MainnetID is the node's default
+// network-id; these must be kept in step with the IDs luxd assigns.
+const (
+	MainnetID uint32 = 96369
+	TestnetID uint32 = 96368
+	LocalID   uint32 = 1337
+)
+
+var (
+	networksMu sync.RWMutex
+
+	// networkIDs maps canonical network names to their IDs
+	networkIDs = map[string]uint32{
+		NetworkMainnet: MainnetID,
+		NetworkTestnet: TestnetID,
+		NetworkLocal:   LocalID,
+	}
+)
+
+// RegisterNetwork adds a named network to the process-wide table used by
+// NormalizeNetworkName and LuxConfig.ValidateWithWarnings. Names match
+// case-insensitively and are canonicalized to the registered spelling.
+// Registering the same name and ID again is a no-op; reusing a name or ID
+// for a different network is an error.
+func RegisterNetwork(name string, id uint32) error {
+	if name == "" {
+		return fmt.Errorf("network name cannot be empty")
+	}
+	if id == 0 {
+		return fmt.Errorf("network id cannot be zero")
+	}
+
+	networksMu.Lock()
+	defer networksMu.Unlock()
+
+	for known, knownID := range networkIDs {
+		switch {
+		case strings.EqualFold(known, name) && knownID != id:
+			return fmt.Errorf("network %q is already registered with id %d", known, knownID)
+		case knownID == id && !strings.EqualFold(known, name):
+			return fmt.Errorf("network id %d is already registered to %q", id, known)
+		case strings.EqualFold(known, name):
+			return nil
+		}
+	}
+	networkIDs[name] = id
+	return nil
+}
+
+// LookupNetworkID returns the ID of a registered network name
+func LookupNetworkID(name string) (uint32, bool) {
+	canonical, ok := lookupNetworkName(name)
+	if !ok {
+		return 0, false
+	}
+
+	networksMu.RLock()
+	defer networksMu.RUnlock()
+	return networkIDs[canonical], true
+}
+
+// LookupNetworkName returns the registered name for a network ID
+func LookupNetworkName(id uint32) (string, bool) {
+	networksMu.RLock()
+	defer networksMu.RUnlock()
+
+	for name, knownID := range networkIDs {
+		if knownID == id {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// RegisteredNetworks returns the registered network names, sorted
+func RegisteredNetworks() []string {
+	networksMu.RLock()
+	defer networksMu.RUnlock()
+
+	names := make([]string, 0, len(networkIDs))
+	for name := range networkIDs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// lookupNetworkName finds the registered spelling of name, case-insensitively
+func lookupNetworkName(name string) (string, bool) {
+	networksMu.RLock()
+	defer networksMu.RUnlock()
+
+	if _, ok := networkIDs[name]; ok {
+		return name, true
+	}
+	for known := range networkIDs {
+		if strings.EqualFold(known, name) {
+			return known, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,150 @@
+// Copyright (C) 2019-2025, Lux Industries, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// NodeLaunchParams describes one node of a local network for BuildNodeFlags
+type NodeLaunchParams struct {
+	Paths    *Paths                // Required
+	Chains   *ChainManager         // Required when TrackChains is set
+	Plugins  *PluginPackageManager // Required
+	Network  string                // Required
+	RunID    string                // Required
+	NodeName string                // Required
+
+	// NetworkID overrides the ID registered for Network
+	NetworkID uint32
+
+	// NodeIndex offsets the ports of BaseNode with PortsForNode; a zero
+	// BaseNode uses the default ports
+	NodeIndex int
+	BaseNode  NodeConfig
+
+	// TrackChains maps chain names in the ChainManager to chain IDs. Their
+	// configs are copied into the node's chain-config-dir.
+	TrackChains map[string]string
+
+	// BootstrapIDs and BootstrapIPs list the bootstrap peers, pairwise.
+	// Both are empty for the first node of a network.
+	BootstrapIDs []string
+	BootstrapIPs []string
+}
+
+// MissingLaunchInputError is returned by BuildNodeFlags when required
+// parameters or files are missing
+type MissingLaunchInputError struct {
+	Node    string
+	Missing []string
+}
+
+func (e *MissingLaunchInputError) Error() string {
+	return fmt.Sprintf("cannot build flags for node %s: missing %s", e.Node, strings.Join(e.Missing, ", "))
+}
+
+// BuildNodeFlags returns the luxd flags for a node, as --key=value
+// arguments: its data, db, log, and config directories from Paths, staking
+// keys, bootstrap peers, tracked chains, the active plugin directory, and
+// ports. The configs of tracked chains are copied into the node's
+// chain-config-dir. Missing inputs are reported together in a
+// *MissingLaunchInputError.
+func BuildNodeFlags(params NodeLaunchParams) ([]string, error) {
+	var missing []string
+	need := func(ok bool, what string) {
+		if !ok {
+			missing = append(missing, what)
+		}
+	}
+	need(params.Paths != nil, "paths")
+	need(params.Plugins != nil, "plugin manager")
+	need(params.Network != "", "network")
+	need(params.RunID != "", "run id")
+	need(params.NodeName != "", "node name")
+	need(params.Chains != nil || len(params.TrackChains) == 0, "chain manager")
+	if len(missing) > 0 {
+		return nil, &MissingLaunchInputError{Node: params.NodeName, Missing: missing}
+	}
+
+	networkID := params.NetworkID
+	if networkID == 0 {
+		networkID, _ = LookupNetworkID(params.Network)
+	}
+	need(networkID != 0, NetworkIDKey)
+
+	p := params.Paths
+	stakingKey := p.NodeStakingKey(params.Network, params.NodeName)
+	stakingCert := p.NodeStakingCert(params.Network, params.NodeName)
+	signerKey := p.NodeSignerKey(params.Network, params.NodeName)
+	need(Exists(stakingKey), StakingTLSKeyPathKey+" "+stakingKey)
+	need(Exists(stakingCert), StakingCertPathKey+" "+stakingCert)
+	need(Exists(signerKey), StakingSignerKeyPathKey+" "+signerKey)
+
+	chainNames := make([]string, 0, len(params.TrackChains))
+	for name := range params.TrackChains {
+		chainNames = append(chainNames, name)
+		need(params.TrackChains[name] != "", "chain id for "+name)
+		need(params.Chains.ChainExists(name), "chain "+name)
+	}
+	sort.Strings(chainNames)
+	if len(missing) > 0 {
+		return nil, &MissingLaunchInputError{Node: params.NodeName, Missing: missing}
+	}
+
+	if len(params.BootstrapIDs) != len(params.BootstrapIPs) {
+		return nil, fmt.Errorf("node %s has %d bootstrap ids but %d bootstrap ips",
+			params.NodeName, len(params.BootstrapIDs), len(params.BootstrapIPs))
+	}
+
+	base := params.BaseNode
+	if base.HTTPPort == 0 && base.StakingPort == 0 {
+		base = DefaultConfig().Node
+	}
+	ports, err := PortsForNode(base, params.NodeIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	dirs := p.NodeDataPaths(params.Network, params.RunID, params.NodeName)
+	if err := ValidateNodeLayout(dirs.NodeDir, dirs.DBDir, dirs.LogsDir, dirs.ChainConfigDir); err != nil {
+		return nil, err
+	}
+
+	trackChains, err := TrackChainsFor(chainNames, params.TrackChains)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range chainNames {
+		if err := params.Chains.CopyChainConfigsToNode(name, params.TrackChains[name], dirs.NodeDir); err != nil {
+			return nil, fmt.Errorf("failed to copy configs for chain %s: %w", name, err)
+		}
+	}
+
+	flags := []struct{ key, value string }{
+		{DataDirKey, dirs.NodeDir},
+		{DBPathKey, dirs.DBDir},
+		{LogsDirKey, dirs.LogsDir},
+		{ChainConfigDirKey, dirs.ChainConfigDir},
+		{NetConfigDirKey, dirs.NetConfigDir},
+		{PluginDirKey, params.Plugins.GetActiveDir()},
+		{NetworkIDKey, strconv.FormatUint(uint64(networkID), 10)},
+		{HTTPPortKey, strconv.Itoa(ports.HTTPPort)},
+		{StakingPortKey, strconv.Itoa(ports.StakingPort)},
+		{StakingTLSKeyPathKey, stakingKey},
+		{StakingCertPathKey, stakingCert},
+		{StakingSignerKeyPathKey, signerKey},
+		{BootstrapIDsKey, strings.Join(params.BootstrapIDs, ",")},
+		{BootstrapIPsKey, strings.Join(params.BootstrapIPs, ",")},
+		{TrackChainsKey, trackChains.String()},
+	}
+	args := make([]string, 0, len(flags))
+	for _, f := range flags {
+		args = append(args, fmt.Sprintf("--%s=%s", f.key, f.value))
+	}
+	return args, nil
+}
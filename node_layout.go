@@ -0,0 +1,117 @@
+// Copyright (C) 2021-2025, Lux Industries Inc. All rights reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// nodeLayoutOptions holds settings for ValidateNodeLayout
+type nodeLayoutOptions struct {
+	allowOutside bool
+}
+
+// NodeLayoutOption is a functional option for ValidateNodeLayout
+type NodeLayoutOption func(*nodeLayoutOptions)
+
+// AllowOutsideNodeDir lets the db, log, and chain config directories live
+// outside the node directory
+func AllowOutsideNodeDir() NodeLayoutOption {
+	return func(o *nodeLayoutOptions) {
+		o.allowOutside = true
+	}
+}
+
+// layoutDir is one named directory of a node layout
+type layoutDir struct {
+	key  string
+	path string
+}
+
+// ValidateNodeLayout checks that a node's db-dir, log-dir, and
+// chain-config-dir are set, distinct, not nested in one another, and
+// inside nodeDir unless AllowOutsideNodeDir is given
+func ValidateNodeLayout(nodeDir, dbDir, logDir, chainConfigDir string, opts ...NodeLayoutOption) error {
+	var o nodeLayoutOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if nodeDir == "" && !o.allowOutside {
+		return fmt.Errorf("node directory is not set")
+	}
+	dirs := []layoutDir{
+		{DBPathKey, dbDir},
+		{LogsDirKey, logDir},
+		{ChainConfigDirKey, chainConfigDir},
+	}
+	for i := range dirs {
+		if dirs[i].path == "" {
+			return fmt.Errorf("%s is not set", dirs[i].key)
+		}
+		abs, err := filepath.Abs(dirs[i].path)
+		if err != nil {
+			return fmt.Errorf("failed to resolve %s: %w", dirs[i].key, err)
+		}
+		dirs[i].path = abs
+	}
+
+	for i, a := range dirs {
+		for _, b := range dirs[i+1:] {
+			if pathWithin(a.path, b.path) || pathWithin(b.path, a.path) {
+				return fmt.Errorf("%s %s overlaps %s %s", a.key, a.path, b.key, b.path)
+			}
+		}
+	}
+
+	if !o.allowOutside {
+		root, err := filepath.Abs(nodeDir)
+		if err != nil {
+			return fmt.Errorf("failed to resolve node directory: %w", err)
+		}
+		for _, d := range dirs {
+			if d.path == root || !pathWithin(root, d.path) {
+				return fmt.Errorf("%s %s is not inside node directory %s", d.key, d.path, root)
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateNodeLayouts runs ValidateNodeLayout on each node and also
+// rejects directories shared between nodes, such as two nodes with the
+// same db-dir
+func ValidateNodeLayouts(nodes []NodeDataPaths, opts ...NodeLayoutOption) error {
+	owners := make(map[string]string)
+	for _, n := range nodes {
+		if err := ValidateNodeLayout(n.NodeDir, n.DBDir, n.LogsDir, n.ChainConfigDir, opts...); err != nil {
+			return fmt.Errorf("node %s: %w", n.NodeDir, err)
+		}
+		for _, dir := range []string{n.DBDir, n.LogsDir, n.ChainConfigDir} {
+			abs, _ := filepath.Abs(dir) // Resolved above
+			for other, owner := range owners {
+				if pathWithin(abs, other) || pathWithin(other, abs) {
+					return fmt.Errorf("node %s uses %s, which overlaps %s of node %s", n.NodeDir, abs, other, owner)
+				}
+			}
+		}
+		for _, dir := range []string{n.DBDir, n.LogsDir, n.ChainConfigDir} {
+			abs, _ := filepath.Abs(dir)
+			owners[abs] = n.NodeDir
+		}
+	}
+	return nil
+}
+
+// pathWithin reports whether path is root or below it. Both must be
+// absolute and clean.
+func pathWithin(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
@@ -0,0 +1,108 @@
+// Copyright (C) 2024-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/luxfi/config/spec"
+)
+
+// nodeArg maps a LuxConfig setting to a luxd flag
+type nodeArg struct {
+	key   string // luxd flag key, as named in the node spec
+	value func(cfg *LuxConfig) string
+}
+
+// nodeArgs are the luxd flags rendered by ToNodeArgs, in output order
+var nodeArgs = []nodeArg{
+	{"data-dir", func(cfg *LuxConfig) string { return cfg.DataDir }},
+	{"plugin-dir", func(cfg *LuxConfig) string { return nodePluginDir(cfg.PluginDir) }},
+	{"network-id", func(cfg *LuxConfig) string { return strconv.FormatUint(uint64(cfg.Network.ID), 10) }},
+	{"http-port", func(cfg *LuxConfig) string { return strconv.Itoa(cfg.Node.HTTPPort) }},
+	{"staking-port", func(cfg *LuxConfig) string { return strconv.Itoa(cfg.Node.StakingPort) }},
+	{"db-type", func(cfg *LuxConfig) string { return cfg.Node.DBType }},
+	{"log-level", func(cfg *LuxConfig) string { return cfg.Log.Level }},
+	{"log-dir", func(cfg *LuxConfig) string { return cfg.Log.Directory }},
+	{"log-rotater-max-size", func(cfg *LuxConfig) string { return strconv.Itoa(cfg.Log.MaxSize) }},
+	{"log-rotater-max-files", func(cfg *LuxConfig) string { return strconv.Itoa(cfg.Log.MaxFiles) }},
+	{"log-rotater-max-age", func(cfg *LuxConfig) string { return strconv.Itoa(cfg.Log.MaxAge) }},
+	{"log-rotater-compress-enabled", func(cfg *LuxConfig) string { return strconv.FormatBool(cfg.Log.Compress) }},
+	{"api-metrics-enabled", func(cfg *LuxConfig) string { return strconv.FormatBool(cfg.Telemetry.MetricsEnabled) }},
+	{"tracing-exporter-type", func(cfg *LuxConfig) string {
+		if cfg.Telemetry.TracingEnabled {
+			return "grpc"
+		}
+		return "disabled"
+	}},
+	{"tracing-endpoint", func(cfg *LuxConfig) string { return cfg.Telemetry.TracingEndpoint }},
+	{"tracing-sample-rate", func(cfg *LuxConfig) string {
+		return strconv.FormatFloat(cfg.Telemetry.SampleRate, 'g', -1, 64)
+	}},
+}
+
+// ToNodeArgs renders cfg as luxd command-line arguments of the form
+// --key=value. Settings with an empty value are omitted, letting the node
+// apply its own default. Enabled tracing uses the grpc exporter.
+func ToNodeArgs(cfg *LuxConfig) []string {
+	var args []string
+	for _, arg := range nodeArgs {
+		if value := arg.value(cfg); value != "" {
+			args = append(args, fmt.Sprintf("--%s=%s", arg.key, value))
+		}
+	}
+	return args
+}
+
+// NodeArgsForCategories is ToNodeArgs restricted to the flags that belong
+// to one of categories in the node spec, so a launcher can pass, say, only
+// network and staking settings and manage the rest itself
+func NodeArgsForCategories(cfg *LuxConfig, categories ...spec.Category) ([]string, error) {
+	s, err := spec.Spec()
+	if err != nil {
+		return nil, fmt.Errorf("error loading config spec: %w", err)
+	}
+
+	wanted := make(map[spec.Category]bool, len(categories))
+	for _, cat := range categories {
+		wanted[cat] = true
+	}
+
+	var args []string
+	for _, arg := range nodeArgs {
+		flag := s.GetFlag(arg.key)
+		if flag == nil || !wanted[flag.Category] {
+			continue
+		}
+		if value := arg.value(cfg); value != "" {
+			args = append(args, fmt.Sprintf("--%s=%s", arg.key, value))
+		}
+	}
+	return args, nil
+}
+
+// nodePluginDir returns the directory luxd should load plugins from for a
+// plugin base directory: current/ for the package layout, else baseDir.
+// baseDir is also used when current/ does not exist yet, e.g. before the
+// first activation or after an interrupted install.
+func nodePluginDir(baseDir string) string {
+	if baseDir == "" {
+		return ""
+	}
+	if layout, err := DetectPluginLayout(baseDir); err == nil && layout == LayoutPackages {
+		if current := filepath.Join(baseDir, CurrentPluginsDir); isDir(current) {
+			return current
+		}
+	}
+	return baseDir
+}
+
+// isDir reports whether path is a directory, following symlinks
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
@@ -0,0 +1,67 @@
+// Copyright (C) 2021-2025, Lux Industries Inc. All rights reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package config
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+
+	"github.com/btcsuite/btcutil/base58"
+	"golang.org/x/crypto/ripemd160"
+)
+
+// NodeIDPrefix prefixes the string form of a node ID
+const NodeIDPrefix = "NodeID-"
+
+// NodeIDFromCert computes a node's ID from its PEM-encoded staking
+// certificate the way the node does: ripemd160(sha256(DER)), encoded as
+// CB58 (base58 with a 4-byte sha256 checksum) and prefixed with "NodeID-".
+func NodeIDFromCert(certPEM []byte) (string, error) {
+	block, _ := pem.Decode(certPEM)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return "", fmt.Errorf("no PEM certificate found")
+	}
+	if _, err := x509.ParseCertificate(block.Bytes); err != nil {
+		return "", fmt.Errorf("failed to parse certificate: %w", err)
+	}
+
+	digest := sha256.Sum256(block.Bytes)
+	h := ripemd160.New()
+	h.Write(digest[:])
+	return NodeIDPrefix + cb58Encode(h.Sum(nil)), nil
+}
+
+// NodeIDForNode reads the node's staking certificate (NodeStakingCert) and
+// returns its node ID
+func (p *Paths) NodeIDForNode(networkName, nodeName string) (string, error) {
+	certPEM, err := os.ReadFile(p.NodeStakingCert(networkName, nodeName))
+	if err != nil {
+		return "", fmt.Errorf("failed to read staking certificate for %s: %w", nodeName, err)
+	}
+	return NodeIDFromCert(certPEM)
+}
+
+// cb58Encode encodes b as base58 with the last 4 bytes of sha256(b) appended
+func cb58Encode(b []byte) string {
+	checksum := sha256.Sum256(b)
+	return base58.Encode(append(append([]byte{}, b...), checksum[len(checksum)-4:]...))
+}
+
+// cb58Decode reverses cb58Encode, verifying the checksum
+func cb58Decode(s string) ([]byte, error) {
+	decoded := base58.Decode(s)
+	if len(decoded) < 4 {
+		return nil, fmt.Errorf("invalid cb58 string")
+	}
+	payload, checksum := decoded[:len(decoded)-4], decoded[len(decoded)-4:]
+	want := sha256.Sum256(payload)
+	if !bytes.Equal(checksum, want[len(want)-4:]) {
+		return nil, fmt.Errorf("invalid cb58 checksum")
+	}
+	return payload, nil
+}
@@ -33,9 +33,13 @@
 package config
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -55,6 +59,13 @@ const (
 	RunsDir           = "runs"
 	CurrentPluginsDir = "current"
 
+	// Per-node data subdirectories under a node's run directory
+	NodeDBDir           = "db"
+	NodeLogsDir         = "logs"
+	NodeConfigsDir      = "configs"
+	NodeChainConfigsDir = "chains" // Under NodeConfigsDir
+	NodeNetConfigsDir   = "nets"   // Under NodeConfigsDir
+
 	// File names for chain configs
 	GenesisFile = "genesis.json"
 	ConfigFile  = "config.json"
@@ -72,15 +83,67 @@ const (
 
 	// Run directory prefix
 	RunPrefix = "run"
+
+	// maxSymlinkHops bounds symlink resolution to guard against loops
+	maxSymlinkHops = 40
+)
+
+// Errors returned when resolving plugin binaries
+var (
+	ErrPluginNotFound      = errors.New("plugin not installed")
+	ErrPluginDangling      = errors.New("plugin symlink target missing")
+	ErrPluginNotExecutable = errors.New("plugin is not an executable file")
+	ErrSymlinkLoop         = errors.New("too many levels of symbolic links")
 )
 
+// PermissionPolicy controls the modes used for created directories and files
+type PermissionPolicy struct {
+	// DirMode is used for regular directories
+	DirMode os.FileMode
+
+	// FileMode is used for regular files (chain configs, manifests)
+	FileMode os.FileMode
+
+	// KeyDirMode is used for directories holding node keys
+	KeyDirMode os.FileMode
+
+	// KeyFileMode is used for node key and certificate files
+	KeyFileMode os.FileMode
+}
+
+// DefaultPermissionPolicy returns the default permissions: world-readable
+// data, owner-only keys
+func DefaultPermissionPolicy() PermissionPolicy {
+	return PermissionPolicy{
+		DirMode:     0755,
+		FileMode:    0644,
+		KeyDirMode:  0700,
+		KeyFileMode: 0600,
+	}
+}
+
 // Paths provides unified path management for Lux tools.
 // Create one instance and use it throughout your application.
 type Paths struct {
 	// BaseDir is the root data directory (default: ~/.lux)
 	BaseDir string
+
+	// Permissions overrides the default permission policy when set
+	Permissions *PermissionPolicy
+
+	// NodePrefixes are the name prefixes that identify node directories in a
+	// run (e.g. "validator", "beacon"). Nil means DefaultNodePrefixes; an
+	// empty, non-nil slice means any directory is a node.
+	NodePrefixes []string
+
+	// sizes caches directory sizes for DirSize, created on first use
+	sizes *dirSizeCache
 }
 
+// DefaultNodePrefixes are the node directory prefixes used when
+// Paths.NodePrefixes is nil
+var DefaultNodePrefixes = []string{"node"}
+
 // DefaultPaths returns a Paths instance using the default base directory (~/.lux)
 func DefaultPaths() (*Paths, error) {
 	homeDir, err := os.UserHomeDir()
@@ -95,6 +158,19 @@ func NewPaths(baseDir string) *Paths {
 	return &Paths{BaseDir: baseDir}
 }
 
+// NewPathsWithPolicy creates a Paths instance with a custom permission policy
+func NewPathsWithPolicy(baseDir string, policy PermissionPolicy) *Paths {
+	return &Paths{BaseDir: baseDir, Permissions: &policy}
+}
+
+// Policy returns the effective permission policy
+func (p *Paths) Policy() PermissionPolicy {
+	if p.Permissions != nil {
+		return *p.Permissions
+	}
+	return DefaultPermissionPolicy()
+}
+
 // --- Chain Config Paths ---
 
 // ChainsBaseDir returns the base directory for all chain configs
@@ -159,6 +235,40 @@ func (p *Paths) NodeDir(networkName, runID, nodeName string) string {
 	return filepath.Join(p.NetworkRunDir(networkName, runID), nodeName)
 }
 
+// NodeDataPaths are the data directories of one node in a run
+type NodeDataPaths struct {
+	NodeDir        string // ~/.lux/networks/<network>/runs/<runID>/<node>/
+	DBDir          string // <NodeDir>/db
+	LogsDir        string // <NodeDir>/logs
+	ConfigsDir     string // <NodeDir>/configs
+	ChainConfigDir string // <NodeDir>/configs/chains
+	NetConfigDir   string // <NodeDir>/configs/nets
+}
+
+// NodeDataPaths returns the data directories for a node within a run
+func (p *Paths) NodeDataPaths(networkName, runID, nodeName string) NodeDataPaths {
+	nodeDir := p.NodeDir(networkName, runID, nodeName)
+	configsDir := filepath.Join(nodeDir, NodeConfigsDir)
+	return NodeDataPaths{
+		NodeDir:        nodeDir,
+		DBDir:          filepath.Join(nodeDir, NodeDBDir),
+		LogsDir:        filepath.Join(nodeDir, NodeLogsDir),
+		ConfigsDir:     configsDir,
+		ChainConfigDir: filepath.Join(configsDir, NodeChainConfigsDir),
+		NetConfigDir:   filepath.Join(configsDir, NodeNetConfigsDir),
+	}
+}
+
+// Flags returns the luxd flags pointing the node at these directories
+func (n NodeDataPaths) Flags() map[string]string {
+	return map[string]string{
+		DBPathKey:         n.DBDir,
+		LogsDirKey:        n.LogsDir,
+		ChainConfigDirKey: n.ChainConfigDir,
+		NetConfigDirKey:   n.NetConfigDir,
+	}
+}
+
 // --- Plugin Paths ---
 
 // PluginsBaseDir returns the base directory for all plugins
@@ -179,6 +289,37 @@ func (p *Paths) PluginPath(vmID string) string {
 	return filepath.Join(p.CurrentPluginsDir(), vmID)
 }
 
+// ResolvePluginBinary returns the absolute path of the executable behind a
+// VMID's plugin entry, following symlinks. Errors wrap ErrPluginNotFound,
+// ErrPluginDangling, ErrPluginNotExecutable, or ErrSymlinkLoop.
+func (p *Paths) ResolvePluginBinary(vmID string) (string, error) {
+	pluginPath := p.PluginPath(vmID)
+	if _, err := os.Lstat(pluginPath); err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("%w: %s", ErrPluginNotFound, vmID)
+		}
+		return "", fmt.Errorf("failed to check plugin %s: %w", vmID, err)
+	}
+
+	target, err := ResolveSymlink(pluginPath)
+	if err != nil {
+		return "", err
+	}
+
+	info, err := os.Stat(target)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("%w: %s -> %s", ErrPluginDangling, vmID, target)
+		}
+		return "", fmt.Errorf("failed to check plugin target %s: %w", target, err)
+	}
+	if !info.Mode().IsRegular() || info.Mode()&0111 == 0 {
+		return "", fmt.Errorf("%w: %s", ErrPluginNotExecutable, target)
+	}
+
+	return filepath.Abs(target)
+}
+
 // --- Key Paths ---
 
 // KeysBaseDir returns the base directory for all keys
@@ -235,7 +376,12 @@ func (p *Paths) SnapshotDir(snapshotName string) string {
 
 // EnsureDir creates a directory if it doesn't exist
 func (p *Paths) EnsureDir(path string) error {
-	return os.MkdirAll(path, 0755)
+	return os.MkdirAll(path, p.Policy().DirMode)
+}
+
+// WriteFile writes a regular data file using the policy's FileMode
+func (p *Paths) WriteFile(path string, data []byte) error {
+	return os.WriteFile(path, data, p.Policy().FileMode)
 }
 
 // EnsureChainDir creates the chain config directory
@@ -253,9 +399,28 @@ func (p *Paths) EnsureCurrentPluginsDir() error {
 	return p.EnsureDir(p.CurrentPluginsDir())
 }
 
-// EnsureNodeKeysDir creates the keys directory for a node
+// EnsureNodeKeysDir creates the keys directory for a node.
+// The node's directory gets the policy's KeyDirMode even if it already exists.
 func (p *Paths) EnsureNodeKeysDir(networkName, nodeName string) error {
-	return p.EnsureDir(p.NodeKeysDir(networkName, nodeName))
+	dir := p.NodeKeysDir(networkName, nodeName)
+	if err := os.MkdirAll(dir, p.Policy().KeyDirMode); err != nil {
+		return err
+	}
+	return os.Chmod(dir, p.Policy().KeyDirMode)
+}
+
+// WriteNodeKeyFile writes a key or certificate file (e.g. StakingKeyFile)
+// into the node's keys directory using the policy's key modes
+func (p *Paths) WriteNodeKeyFile(networkName, nodeName, fileName string, data []byte) error {
+	if err := p.EnsureNodeKeysDir(networkName, nodeName); err != nil {
+		return fmt.Errorf("failed to create keys directory: %w", err)
+	}
+	path := filepath.Join(p.NodeKeysDir(networkName, nodeName), fileName)
+	if err := os.WriteFile(path, data, p.Policy().KeyFileMode); err != nil {
+		return err
+	}
+	// WriteFile keeps the mode of an existing file, so enforce it
+	return os.Chmod(path, p.Policy().KeyFileMode)
 }
 
 // --- Run Management ---
@@ -266,9 +431,71 @@ func NewRunID() string {
 	return fmt.Sprintf("%s_%s", RunPrefix, time.Now().Format("20060102_150405"))
 }
 
+// ListNodes returns the sorted names of the node directories in a run.
+// Returns nil if the run does not exist.
+func (p *Paths) ListNodes(networkName, runID string) ([]string, error) {
+	return p.ListNodesContext(context.Background(), networkName, runID)
+}
+
+// ListNodesContext is ListNodes, returning ctx.Err() if ctx is cancelled
+// while the run directory is scanned
+func (p *Paths) ListNodesContext(ctx context.Context, networkName, runID string) ([]string, error) {
+	entries, err := os.ReadDir(p.NetworkRunDir(networkName, runID))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var nodes []string
+	for _, entry := range entries {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		if entry.IsDir() && p.isNodeDirName(entry.Name()) {
+			nodes = append(nodes, entry.Name())
+		}
+	}
+	sort.Strings(nodes)
+	return nodes, nil
+}
+
+// isNodeDirName reports whether a run subdirectory name is a node directory
+func (p *Paths) isNodeDirName(name string) bool {
+	prefixes := p.NodePrefixes
+	if prefixes == nil {
+		prefixes = DefaultNodePrefixes
+	}
+	if len(prefixes) == 0 {
+		return true
+	}
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
 // FindLatestRun finds the most recent run directory with node data
 // Returns the run ID (not full path) or empty string if none found
 func (p *Paths) FindLatestRun(networkName string) (string, error) {
+	return p.FindLatestRunContext(context.Background(), networkName)
+}
+
+// FindLatestRunContext is FindLatestRun, returning ctx.Err() if ctx is
+// cancelled while runs are scanned
+func (p *Paths) FindLatestRunContext(ctx context.Context, networkName string) (string, error) {
+	return p.findLatestRun(ctx, networkName, nil)
+}
+
+// findLatestRun implements FindLatestRunContext, ignoring runs for which
+// skip, if set, returns true
+func (p *Paths) findLatestRun(ctx context.Context, networkName string, skip func(runID string) bool) (string, error) {
 	runsDir := p.NetworkRunsDir(networkName)
 	entries, err := os.ReadDir(runsDir)
 	if err != nil {
@@ -280,6 +507,12 @@ func (p *Paths) FindLatestRun(networkName string) (string, error) {
 
 	var latestRunID string
 	for _, entry := range entries {
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		default:
+		}
+
 		if !entry.IsDir() {
 			continue
 		}
@@ -288,18 +521,16 @@ func (p *Paths) FindLatestRun(networkName string) (string, error) {
 			continue
 		}
 
-		// Check if this run has node directories
-		runPath := filepath.Join(runsDir, name)
-		nodeEntries, _ := os.ReadDir(runPath)
-		hasNodes := false
-		for _, nodeEntry := range nodeEntries {
-			if nodeEntry.IsDir() && len(nodeEntry.Name()) >= 4 && nodeEntry.Name()[:4] == "node" {
-				hasNodes = true
-				break
-			}
+		if skip != nil && skip(name) {
+			continue
 		}
 
-		if hasNodes {
+		// Check if this run has node directories
+		nodes, err := p.ListNodesContext(ctx, networkName, name)
+		if err != nil && ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		if len(nodes) > 0 {
 			// Timestamps sort lexicographically
 			if latestRunID == "" || name > latestRunID {
 				latestRunID = name
@@ -311,15 +542,19 @@ func (p *Paths) FindLatestRun(networkName string) (string, error) {
 }
 
 // GetOrCreateRun finds existing run or creates new one
-// Returns the full path to the run directory
+// Returns the full path to the run directory. Runs locked by another
+// process (see TryLockRun) are never reused; use GetOrCreateLockedRun to
+// also lock the returned run.
 func (p *Paths) GetOrCreateRun(networkName string) (string, error) {
 	// Ensure runs directory exists
 	if err := p.EnsureNetworkRunsDir(networkName); err != nil {
 		return "", err
 	}
 
-	// Try to find existing run
-	latestRunID, err := p.FindLatestRun(networkName)
+	// Try to find an existing run that nobody else is using
+	latestRunID, err := p.findLatestRun(context.Background(), networkName, func(runID string) bool {
+		return p.IsRunLocked(networkName, runID)
+	})
 	if err != nil {
 		return "", err
 	}
@@ -338,6 +573,57 @@ func (p *Paths) GetOrCreateRun(networkName string) (string, error) {
 	return runDir, nil
 }
 
+// GetOrCreateLockedRun is GetOrCreateRun, also locking the chosen run (see
+// TryLockRun). It returns the full path to the run directory and the func
+// that releases the lock. A run locked by another process between being
+// chosen and being locked is skipped in favor of the next candidate.
+func (p *Paths) GetOrCreateLockedRun(networkName string) (string, func() error, error) {
+	// Ensure runs directory exists
+	if err := p.EnsureNetworkRunsDir(networkName); err != nil {
+		return "", nil, err
+	}
+
+	// Try to lock the latest existing run that nobody else is using
+	skipped := make(map[string]bool)
+	skip := func(runID string) bool {
+		return skipped[runID] || p.IsRunLocked(networkName, runID)
+	}
+	for {
+		runID, err := p.findLatestRun(context.Background(), networkName, skip)
+		if err != nil {
+			return "", nil, err
+		}
+		if runID == "" {
+			break
+		}
+		unlock, err := p.TryLockRun(networkName, runID)
+		if err == nil {
+			return p.NetworkRunDir(networkName, runID), unlock, nil
+		}
+		if !errors.Is(err, ErrRunLocked) {
+			return "", nil, err
+		}
+		skipped[runID] = true
+	}
+
+	// Create and lock a new run, picking a unique ID if another process
+	// created one in the same second
+	base := NewRunID()
+	for i := 1; ; i++ {
+		runID := base
+		if i > 1 {
+			runID = fmt.Sprintf("%s_%d", base, i)
+		}
+		unlock, err := p.TryLockRun(networkName, runID)
+		if err == nil {
+			return p.NetworkRunDir(networkName, runID), unlock, nil
+		}
+		if !errors.Is(err, ErrRunLocked) {
+			return "", nil, err
+		}
+	}
+}
+
 // --- Utility Functions ---
 
 // Exists checks if a path exists
@@ -346,6 +632,35 @@ func Exists(path string) bool {
 	return err == nil
 }
 
+// ResolveSymlink follows a chain of symlinks starting at path and returns
+// the final non-symlink path, which may not exist. Relative targets are
+// resolved against the link's directory. Returns ErrSymlinkLoop after
+// too many hops.
+func ResolveSymlink(path string) (string, error) {
+	for i := 0; i < maxSymlinkHops; i++ {
+		info, err := os.Lstat(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return path, nil
+			}
+			return "", err
+		}
+		if info.Mode()&os.ModeSymlink == 0 {
+			return path, nil
+		}
+
+		target, err := os.Readlink(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read symlink %s: %w", path, err)
+		}
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(filepath.Dir(path), target)
+		}
+		path = target
+	}
+	return "", fmt.Errorf("%w: %s", ErrSymlinkLoop, path)
+}
+
 // IsSymlink checks if a path is a symlink
 func IsSymlink(path string) bool {
 	info, err := os.Lstat(path)
@@ -0,0 +1,140 @@
+// Copyright (C) 2024-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+)
+
+// CompactReport describes what Compact changed in the registry
+type CompactReport struct {
+	// Reordered lists "org/name" keys whose version list was sorted or deduped
+	Reordered []string `json:"reordered,omitempty"`
+
+	// DroppedActive maps VMIDs removed from Active to the missing package
+	// reference they pointed at
+	DroppedActive map[string]string `json:"dropped_active,omitempty"`
+
+	// RemovedKeys lists "org/name" keys removed because they had no versions
+	RemovedKeys []string `json:"removed_keys,omitempty"`
+
+	// RemovedLinks lists the current/<vmid> symlinks removed along with
+	// dropped Active entries
+	RemovedLinks []string `json:"removed_links,omitempty"`
+}
+
+// Changed reports whether Compact modified the registry
+func (r *CompactReport) Changed() bool {
+	return len(r.Reordered) > 0 || len(r.DroppedActive) > 0 || len(r.RemovedKeys) > 0
+}
+
+// Compact normalizes the registry: each package's version list is sorted
+// by semver and deduplicated, Active entries whose package directory no
+// longer exists are dropped along with their current/<vmid> and aliases/
+// symlinks, and
+// package keys with no versions are removed. Packages on disk are left
+// alone. The registry is saved only if something changed.
+func (pm *PluginPackageManager) Compact() (*CompactReport, error) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	return pm.compact()
+}
+
+// compact implements Compact. The caller must hold pm.mu.
+func (pm *PluginPackageManager) compact() (*CompactReport, error) {
+	report := &CompactReport{}
+
+	keys := make([]string, 0, len(pm.registry.Plugins))
+	for key := range pm.registry.Plugins {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		versions := pm.registry.Plugins[key]
+		if len(versions) == 0 {
+			delete(pm.registry.Plugins, key)
+			report.RemovedKeys = append(report.RemovedKeys, key)
+			continue
+		}
+
+		compacted := compactVersions(versions)
+		if !equalStrings(compacted, versions) {
+			pm.registry.Plugins[key] = compacted
+			report.Reordered = append(report.Reordered, key)
+		}
+	}
+
+	for vmid, ref := range pm.registry.Active {
+		org, name, version, ok := splitPackageRef(ref)
+		if ok && Exists(pm.PackagePath(org, name, version)) {
+			continue
+		}
+		if report.DroppedActive == nil {
+			report.DroppedActive = make(map[string]string)
+		}
+		report.DroppedActive[vmid] = ref
+		delete(pm.registry.Active, vmid)
+		pm.unlinkAliases(func(owner string) bool { return owner == ref })
+	}
+
+	if !report.Changed() {
+		return report, nil
+	}
+	if err := pm.saveRegistry(); err != nil {
+		return nil, fmt.Errorf("failed to save compacted registry: %w", err)
+	}
+
+	// Remove the now-dangling VMID links
+	vmids := make([]string, 0, len(report.DroppedActive))
+	for vmid := range report.DroppedActive {
+		vmids = append(vmids, vmid)
+	}
+	sort.Strings(vmids)
+	for _, vmid := range vmids {
+		linkPath := pm.ActivePath(vmid)
+		info, err := os.Lstat(linkPath)
+		if err != nil || info.Mode()&os.ModeSymlink == 0 {
+			continue
+		}
+		if err := os.Remove(linkPath); err != nil {
+			return nil, fmt.Errorf("failed to remove symlink for %s: %w", vmid, err)
+		}
+		report.RemovedLinks = append(report.RemovedLinks, linkPath)
+	}
+	return report, nil
+}
+
+// compactVersions returns versions deduplicated and sorted by semver
+func compactVersions(versions []string) []string {
+	seen := make(map[string]bool, len(versions))
+	out := make([]string, 0, len(versions))
+	for _, v := range versions {
+		if seen[v] {
+			continue
+		}
+		seen[v] = true
+		out = append(out, v)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return CompareSemver(out[i], out[j]) < 0
+	})
+	return out
+}
+
+// equalStrings reports whether a and b hold the same strings in order
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,106 @@
+// Copyright (C) 2024-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package config
+
+import (
+	"context"
+	"debug/elf"
+	"debug/macho"
+	"debug/pe"
+	"errors"
+	"fmt"
+)
+
+// InstallDev links a locally built VM binary as org/name@version without a
+// hand-written manifest. The VMID is computed from vmName and the binary's
+// platform is detected from its executable header. Options such as
+// WithBuildInfo are passed through to Link.
+func (pm *PluginPackageManager) InstallDev(ctx context.Context, binaryPath, org, name, version, vmName string, opts ...InstallOption) error {
+	if vmName == "" {
+		return fmt.Errorf("vm name is required")
+	}
+
+	platform, err := DetectBinaryPlatform(binaryPath)
+	if err != nil {
+		return err
+	}
+
+	manifest := &PluginManifest{
+		Org:      org,
+		Name:     name,
+		Version:  version,
+		VMID:     VMID(vmName),
+		VMName:   vmName,
+		Platform: platform,
+	}
+	return pm.Link(ctx, manifest, binaryPath, opts...)
+}
+
+// elfArches maps ELF machine types to GOARCH names
+var elfArches = map[elf.Machine]string{
+	elf.EM_X86_64:  "amd64",
+	elf.EM_AARCH64: "arm64",
+	elf.EM_386:     "386",
+	elf.EM_ARM:     "arm",
+	elf.EM_RISCV:   "riscv64",
+	elf.EM_PPC64:   "ppc64le",
+	elf.EM_S390:    "s390x",
+}
+
+// machoArches maps Mach-O CPU types to GOARCH names
+var machoArches = map[macho.Cpu]string{
+	macho.CpuAmd64: "amd64",
+	macho.CpuArm64: "arm64",
+	macho.Cpu386:   "386",
+}
+
+// peArches maps PE machine types to GOARCH names
+var peArches = map[uint16]string{
+	pe.IMAGE_FILE_MACHINE_AMD64: "amd64",
+	pe.IMAGE_FILE_MACHINE_ARM64: "arm64",
+	pe.IMAGE_FILE_MACHINE_I386:  "386",
+}
+
+// DetectBinaryPlatform returns the os/arch an executable was built for by
+// reading its ELF, Mach-O, or PE header. Universal Mach-O binaries report
+// "darwin/universal".
+func DetectBinaryPlatform(path string) (string, error) {
+	if f, err := elf.Open(path); err == nil {
+		defer f.Close()
+		goos := "linux"
+		if f.OSABI == elf.ELFOSABI_FREEBSD {
+			goos = "freebsd"
+		}
+		return platformString(goos, elfArches[f.Machine], f.Machine.String())
+	}
+
+	if f, err := macho.Open(path); err == nil {
+		defer f.Close()
+		return platformString("darwin", machoArches[f.Cpu], f.Cpu.String())
+	}
+
+	if f, err := macho.OpenFat(path); err == nil {
+		f.Close()
+		return "darwin/universal", nil
+	} else if !errors.Is(err, macho.ErrNotFat) {
+		if _, ok := err.(*macho.FormatError); !ok {
+			return "", fmt.Errorf("failed to read binary: %w", err)
+		}
+	}
+
+	if f, err := pe.Open(path); err == nil {
+		defer f.Close()
+		return platformString("windows", peArches[f.Machine], fmt.Sprintf("machine 0x%x", f.Machine))
+	}
+
+	return "", fmt.Errorf("unrecognized executable format: %s", path)
+}
+
+// platformString joins goos and arch, failing when arch is unknown
+func platformString(goos, arch, raw string) (string, error) {
+	if arch == "" {
+		return "", fmt.Errorf("unsupported %s architecture: %s", goos, raw)
+	}
+	return goos + "/" + arch, nil
+}
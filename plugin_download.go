@@ -0,0 +1,247 @@
+// Copyright (C) 2024-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package config
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// downloadsDir holds partial downloads under the plugin base directory
+const downloadsDir = "downloads"
+
+// InstallFromURL downloads a plugin binary and installs it.
+// The download is written to a .part file under downloads/, next to a
+// .meta file recording the source URL and its ETag and Last-Modified. If a
+// previous attempt left a partial file for the same URL, the download
+// resumes with an HTTP Range request; it restarts from zero when the server
+// does not honor the range at the expected offset or the remote file
+// changed. Downloads are only resumed when checksum is given, since nothing
+// else would catch a corrupt join. The completed file must match checksum
+// (hex sha256) when one is given. The partial files are removed on success
+// and on permanent failure, but kept after transient errors so the next
+// call can resume.
+func (pm *PluginPackageManager) InstallFromURL(ctx context.Context, manifest *PluginManifest, url, checksum string, opts ...InstallOption) error {
+	manifest.normalizeRef()
+	if err := manifest.Validate(); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Join(pm.baseDir, downloadsDir), 0755); err != nil {
+		return fmt.Errorf("failed to create downloads directory: %w", err)
+	}
+	partPath := pm.partPath(manifest.Org, manifest.Name, manifest.Version)
+
+	if err := downloadFile(ctx, http.DefaultClient, url, partPath, checksum != ""); err != nil {
+		return err
+	}
+
+	if checksum != "" {
+		sum, err := fileSHA256(partPath)
+		if err != nil {
+			return fmt.Errorf("failed to checksum download: %w", err)
+		}
+		if !strings.EqualFold(sum, checksum) {
+			removePartial(partPath)
+			return fmt.Errorf("checksum mismatch for %s: got %s, want %s", url, sum, checksum)
+		}
+		manifest.Checksum = strings.ToLower(checksum)
+	}
+
+	if err := pm.Install(ctx, manifest, partPath, opts...); err != nil {
+		removePartial(partPath)
+		return err
+	}
+
+	_ = os.Remove(partMetaPath(partPath))
+	return os.Remove(partPath)
+}
+
+// partPath returns the partial download path for a package version
+func (pm *PluginPackageManager) partPath(org, name, version string) string {
+	return filepath.Join(pm.baseDir, downloadsDir, fmt.Sprintf("%s-%s-%s.part", org, name, version))
+}
+
+// partMeta identifies the source of a partial download, so it is only
+// resumed against the same remote file
+type partMeta struct {
+	URL          string `json:"url"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+	AcceptRanges string `json:"accept_ranges,omitempty"`
+}
+
+// partMetaPath returns the metadata path for a partial download
+func partMetaPath(path string) string {
+	return path + ".meta"
+}
+
+// readPartMeta reads the metadata of a partial download, if any
+func readPartMeta(path string) (*partMeta, bool) {
+	data, err := os.ReadFile(partMetaPath(path))
+	if err != nil {
+		return nil, false
+	}
+	var meta partMeta
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, false
+	}
+	return &meta, true
+}
+
+// removePartial removes a partial download and its metadata
+func removePartial(path string) {
+	_ = os.Remove(path)
+	_ = os.Remove(partMetaPath(path))
+}
+
+// downloadFile fetches url into path. With resume set, it continues from
+// the bytes already in path when they were fetched from the same url and
+// the server did not refuse ranges; otherwise any partial file is
+// discarded and the download starts over.
+func downloadFile(ctx context.Context, client *http.Client, url, path string, resume bool) error {
+	var offset int64
+	meta, ok := readPartMeta(path)
+	if resume && ok && meta.URL == url && !strings.EqualFold(meta.AcceptRanges, "none") {
+		if info, err := os.Stat(path); err == nil {
+			offset = info.Size()
+		}
+	}
+	if offset == 0 {
+		removePartial(path)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		// The server sends the whole file instead if it changed
+		if meta.ETag != "" {
+			req.Header.Set("If-Range", meta.ETag)
+		} else if meta.LastModified != "" {
+			req.Header.Set("If-Range", meta.LastModified)
+		}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	flags := os.O_WRONLY | os.O_CREATE
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		if offset == 0 {
+			removePartial(path)
+			return fmt.Errorf("failed to download %s: unexpected partial content", url)
+		}
+		if !contentRangeStartsAt(resp.Header.Get("Content-Range"), offset) || sourceChanged(meta, resp.Header) {
+			// Not the continuation of our partial file, start over
+			resp.Body.Close()
+			return downloadFile(ctx, client, url, path, false)
+		}
+		flags |= os.O_APPEND
+	case http.StatusOK:
+		// Full content, either a fresh download or the range was not honored
+		flags |= os.O_TRUNC
+		if err := writePartMeta(path, url, resp.Header); err != nil {
+			return err
+		}
+	case http.StatusRequestedRangeNotSatisfiable:
+		// Partial file is unusable (e.g. larger than the remote file)
+		removePartial(path)
+		if offset == 0 {
+			return fmt.Errorf("failed to download %s: unexpected status %s", url, resp.Status)
+		}
+		resp.Body.Close()
+		return downloadFile(ctx, client, url, path, false)
+	default:
+		removePartial(path)
+		return fmt.Errorf("failed to download %s: unexpected status %s", url, resp.Status)
+	}
+
+	f, err := os.OpenFile(path, flags, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open partial download: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		// Keep the partial file so the next attempt can resume
+		return fmt.Errorf("failed to download %s: %w", url, err)
+	}
+
+	return f.Sync()
+}
+
+// writePartMeta records the source of a download starting at path
+func writePartMeta(path, url string, header http.Header) error {
+	data, err := json.Marshal(partMeta{
+		URL:          url,
+		ETag:         header.Get("ETag"),
+		LastModified: header.Get("Last-Modified"),
+		AcceptRanges: header.Get("Accept-Ranges"),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal download metadata: %w", err)
+	}
+	if err := os.WriteFile(partMetaPath(path), data, 0644); err != nil {
+		return fmt.Errorf("failed to write download metadata: %w", err)
+	}
+	return nil
+}
+
+// contentRangeStartsAt reports whether a Content-Range header of the form
+// "bytes start-end/size" starts at offset
+func contentRangeStartsAt(contentRange string, offset int64) bool {
+	rest, ok := strings.CutPrefix(contentRange, "bytes ")
+	if !ok {
+		return false
+	}
+	start, _, ok := strings.Cut(rest, "-")
+	if !ok {
+		return false
+	}
+	n, err := strconv.ParseInt(strings.TrimSpace(start), 10, 64)
+	return err == nil && n == offset
+}
+
+// sourceChanged reports whether the validators in header differ from the
+// ones recorded when the partial download started
+func sourceChanged(meta *partMeta, header http.Header) bool {
+	if etag := header.Get("ETag"); meta.ETag != "" && etag != "" && etag != meta.ETag {
+		return true
+	}
+	if lm := header.Get("Last-Modified"); meta.LastModified != "" && lm != "" && lm != meta.LastModified {
+		return true
+	}
+	return false
+}
+
+// fileSHA256 returns the hex-encoded sha256 digest of a file
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
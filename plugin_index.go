@@ -0,0 +1,100 @@
+// Copyright (C) 2024-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package config
+
+import (
+	"context"
+	"fmt"
+)
+
+// pluginIndex maps VM names and VMIDs to installed package versions
+type pluginIndex struct {
+	byVMName map[string][]PluginManifest // VMName and aliases, in List order
+	byVMID   map[string][]PluginManifest
+}
+
+// lookupIndex returns the plugin index, building it if needed. The caller
+// must hold pm.mu.
+func (pm *PluginPackageManager) lookupIndex() (*pluginIndex, error) {
+	if pm.index != nil {
+		return pm.index, nil
+	}
+
+	manifests, err := pm.list(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	idx := &pluginIndex{
+		byVMName: make(map[string][]PluginManifest),
+		byVMID:   make(map[string][]PluginManifest),
+	}
+	for _, m := range manifests {
+		seen := make(map[string]bool)
+		for _, name := range append([]string{m.VMName}, m.Aliases...) {
+			if name == "" || seen[name] {
+				continue
+			}
+			seen[name] = true
+			idx.byVMName[name] = append(idx.byVMName[name], m)
+		}
+		idx.byVMID[m.VMID] = append(idx.byVMID[m.VMID], m)
+	}
+
+	pm.index = idx
+	return idx, nil
+}
+
+// FindByVMName returns every installed package version whose VMName or
+// aliases include vmName. When several orgs provide the same name, all are
+// returned, sorted by org, then name, then version (newest first).
+func (pm *PluginPackageManager) FindByVMName(vmName string) ([]PluginManifest, error) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	idx, err := pm.lookupIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	matches := idx.byVMName[vmName]
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrPluginNotFound, vmName)
+	}
+	return append([]PluginManifest(nil), matches...), nil
+}
+
+// FindByVMID returns the installed package for vmid, preferring the active
+// version and falling back to the newest installed one
+func (pm *PluginPackageManager) FindByVMID(vmid string) (*PluginManifest, error) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	idx, err := pm.lookupIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	matches := idx.byVMID[vmid]
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrPluginNotFound, vmid)
+	}
+
+	if ref, ok := pm.registry.Active[vmid]; ok {
+		for i := range matches {
+			m := matches[i]
+			if fmt.Sprintf("%s/%s@%s", m.Org, m.Name, m.Version) == ref {
+				return &m, nil
+			}
+		}
+	}
+
+	newest := matches[0]
+	for _, m := range matches[1:] {
+		if CompareSemver(m.Version, newest.Version) > 0 {
+			newest = m
+		}
+	}
+	return &newest, nil
+}
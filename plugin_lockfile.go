@@ -0,0 +1,275 @@
+// Copyright (C) 2024-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// LockfileVersion is the plugins.lock format version written by WriteLockfile
+const LockfileVersion = 1
+
+// PluginLockfile pins the active plugin set: the org/name@version that
+// must be active for each VMID
+type PluginLockfile struct {
+	Version int               `json:"version"`
+	Plugins map[string]string `json:"plugins"` // VMID -> org/name@version
+}
+
+// ReadLockfile reads and validates a lockfile written by WriteLockfile
+func ReadLockfile(path string) (*PluginLockfile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lockfile: %w", err)
+	}
+	lock := &PluginLockfile{}
+	if err := json.Unmarshal(data, lock); err != nil {
+		return nil, fmt.Errorf("failed to parse lockfile: %w", err)
+	}
+	if lock.Version > LockfileVersion {
+		return nil, fmt.Errorf("lockfile version %d is newer than supported version %d", lock.Version, LockfileVersion)
+	}
+
+	pkgs := make(map[string]string, len(lock.Plugins))
+	for vmid, ref := range lock.Plugins {
+		if err := ValidateVMID(vmid); err != nil {
+			return nil, fmt.Errorf("invalid lockfile entry: %w", err)
+		}
+		org, name, version, ok := splitPackageRef(ref)
+		if !ok || version == "" {
+			return nil, fmt.Errorf("invalid lockfile entry for %s: %q is not org/name@version", vmid, ref)
+		}
+		if err := ValidatePackageRef(org, name); err != nil {
+			return nil, fmt.Errorf("invalid lockfile entry for %s: %w", vmid, err)
+		}
+		if other, dup := pkgs[pkgKeyOf(ref)]; dup {
+			return nil, fmt.Errorf("invalid lockfile: %s is locked for vmids %s and %s", pkgKeyOf(ref), other, vmid)
+		}
+		pkgs[pkgKeyOf(ref)] = vmid
+	}
+	return lock, nil
+}
+
+// WriteLockfile records the current active set to path
+func (pm *PluginPackageManager) WriteLockfile(path string) error {
+	pm.mu.Lock()
+	lock := &PluginLockfile{Version: LockfileVersion, Plugins: make(map[string]string, len(pm.registry.Active))}
+	for vmid, ref := range pm.registry.Active {
+		lock.Plugins[vmid] = ref
+	}
+	pm.mu.Unlock()
+
+	data, err := json.MarshalIndent(lock, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal lockfile: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create lockfile directory: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write lockfile: %w", err)
+	}
+	return nil
+}
+
+// LockfileActionType is the kind of change in a lockfile plan
+type LockfileActionType string
+
+const (
+	// LockfileInstall downloads a locked version from the remote index
+	LockfileInstall LockfileActionType = "install"
+
+	// LockfileActivate makes a locked version active for its VMID
+	LockfileActivate LockfileActionType = "activate"
+
+	// LockfileDeactivate removes an active VMID that is not in the lockfile
+	LockfileDeactivate LockfileActionType = "deactivate"
+)
+
+// LockfileAction is one change ApplyLockfile makes
+type LockfileAction struct {
+	Type    LockfileActionType `json:"type"`
+	VMID    string             `json:"vmid"`
+	Ref     string             `json:"ref"`               // Locked org/name@version; the active one for deactivate
+	Current string             `json:"current,omitempty"` // Version active for VMID before the change, if any
+
+	release *RemoteRelease // Source of an install
+}
+
+// String describes the action, e.g. "activate luxfi/evm@v1.0.0 (was luxfi/evm@v1.1.0)"
+func (a LockfileAction) String() string {
+	if a.Current != "" && a.Type == LockfileActivate {
+		return fmt.Sprintf("%s %s (was %s)", a.Type, a.Ref, a.Current)
+	}
+	return fmt.Sprintf("%s %s", a.Type, a.Ref)
+}
+
+// PlanLockfile returns the changes ApplyLockfile would make for the
+// lockfile at path, without making them: installs of locked versions
+// missing locally, then activations of locked versions not already active,
+// each sorted by VMID, then deactivations of VMIDs not in the lockfile.
+// A locked version that is neither installed nor in the remote index is an
+// error wrapping ErrPluginNotFound, as is a lockfile entry whose package
+// declares a different VMID.
+func (pm *PluginPackageManager) PlanLockfile(path string) ([]LockfileAction, error) {
+	lock, err := ReadLockfile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	return pm.planLockfile(lock)
+}
+
+// planLockfile implements PlanLockfile. The caller must hold pm.mu.
+func (pm *PluginPackageManager) planLockfile(lock *PluginLockfile) ([]LockfileAction, error) {
+	vmids := make([]string, 0, len(lock.Plugins))
+	for vmid := range lock.Plugins {
+		vmids = append(vmids, vmid)
+	}
+	sort.Strings(vmids)
+
+	var installs, activations, deactivations []LockfileAction
+	var missing []string
+	for _, vmid := range vmids {
+		ref := lock.Plugins[vmid]
+		org, name, version, _ := splitPackageRef(ref)
+		current := pm.registry.Active[vmid]
+
+		if contains(pm.registry.Plugins[pkgKeyOf(ref)], version) {
+			manifest, err := pm.getManifest(org, name, version)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load manifest for %s: %w", ref, err)
+			}
+			if manifest.VMID != vmid {
+				return nil, fmt.Errorf("lockfile maps %s to %s, whose vmid is %s", vmid, ref, manifest.VMID)
+			}
+		} else {
+			if pm.remoteIndex == nil {
+				missing = append(missing, ref)
+				continue
+			}
+			release, err := pm.remoteIndex.Resolve(org, name, version)
+			if err != nil {
+				return nil, err
+			}
+			if release.VMID != vmid {
+				return nil, fmt.Errorf("lockfile maps %s to %s, but the index lists vmid %s", vmid, ref, release.VMID)
+			}
+			installs = append(installs, LockfileAction{Type: LockfileInstall, VMID: vmid, Ref: ref, release: release})
+		}
+
+		if current != ref {
+			activations = append(activations, LockfileAction{Type: LockfileActivate, VMID: vmid, Ref: ref, Current: current})
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%w: %s not installed and no remote index is configured", ErrPluginNotFound, strings.Join(missing, ", "))
+	}
+
+	for vmid, ref := range pm.registry.Active {
+		if _, ok := lock.Plugins[vmid]; !ok {
+			deactivations = append(deactivations, LockfileAction{Type: LockfileDeactivate, VMID: vmid, Ref: ref, Current: ref})
+		}
+	}
+	sort.Slice(deactivations, func(i, j int) bool { return deactivations[i].VMID < deactivations[j].VMID })
+
+	return append(append(installs, activations...), deactivations...), nil
+}
+
+// ApplyLockfile makes the active set match the lockfile at path by
+// carrying out the plan returned by PlanLockfile: each locked version is
+// activated for its VMID and every other VMID is deactivated. Locked
+// versions that are not installed are downloaded from the remote index
+// given with WithRemoteIndex; without one they are an error wrapping
+// ErrPluginNotFound, reported before anything changes. Activations and
+// deactivations are one transaction: if any fails, those already made are
+// rolled back and the previous active set is restored.
+func (pm *PluginPackageManager) ApplyLockfile(ctx context.Context, path string) error {
+	plan, err := pm.PlanLockfile(path)
+	if err != nil {
+		return err
+	}
+
+	// Install missing versions first; InstallFromURL takes pm.mu itself
+	for _, action := range plan {
+		if action.Type != LockfileInstall {
+			continue
+		}
+		if err := pm.InstallFromURL(ctx, action.release.Manifest(), action.release.URL, action.release.Checksum); err != nil {
+			return fmt.Errorf("failed to install %s: %w", action.Ref, err)
+		}
+	}
+
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	var applied []activeChange
+	for _, action := range plan {
+		err := ctx.Err()
+		if err == nil {
+			switch action.Type {
+			case LockfileActivate:
+				// Record the change before activating: a failed activate may
+				// have already replaced the VMID symlink
+				applied = append(applied, activeChange{
+					ref:       action.Ref,
+					vmid:      action.VMID,
+					prev:      pm.activeRefOf(pkgKeyOf(action.Ref)),
+					prevOwner: pm.registry.Active[action.VMID],
+				})
+				org, name, version, _ := splitPackageRef(action.Ref)
+				if err = pm.activate(ctx, org, name, version); err != nil {
+					err = fmt.Errorf("failed to activate %s: %w", action.Ref, err)
+				}
+			case LockfileDeactivate:
+				// Nothing changes when removing the symlink fails
+				if err = pm.deactivateVMID(action.VMID); err == nil {
+					applied = append(applied, activeChange{vmid: action.VMID, prev: action.Ref})
+				}
+			default:
+				continue
+			}
+		}
+		if err != nil {
+			if rbErr := pm.rollbackActivations(applied); rbErr != nil {
+				return errors.Join(err, fmt.Errorf("rollback failed: %w", rbErr))
+			}
+			return err
+		}
+	}
+	return pm.saveRegistry()
+}
+
+// activeRefOf returns the active org/name@version of pkgKey, or "". The
+// caller must hold pm.mu.
+func (pm *PluginPackageManager) activeRefOf(pkgKey string) string {
+	for _, ref := range pm.registry.Active {
+		if pkgKeyOf(ref) == pkgKey {
+			return ref
+		}
+	}
+	return ""
+}
+
+// deactivateVMID removes vmid from the active set along with its symlink
+// and the aliases of its package. The caller must hold pm.mu.
+func (pm *PluginPackageManager) deactivateVMID(vmid string) error {
+	ref := pm.registry.Active[vmid]
+	if err := os.Remove(pm.ActivePath(vmid)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove VMID symlink %s: %w", vmid, err)
+	}
+	pm.unlinkAliases(func(owner string) bool { return owner == ref })
+	delete(pm.registry.Active, vmid)
+	return nil
+}
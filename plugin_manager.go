@@ -4,12 +4,18 @@
 package config
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -28,11 +34,15 @@ import (
 // │       └── myvm/
 // ├── current/                           # VMID symlinks (what node uses)
 // │   └── ag3GReYPNuSR... -> ../packages/luxfi/evm/v1.0.0/evm
+// ├── aliases/                           # Name and alias symlinks (human lookups)
+// │   └── evm -> ../packages/luxfi/evm/v1.0.0/evm
 // └── registry.json                      # Local registry of installed packages
 
 const (
 	packagesDir  = "packages"
 	activeDir    = "current" // Symlinks by VMID for node compatibility (unified with SDK constants.CurrentPluginDir)
+	aliasesDir   = "aliases" // Symlinks by package name and manifest aliases
+	trashDir     = "trash"   // Uninstalled packages kept for RestoreTrashed
 	registryFile = "registry.json"
 )
 
@@ -70,28 +80,257 @@ type PluginManifest struct {
 
 	// Size is the binary size in bytes
 	Size int64 `json:"size,omitempty"`
+
+	// Checksum is the hex-encoded sha256 of the binary, when known
+	Checksum string `json:"checksum,omitempty"`
+
+	// Env holds environment variables the node sets when launching the VM
+	Env map[string]string `json:"env,omitempty"`
+
+	// Platform is the os/arch the binary was built for (e.g., "linux/amd64")
+	Platform string `json:"platform,omitempty"`
+
+	// BuildInfo records who built the binary and from what, for auditing
+	BuildInfo
+}
+
+// BuildInfo describes how a plugin binary was built. The fields are
+// informational and are not verified against the binary.
+type BuildInfo struct {
+	// BuiltBy identifies the builder (e.g., a CI job or user)
+	BuiltBy string `json:"built_by,omitempty"`
+
+	// BuildCommit is the source revision the binary was built from
+	BuildCommit string `json:"build_commit,omitempty"`
+
+	// BuildTime is when the binary was built
+	BuildTime time.Time `json:"build_time,omitzero"`
+
+	// ToolVersion is the version of the toolchain that built the binary
+	ToolVersion string `json:"tool_version,omitempty"`
+}
+
+// shortCommitLen is the number of commit characters shown in listings
+const shortCommitLen = 7
+
+// ShortCommit returns the abbreviated build commit, or "" if unknown
+func (b BuildInfo) ShortCommit() string {
+	if len(b.BuildCommit) <= shortCommitLen {
+		return b.BuildCommit
+	}
+	return b.BuildCommit[:shortCommitLen]
+}
+
+// envNamePattern matches valid environment variable names
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// packageNamePattern matches valid org and package names: lowercase
+// alphanumerics and inner hyphens
+var packageNamePattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?$`)
+
+// ValidatePackageRef checks that org and name are usable as directory names
+// and registry keys: lowercase alphanumerics and hyphens, not starting or
+// ending with a hyphen. Separators such as "/" are rejected since the
+// registry keys packages as org/name.
+func ValidatePackageRef(org, name string) error {
+	if !packageNamePattern.MatchString(org) {
+		return fmt.Errorf("invalid org %q: must be lowercase alphanumerics and hyphens", org)
+	}
+	if !packageNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid package name %q: must be lowercase alphanumerics and hyphens", name)
+	}
+	return nil
+}
+
+// NormalizePackageRef trims and lowercases org and name, then validates them
+// with ValidatePackageRef
+func NormalizePackageRef(org, name string) (string, string, error) {
+	org, name = normalizePackageName(org), normalizePackageName(name)
+	if err := ValidatePackageRef(org, name); err != nil {
+		return "", "", err
+	}
+	return org, name, nil
+}
+
+// normalizeRef trims and lowercases Org and Name so that, e.g., "LuxFi"
+// installs as "luxfi"
+func (m *PluginManifest) normalizeRef() {
+	m.Org = normalizePackageName(m.Org)
+	m.Name = normalizePackageName(m.Name)
+}
+
+// normalizePackageName trims and lowercases an org or package name
+func normalizePackageName(s string) string {
+	return strings.ToLower(strings.TrimSpace(s))
+}
+
+// Validate checks that the manifest is complete and self-consistent:
+// org, name, version, and vmid are set, org and name pass
+// ValidatePackageRef, the version is valid semver, the vmid matches
+// VMID(VMName) when VMName is set, the binary name is a plain file name,
+// aliases are unique, and env keys are valid variable names.
+func (m *PluginManifest) Validate() error {
+	if m.Org == "" || m.Name == "" || m.Version == "" {
+		return fmt.Errorf("manifest must have org, name, and version")
+	}
+	if err := ValidatePackageRef(m.Org, m.Name); err != nil {
+		return fmt.Errorf("manifest %w", err)
+	}
+	if !IsValidSemver(m.Version) {
+		return fmt.Errorf("manifest version %q is not valid semver", m.Version)
+	}
+	if m.VMID == "" {
+		return fmt.Errorf("manifest must have vmid")
+	}
+	if err := ValidateVMID(m.VMID); err != nil {
+		return fmt.Errorf("manifest %w", err)
+	}
+	if m.VMName != "" {
+		if want := VMID(m.VMName); m.VMID != want {
+			return fmt.Errorf("manifest vmid %s does not match vm name %q (want %s)", m.VMID, m.VMName, want)
+		}
+	}
+	if m.Binary != "" && (strings.ContainsAny(m.Binary, `/\`) || m.Binary == "." || m.Binary == "..") {
+		return fmt.Errorf("manifest binary %q must be a file name, not a path", m.Binary)
+	}
+
+	seen := make(map[string]bool, len(m.Aliases))
+	for _, alias := range m.Aliases {
+		if seen[alias] {
+			return fmt.Errorf("manifest alias %q is duplicated", alias)
+		}
+		seen[alias] = true
+	}
+
+	for key := range m.Env {
+		if !envNamePattern.MatchString(key) {
+			return fmt.Errorf("manifest env key %q is not a valid environment variable name", key)
+		}
+	}
+
+	return nil
 }
 
+// RegistrySchemaVersion is the newest registry format this package can write
+const RegistrySchemaVersion = 1
+
 // PluginRegistry tracks all installed plugins
 type PluginRegistry struct {
+	// SchemaVersion is the registry format version
+	SchemaVersion int `json:"schema_version"`
+
 	// Plugins maps "org/name" to list of installed versions
 	Plugins map[string][]string `json:"plugins"`
 
 	// Active maps VMID to active package reference
 	Active map[string]string `json:"active"`
 
+	// Aliases maps an alias symlink name to the package reference that owns it
+	Aliases map[string]string `json:"aliases,omitempty"`
+
+	// Pinned maps "org/name" to a version that upgrades must not move off
+	Pinned map[string]string `json:"pinned,omitempty"`
+
 	// UpdatedAt is when the registry was last modified
 	UpdatedAt time.Time `json:"updated_at"`
+
+	// extra holds fields written by newer versions, re-emitted on save
+	extra map[string]json.RawMessage
+}
+
+// registryFields are the JSON keys PluginRegistry understands
+var registryFields = []string{"schema_version", "plugins", "active", "aliases", "pinned", "updated_at"}
+
+// pluginRegistryJSON avoids recursion in the custom (un)marshalers
+type pluginRegistryJSON PluginRegistry
+
+// UnmarshalJSON decodes the registry, keeping unknown fields so they
+// survive a load/save round trip
+func (r *PluginRegistry) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, (*pluginRegistryJSON)(r)); err != nil {
+		return err
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	for _, key := range registryFields {
+		delete(raw, key)
+	}
+	r.extra = nil
+	if len(raw) > 0 {
+		r.extra = raw
+	}
+	return nil
+}
+
+// MarshalJSON encodes the registry along with any preserved unknown fields
+func (r PluginRegistry) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(pluginRegistryJSON(r))
+	if err != nil || len(r.extra) == 0 {
+		return data, err
+	}
+
+	var merged map[string]json.RawMessage
+	if err := json.Unmarshal(data, &merged); err != nil {
+		return nil, err
+	}
+	for key, value := range r.extra {
+		if _, ok := merged[key]; !ok {
+			merged[key] = value
+		}
+	}
+	return json.Marshal(merged)
 }
 
-// PluginPackageManager provides proper package manager functionality
+// PluginPackageManager provides proper package manager functionality.
+// It is safe for concurrent use by multiple goroutines.
 type PluginPackageManager struct {
-	baseDir  string
-	registry *PluginRegistry
+	baseDir string
+
+	mu        sync.Mutex // Guards registry, index, and manifests
+	registry  *PluginRegistry
+	index     *pluginIndex               // Built lazily, reset whenever the registry changes
+	manifests map[string]*PluginManifest // Cache for GetManifest, keyed by manifestKey
+
+	compactOnLoad bool
+	relativeLinks bool         // Create VMID and alias symlinks with relative targets
+	remoteIndex   *RemoteIndex // Source for versions missing locally, see ApplyLockfile
+}
+
+// PackageManagerOption configures a PluginPackageManager
+type PackageManagerOption func(*PluginPackageManager)
+
+// WithCompactOnLoad compacts the registry (see Compact) right after it is
+// loaded, saving it only if anything changed
+func WithCompactOnLoad() PackageManagerOption {
+	return func(pm *PluginPackageManager) {
+		pm.compactOnLoad = true
+	}
+}
+
+// WithRelativeSymlinks makes VMID and alias symlinks point at package
+// binaries with targets relative to the link's directory, so the base
+// directory can be moved without RelinkAll. The "latest" symlinks are
+// always relative. Targets outside the base directory, such as the source
+// binary of a linked package, stay absolute.
+func WithRelativeSymlinks() PackageManagerOption {
+	return func(pm *PluginPackageManager) {
+		pm.relativeLinks = true
+	}
+}
+
+// WithRemoteIndex lets ApplyLockfile download locked versions that are
+// not installed from the releases listed in idx
+func WithRemoteIndex(idx *RemoteIndex) PackageManagerOption {
+	return func(pm *PluginPackageManager) {
+		pm.remoteIndex = idx
+	}
 }
 
 // NewPluginPackageManager creates a new package manager
-func NewPluginPackageManager(baseDir string) (*PluginPackageManager, error) {
+func NewPluginPackageManager(baseDir string, opts ...PackageManagerOption) (*PluginPackageManager, error) {
 	if baseDir == "" {
 		baseDir = ResolvePluginBaseDir()
 	}
@@ -99,6 +338,9 @@ func NewPluginPackageManager(baseDir string) (*PluginPackageManager, error) {
 	pm := &PluginPackageManager{
 		baseDir: baseDir,
 	}
+	for _, opt := range opts {
+		opt(pm)
+	}
 
 	// Ensure directory structure exists
 	if err := pm.ensureDirectories(); err != nil {
@@ -109,6 +351,11 @@ func NewPluginPackageManager(baseDir string) (*PluginPackageManager, error) {
 	if err := pm.loadRegistry(); err != nil {
 		return nil, err
 	}
+	if pm.compactOnLoad {
+		if _, err := pm.compact(); err != nil {
+			return nil, err
+		}
+	}
 
 	return pm, nil
 }
@@ -132,14 +379,18 @@ func (pm *PluginPackageManager) ensureDirectories() error {
 
 // loadRegistry loads or creates the plugin registry
 func (pm *PluginPackageManager) loadRegistry() error {
+	pm.index = nil
 	registryPath := filepath.Join(pm.baseDir, registryFile)
 	data, err := os.ReadFile(registryPath)
 	if err != nil {
 		if os.IsNotExist(err) {
 			pm.registry = &PluginRegistry{
-				Plugins:   make(map[string][]string),
-				Active:    make(map[string]string),
-				UpdatedAt: time.Now(),
+				SchemaVersion: RegistrySchemaVersion,
+				Plugins:       make(map[string][]string),
+				Active:        make(map[string]string),
+				Aliases:       make(map[string]string),
+				Pinned:        make(map[string]string),
+				UpdatedAt:     time.Now(),
 			}
 			return nil
 		}
@@ -150,12 +401,34 @@ func (pm *PluginPackageManager) loadRegistry() error {
 	if err := json.Unmarshal(data, pm.registry); err != nil {
 		return fmt.Errorf("failed to parse registry: %w", err)
 	}
+	if pm.registry.Plugins == nil {
+		pm.registry.Plugins = make(map[string][]string)
+	}
+	if pm.registry.Active == nil {
+		pm.registry.Active = make(map[string]string)
+	}
+	if pm.registry.Aliases == nil {
+		pm.registry.Aliases = make(map[string]string)
+	}
+	if pm.registry.Pinned == nil {
+		pm.registry.Pinned = make(map[string]string)
+	}
 
 	return nil
 }
 
-// saveRegistry persists the registry to disk
+// saveRegistry persists the registry to disk.
+// A registry written by a newer schema is never overwritten, since this
+// version cannot know which of its invariants a save would break.
 func (pm *PluginPackageManager) saveRegistry() error {
+	if pm.registry.SchemaVersion > RegistrySchemaVersion {
+		return fmt.Errorf("registry schema version %d is newer than supported version %d; upgrade this tool",
+			pm.registry.SchemaVersion, RegistrySchemaVersion)
+	}
+	pm.index = nil
+	if pm.registry.SchemaVersion == 0 {
+		pm.registry.SchemaVersion = RegistrySchemaVersion
+	}
 	pm.registry.UpdatedAt = time.Now()
 
 	data, err := json.MarshalIndent(pm.registry, "", "  ")
@@ -176,23 +449,173 @@ func (pm *PluginPackageManager) PackagePath(org, name, version string) string {
 	return filepath.Join(pm.baseDir, packagesDir, org, name, version)
 }
 
-// ActivePath returns the path for VMID symlinks (node compatibility)
+// ActivePath returns the path for VMID symlinks (node compatibility).
+// vmid is not validated here; Install, Link, and Activate reject malformed
+// VMIDs with ValidateVMID before creating the symlink.
 func (pm *PluginPackageManager) ActivePath(vmid string) string {
 	return filepath.Join(pm.baseDir, activeDir, vmid)
 }
 
+// AliasPath returns the path for a name or alias symlink
+func (pm *PluginPackageManager) AliasPath(alias string) string {
+	return filepath.Join(pm.baseDir, aliasesDir, alias)
+}
+
+// ErrVMIDConflict is returned when a package's VMID is already active for a
+// different org/name
+var ErrVMIDConflict = errors.New("vmid already provided by another package")
+
+// ErrInvalidPluginBinary is returned when Install is given a file that is
+// too small or not an executable, e.g. an HTML error page from a download
+var ErrInvalidPluginBinary = errors.New("invalid plugin binary")
+
+// MinPluginBinarySize is the smallest file Install accepts as a binary
+const MinPluginBinarySize = 64
+
+// executableMagics are the leading bytes of ELF, Mach-O (32/64-bit, both
+// byte orders, and universal), and PE executables
+var executableMagics = [][]byte{
+	[]byte("\x7fELF"),
+	{0xfe, 0xed, 0xfa, 0xce}, {0xce, 0xfa, 0xed, 0xfe},
+	{0xfe, 0xed, 0xfa, 0xcf}, {0xcf, 0xfa, 0xed, 0xfe},
+	{0xca, 0xfe, 0xba, 0xbe},
+	[]byte("MZ"),
+}
+
+// checkPluginBinary fails unless path is at least MinPluginBinarySize bytes
+// and, when checkFormat is set, starts with an executable magic number
+func checkPluginBinary(path string, checkFormat bool) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open binary: %w", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat binary: %w", err)
+	}
+	if info.Size() < MinPluginBinarySize {
+		return fmt.Errorf("%w: %s is %d bytes, below the %d byte minimum",
+			ErrInvalidPluginBinary, path, info.Size(), MinPluginBinarySize)
+	}
+	if !checkFormat {
+		return nil
+	}
+
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(f, header); err != nil {
+		return fmt.Errorf("failed to read binary: %w", err)
+	}
+	for _, magic := range executableMagics {
+		if bytes.HasPrefix(header, magic) {
+			return nil
+		}
+	}
+	if trimmed := bytes.TrimSpace(header); len(trimmed) > 0 && trimmed[0] == '<' {
+		return fmt.Errorf("%w: %s looks like an HTML or XML page, not an executable", ErrInvalidPluginBinary, path)
+	}
+	return fmt.Errorf("%w: %s is not an ELF, Mach-O, or PE executable", ErrInvalidPluginBinary, path)
+}
+
+// installOptions holds settings for Install and Link
+type installOptions struct {
+	allowVMIDOverride bool
+	immutable         bool
+	skipFormatCheck   bool
+	buildInfo         *BuildInfo
+}
+
+// InstallOption is a functional option for Install and Link
+type InstallOption func(*installOptions)
+
+// WithVMIDOverride lets Install and Link take over a VMID that is active
+// for a different package
+func WithVMIDOverride() InstallOption {
+	return func(o *installOptions) {
+		o.allowVMIDOverride = true
+	}
+}
+
+// WithImmutable makes Install leave the package read-only: the binary is
+// set to 0555, the manifest to 0444, and the package directory to 0555.
+// The filesystem immutable attribute is not set; use chattr for that.
+// Link does not support immutability, since it points at a binary this
+// package manager does not own.
+func WithImmutable() InstallOption {
+	return func(o *installOptions) {
+		o.immutable = true
+	}
+}
+
+// WithoutFormatCheck lets Install accept a binary that is not an ELF,
+// Mach-O, or PE executable, for exotic plugin types. The minimum size check
+// still applies.
+func WithoutFormatCheck() InstallOption {
+	return func(o *installOptions) {
+		o.skipFormatCheck = true
+	}
+}
+
+// WithBuildInfo records info in the manifest written by Install and Link,
+// replacing any build info already set on the manifest
+func WithBuildInfo(info BuildInfo) InstallOption {
+	return func(o *installOptions) {
+		o.buildInfo = &info
+	}
+}
+
+// applyInstallOptions builds installOptions from opts
+func applyInstallOptions(opts []InstallOption) installOptions {
+	var o installOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// checkVMIDConflict fails if the manifest's VMID is active for a different
+// org/name, naming the package that holds it
+func (pm *PluginPackageManager) checkVMIDConflict(manifest *PluginManifest) error {
+	ref, ok := pm.registry.Active[manifest.VMID]
+	if !ok || pkgKeyOf(ref) == manifest.Org+"/"+manifest.Name {
+		return nil
+	}
+	return fmt.Errorf("%w: %s is active as %s", ErrVMIDConflict, manifest.VMID, ref)
+}
+
 // Install installs a plugin from a binary path
-func (pm *PluginPackageManager) Install(ctx context.Context, manifest *PluginManifest, binaryPath string) error {
+func (pm *PluginPackageManager) Install(ctx context.Context, manifest *PluginManifest, binaryPath string, opts ...InstallOption) error {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	return pm.install(ctx, manifest, binaryPath, opts...)
+}
+
+// install implements Install; the caller must hold pm.mu
+func (pm *PluginPackageManager) install(ctx context.Context, manifest *PluginManifest, binaryPath string, opts ...InstallOption) error {
+	options := applyInstallOptions(opts)
+	manifest.normalizeRef()
+
 	// Validate manifest
-	if manifest.Org == "" || manifest.Name == "" || manifest.Version == "" {
-		return fmt.Errorf("manifest must have org, name, and version")
+	if err := manifest.Validate(); err != nil {
+		return err
 	}
-	if manifest.VMID == "" {
-		return fmt.Errorf("manifest must have vmid")
+	if !options.allowVMIDOverride {
+		if err := pm.checkVMIDConflict(manifest); err != nil {
+			return err
+		}
+	}
+	if err := checkPluginBinary(binaryPath, !options.skipFormatCheck); err != nil {
+		return err
 	}
 
-	// Create package directory
+	// Create package directory, unlocking a previous immutable install
 	pkgPath := pm.PackagePath(manifest.Org, manifest.Name, manifest.Version)
+	pm.invalidateManifest(manifest.Org, manifest.Name, manifest.Version)
+	if err := makeWritable(pkgPath); err != nil {
+		return fmt.Errorf("failed to unlock package directory: %w", err)
+	}
 	if err := os.MkdirAll(pkgPath, 0755); err != nil {
 		return fmt.Errorf("failed to create package directory: %w", err)
 	}
@@ -220,6 +643,9 @@ func (pm *PluginPackageManager) Install(ctx context.Context, manifest *PluginMan
 		manifest.Size = info.Size()
 	}
 	manifest.InstalledAt = time.Now()
+	if options.buildInfo != nil {
+		manifest.BuildInfo = *options.buildInfo
+	}
 
 	// Write manifest
 	manifestPath := filepath.Join(pkgPath, "manifest.json")
@@ -231,6 +657,13 @@ func (pm *PluginPackageManager) Install(ctx context.Context, manifest *PluginMan
 		return fmt.Errorf("failed to write manifest: %w", err)
 	}
 
+	// Lock down the package for hardened deployments
+	if options.immutable {
+		if err := lockPackage(pkgPath, destBinaryPath, manifestPath); err != nil {
+			return fmt.Errorf("failed to make package immutable: %w", err)
+		}
+	}
+
 	// Update registry
 	pkgKey := fmt.Sprintf("%s/%s", manifest.Org, manifest.Name)
 	versions := pm.registry.Plugins[pkgKey]
@@ -239,7 +672,7 @@ func (pm *PluginPackageManager) Install(ctx context.Context, manifest *PluginMan
 	}
 
 	// Activate this version (create VMID symlink)
-	if err := pm.Activate(ctx, manifest.Org, manifest.Name, manifest.Version); err != nil {
+	if err := pm.activate(ctx, manifest.Org, manifest.Name, manifest.Version); err != nil {
 		return fmt.Errorf("failed to activate plugin: %w", err)
 	}
 
@@ -256,13 +689,24 @@ func (pm *PluginPackageManager) Install(ctx context.Context, manifest *PluginMan
 
 // Link creates a symlink-based installation (for development)
 // Unlike Install which copies the binary, Link creates a symlink to the source
-func (pm *PluginPackageManager) Link(ctx context.Context, manifest *PluginManifest, binaryPath string) error {
+func (pm *PluginPackageManager) Link(ctx context.Context, manifest *PluginManifest, binaryPath string, opts ...InstallOption) error {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	options := applyInstallOptions(opts)
+	manifest.normalizeRef()
+	if options.immutable {
+		return fmt.Errorf("linked packages cannot be immutable")
+	}
+
 	// Validate manifest
-	if manifest.Org == "" || manifest.Name == "" || manifest.Version == "" {
-		return fmt.Errorf("manifest must have org, name, and version")
+	if err := manifest.Validate(); err != nil {
+		return err
 	}
-	if manifest.VMID == "" {
-		return fmt.Errorf("manifest must have vmid")
+	if !options.allowVMIDOverride {
+		if err := pm.checkVMIDConflict(manifest); err != nil {
+			return err
+		}
 	}
 
 	// Resolve binary path to absolute
@@ -278,9 +722,16 @@ func (pm *PluginPackageManager) Link(ctx context.Context, manifest *PluginManife
 	}
 	manifest.Size = info.Size()
 	manifest.InstalledAt = time.Now()
+	if options.buildInfo != nil {
+		manifest.BuildInfo = *options.buildInfo
+	}
 
-	// Create package directory
+	// Create package directory, unlocking an installed package it replaces
 	pkgPath := pm.PackagePath(manifest.Org, manifest.Name, manifest.Version)
+	pm.invalidateManifest(manifest.Org, manifest.Name, manifest.Version)
+	if err := makeWritable(pkgPath); err != nil {
+		return fmt.Errorf("failed to unlock existing package: %w", err)
+	}
 	if err := os.MkdirAll(pkgPath, 0755); err != nil {
 		return fmt.Errorf("failed to create package directory: %w", err)
 	}
@@ -334,6 +785,11 @@ func (pm *PluginPackageManager) Link(ctx context.Context, manifest *PluginManife
 	// Update registry
 	pm.registry.Active[manifest.VMID] = fmt.Sprintf("%s/%s@%s", manifest.Org, manifest.Name, manifest.Version)
 
+	// Point name and alias symlinks at the linked binary
+	if err := pm.linkAliases(manifest, absBinaryPath); err != nil {
+		return err
+	}
+
 	// Create "latest" symlink
 	latestPath := filepath.Join(pm.baseDir, packagesDir, manifest.Org, manifest.Name, "latest")
 	_ = os.Remove(latestPath)
@@ -344,11 +800,22 @@ func (pm *PluginPackageManager) Link(ctx context.Context, manifest *PluginManife
 
 // Activate creates the VMID symlink for a specific version
 func (pm *PluginPackageManager) Activate(ctx context.Context, org, name, version string) error {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	return pm.activate(ctx, org, name, version)
+}
+
+// activate implements Activate; the caller must hold pm.mu
+func (pm *PluginPackageManager) activate(ctx context.Context, org, name, version string) error {
 	// Load manifest to get VMID
-	manifest, err := pm.GetManifest(org, name, version)
+	manifest, err := pm.getManifest(org, name, version)
 	if err != nil {
 		return fmt.Errorf("failed to load manifest: %w", err)
 	}
+	if err := ValidateVMID(manifest.VMID); err != nil {
+		return fmt.Errorf("manifest %w", err)
+	}
 
 	// Binary path
 	binaryName := manifest.Binary
@@ -368,37 +835,101 @@ func (pm *PluginPackageManager) Activate(ctx context.Context, org, name, version
 	}
 
 	// Create new symlink
-	if err := os.Symlink(binaryPath, vmidPath); err != nil {
+	if err := os.Symlink(pm.linkTarget(vmidPath, binaryPath), vmidPath); err != nil {
 		return fmt.Errorf("failed to create VMID symlink: %w", err)
 	}
 
 	// Update registry
 	pm.registry.Active[manifest.VMID] = fmt.Sprintf("%s/%s@%s", org, name, version)
 
+	// Point name and alias symlinks at the activated binary
+	if err := pm.linkAliases(manifest, binaryPath); err != nil {
+		return err
+	}
+
 	return pm.saveRegistry()
 }
 
-// GetManifest loads the manifest for a specific package version
-func (pm *PluginPackageManager) GetManifest(org, name, version string) (*PluginManifest, error) {
-	manifestPath := filepath.Join(pm.PackagePath(org, name, version), "manifest.json")
-	data, err := os.ReadFile(manifestPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read manifest: %w", err)
+// linkAliases creates symlinks under aliases/ for the manifest's Name and
+// Aliases, replacing any aliases previously owned by the same org/name.
+// An alias already owned by a different package is left untouched: the
+// first package to claim an alias keeps it until it is uninstalled.
+func (pm *PluginPackageManager) linkAliases(manifest *PluginManifest, binaryPath string) error {
+	pkgKey := fmt.Sprintf("%s/%s", manifest.Org, manifest.Name)
+	pm.unlinkAliases(func(ref string) bool { return pkgKeyOf(ref) == pkgKey })
+
+	if err := os.MkdirAll(filepath.Join(pm.baseDir, aliasesDir), 0755); err != nil {
+		return fmt.Errorf("failed to create aliases directory: %w", err)
+	}
+
+	ref := fmt.Sprintf("%s@%s", pkgKey, manifest.Version)
+	for _, alias := range append([]string{manifest.Name}, manifest.Aliases...) {
+		if alias == "" || strings.ContainsAny(alias, `/\`) {
+			continue
+		}
+		if owner, ok := pm.registry.Aliases[alias]; ok && pkgKeyOf(owner) != pkgKey {
+			continue
+		}
+
+		aliasPath := pm.AliasPath(alias)
+		if _, err := os.Lstat(aliasPath); err == nil {
+			if err := os.Remove(aliasPath); err != nil {
+				return fmt.Errorf("failed to remove existing alias %s: %w", alias, err)
+			}
+		}
+		if err := os.Symlink(pm.linkTarget(aliasPath, binaryPath), aliasPath); err != nil {
+			return fmt.Errorf("failed to create alias symlink %s: %w", alias, err)
+		}
+		pm.registry.Aliases[alias] = ref
 	}
 
-	manifest := &PluginManifest{}
-	if err := json.Unmarshal(data, manifest); err != nil {
-		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	return nil
+}
+
+// unlinkAliases removes alias symlinks whose owning package reference matches
+func (pm *PluginPackageManager) unlinkAliases(match func(ref string) bool) {
+	for alias, ref := range pm.registry.Aliases {
+		if match(ref) {
+			_ = os.Remove(pm.AliasPath(alias))
+			delete(pm.registry.Aliases, alias)
+		}
+	}
+}
+
+// GetManifest loads the manifest for a specific package version. Manifests
+// are cached in memory; Install, Link, and Uninstall invalidate the
+// affected entry, and FreshManifest forces a read from disk.
+func (pm *PluginPackageManager) GetManifest(org, name, version string, opts ...ManifestOption) (*PluginManifest, error) {
+	var o manifestOptions
+	for _, opt := range opts {
+		opt(&o)
 	}
 
-	return manifest, nil
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	return pm.loadManifest(org, name, version, o.fresh)
 }
 
-// List returns all installed packages
+// List returns all installed packages, sorted by org, then name, then
+// version (newest first)
 func (pm *PluginPackageManager) List(ctx context.Context) ([]PluginManifest, error) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	return pm.list(ctx)
+}
+
+// list implements List; the caller must hold pm.mu
+func (pm *PluginPackageManager) list(ctx context.Context) ([]PluginManifest, error) {
 	var manifests []PluginManifest
 
 	for pkgKey, versions := range pm.registry.Plugins {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
 		parts := strings.SplitN(pkgKey, "/", 2)
 		if len(parts) != 2 {
 			continue
@@ -406,7 +937,7 @@ func (pm *PluginPackageManager) List(ctx context.Context) ([]PluginManifest, err
 		org, name := parts[0], parts[1]
 
 		for _, version := range versions {
-			manifest, err := pm.GetManifest(org, name, version)
+			manifest, err := pm.getManifest(org, name, version)
 			if err != nil {
 				continue // Skip packages with invalid manifests
 			}
@@ -414,11 +945,25 @@ func (pm *PluginPackageManager) List(ctx context.Context) ([]PluginManifest, err
 		}
 	}
 
+	sort.Slice(manifests, func(i, j int) bool {
+		a, b := manifests[i], manifests[j]
+		if a.Org != b.Org {
+			return a.Org < b.Org
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return CompareSemver(a.Version, b.Version) > 0
+	})
+
 	return manifests, nil
 }
 
 // ListActive returns all active plugins (those with VMID symlinks)
 func (pm *PluginPackageManager) ListActive(ctx context.Context) (map[string]PluginManifest, error) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
 	active := make(map[string]PluginManifest)
 
 	entries, err := os.ReadDir(filepath.Join(pm.baseDir, activeDir))
@@ -430,22 +975,21 @@ func (pm *PluginPackageManager) ListActive(ctx context.Context) (map[string]Plug
 	}
 
 	for _, entry := range entries {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
 		vmid := entry.Name()
 		// Look up in registry
 		if pkgRef, ok := pm.registry.Active[vmid]; ok {
-			// Parse org/name@version
-			atIdx := strings.LastIndex(pkgRef, "@")
-			if atIdx == -1 {
-				continue
-			}
-			pkgKey := pkgRef[:atIdx]
-			version := pkgRef[atIdx+1:]
-			parts := strings.SplitN(pkgKey, "/", 2)
-			if len(parts) != 2 {
+			org, name, version, ok := splitPackageRef(pkgRef)
+			if !ok {
 				continue
 			}
 
-			manifest, err := pm.GetManifest(parts[0], parts[1], version)
+			manifest, err := pm.getManifest(org, name, version)
 			if err != nil {
 				continue
 			}
@@ -456,12 +1000,220 @@ func (pm *PluginPackageManager) ListActive(ctx context.Context) (map[string]Plug
 	return active, nil
 }
 
-// Uninstall removes a specific version of a package
-func (pm *PluginPackageManager) Uninstall(ctx context.Context, org, name, version string) error {
+// PluginEnv returns the environment variables declared by the active
+// plugin for vmid. Returns an empty map if the plugin declares none.
+func (pm *PluginPackageManager) PluginEnv(vmid string) (map[string]string, error) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	ref, ok := pm.registry.Active[vmid]
+	if !ok {
+		return nil, fmt.Errorf("no active plugin for vmid %s", vmid)
+	}
+	org, name, version, ok := splitPackageRef(ref)
+	if !ok {
+		return nil, fmt.Errorf("invalid package reference %q", ref)
+	}
+
+	manifest, err := pm.getManifest(org, name, version)
+	if err != nil {
+		return nil, err
+	}
+
+	env := make(map[string]string, len(manifest.Env))
+	for k, v := range manifest.Env {
+		env[k] = v
+	}
+	return env, nil
+}
+
+// VMIDConflict lists the packages that declare the same VMID
+type VMIDConflict struct {
+	VMID     string   `json:"vmid"`
+	Packages []string `json:"packages"` // org/name, sorted
+}
+
+// CheckVMIDConflicts reports every VMID declared by more than one org/name
+// among installed packages, sorted by VMID
+func (pm *PluginPackageManager) CheckVMIDConflicts(ctx context.Context) ([]VMIDConflict, error) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	manifests, err := pm.list(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	owners := make(map[string][]string)
+	for _, m := range manifests {
+		pkgKey := m.Org + "/" + m.Name
+		if !contains(owners[m.VMID], pkgKey) {
+			owners[m.VMID] = append(owners[m.VMID], pkgKey)
+		}
+	}
+
+	var conflicts []VMIDConflict
+	for vmid, pkgs := range owners {
+		if len(pkgs) > 1 {
+			sort.Strings(pkgs)
+			conflicts = append(conflicts, VMIDConflict{VMID: vmid, Packages: pkgs})
+		}
+	}
+	sort.Slice(conflicts, func(i, j int) bool { return conflicts[i].VMID < conflicts[j].VMID })
+
+	return conflicts, nil
+}
+
+// VerifyResult is the outcome of verifying one installed package version
+type VerifyResult struct {
+	Org     string `json:"org"`
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	VMID    string `json:"vmid,omitempty"`
+	OK      bool   `json:"ok"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// VerifyAll checks every package version in the registry: the package
+// directory and manifest exist, the binary exists and is executable, the
+// checksum matches when one is recorded, and the VMID matches VMID(VMName)
+// when VMName is set. It reports every package rather than stopping at the
+// first failure; the error is only set if ctx is cancelled.
+func (pm *PluginPackageManager) VerifyAll(ctx context.Context) ([]VerifyResult, error) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	pkgKeys := make([]string, 0, len(pm.registry.Plugins))
+	for pkgKey := range pm.registry.Plugins {
+		pkgKeys = append(pkgKeys, pkgKey)
+	}
+	sort.Strings(pkgKeys)
+
+	var results []VerifyResult
+	for _, pkgKey := range pkgKeys {
+		parts := strings.SplitN(pkgKey, "/", 2)
+		if len(parts) != 2 {
+			results = append(results, VerifyResult{Name: pkgKey, Reason: "invalid registry key"})
+			continue
+		}
+
+		for _, version := range pm.registry.Plugins[pkgKey] {
+			select {
+			case <-ctx.Done():
+				return results, ctx.Err()
+			default:
+			}
+
+			result := VerifyResult{Org: parts[0], Name: parts[1], Version: version}
+			vmid, err := pm.verifyPackage(parts[0], parts[1], version)
+			result.VMID = vmid
+			if err != nil {
+				result.Reason = err.Error()
+			} else {
+				result.OK = true
+			}
+			results = append(results, result)
+		}
+	}
+
+	return results, nil
+}
+
+// verifyPackage checks a single package version, returning its VMID if known
+func (pm *PluginPackageManager) verifyPackage(org, name, version string) (string, error) {
+	pkgPath := pm.PackagePath(org, name, version)
+	if info, err := os.Stat(pkgPath); err != nil || !info.IsDir() {
+		return "", fmt.Errorf("package directory missing: %s", pkgPath)
+	}
+
+	manifest, err := pm.loadManifest(org, name, version, true)
+	if err != nil {
+		return "", err
+	}
+
+	binaryName := manifest.Binary
+	if binaryName == "" {
+		binaryName = name
+	}
+	binaryPath := filepath.Join(pkgPath, binaryName)
+	info, err := os.Stat(binaryPath)
+	if err != nil {
+		return manifest.VMID, fmt.Errorf("binary missing: %s", binaryPath)
+	}
+	if info.IsDir() || info.Mode()&0111 == 0 {
+		return manifest.VMID, fmt.Errorf("binary is not executable: %s", binaryPath)
+	}
+
+	if manifest.Checksum != "" {
+		sum, err := fileSHA256(binaryPath)
+		if err != nil {
+			return manifest.VMID, fmt.Errorf("failed to checksum binary: %w", err)
+		}
+		if !strings.EqualFold(sum, manifest.Checksum) {
+			return manifest.VMID, fmt.Errorf("checksum mismatch: got %s, want %s", sum, manifest.Checksum)
+		}
+	}
+
+	if manifest.VMName != "" {
+		if want := VMID(manifest.VMName); manifest.VMID != want {
+			return manifest.VMID, fmt.Errorf("vmid %s does not match vm name %q (want %s)", manifest.VMID, manifest.VMName, want)
+		}
+	}
+
+	return manifest.VMID, nil
+}
+
+// ListActiveSorted returns all active plugins as a slice sorted by VMID
+func (pm *PluginPackageManager) ListActiveSorted(ctx context.Context) ([]PluginManifest, error) {
+	active, err := pm.ListActive(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	vmids := make([]string, 0, len(active))
+	for vmid := range active {
+		vmids = append(vmids, vmid)
+	}
+	sort.Strings(vmids)
+
+	manifests := make([]PluginManifest, 0, len(vmids))
+	for _, vmid := range vmids {
+		manifests = append(manifests, active[vmid])
+	}
+	return manifests, nil
+}
+
+// Uninstall removes a specific version of a package. With WithTrash the
+// package directory is moved to the trash instead of deleted; the VMID
+// symlink, aliases, and registry entry are removed either way.
+func (pm *PluginPackageManager) Uninstall(ctx context.Context, org, name, version string, opts ...UninstallOption) error {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	o := applyUninstallOptions(opts)
+
 	pkgPath := pm.PackagePath(org, name, version)
 
 	// Load manifest to get VMID before removing
-	manifest, err := pm.GetManifest(org, name, version)
+	manifest, err := pm.getManifest(org, name, version)
+
+	// Move or remove the package first, so a failure leaves the links and
+	// registry untouched
+	if o.trash {
+		if err := pm.trashPackage(org, name, version); err != nil {
+			return err
+		}
+	} else {
+		// Remove package directory, restoring write permission first
+		if err := makeWritable(pkgPath); err != nil {
+			return fmt.Errorf("failed to unlock package: %w", err)
+		}
+		if err := os.RemoveAll(pkgPath); err != nil {
+			return fmt.Errorf("failed to remove package: %w", err)
+		}
+	}
+	pm.invalidateManifest(org, name, version)
+
 	if err == nil && manifest.VMID != "" {
 		// Remove VMID symlink
 		vmidPath := pm.ActivePath(manifest.VMID)
@@ -469,10 +1221,9 @@ func (pm *PluginPackageManager) Uninstall(ctx context.Context, org, name, versio
 		delete(pm.registry.Active, manifest.VMID)
 	}
 
-	// Remove package directory
-	if err := os.RemoveAll(pkgPath); err != nil {
-		return fmt.Errorf("failed to remove package: %w", err)
-	}
+	// Release any aliases owned by this version
+	pkgRef := fmt.Sprintf("%s/%s@%s", org, name, version)
+	pm.unlinkAliases(func(ref string) bool { return ref == pkgRef })
 
 	// Update registry
 	pkgKey := fmt.Sprintf("%s/%s", org, name)
@@ -481,6 +1232,9 @@ func (pm *PluginPackageManager) Uninstall(ctx context.Context, org, name, versio
 	if len(pm.registry.Plugins[pkgKey]) == 0 {
 		delete(pm.registry.Plugins, pkgKey)
 	}
+	if pm.registry.Pinned[pkgKey] == version {
+		delete(pm.registry.Pinned, pkgKey)
+	}
 
 	return pm.saveRegistry()
 }
@@ -490,50 +1244,209 @@ func (pm *PluginPackageManager) GetActiveDir() string {
 	return filepath.Join(pm.baseDir, activeDir)
 }
 
-// MigrateFromLegacy migrates plugins from the old VMID-based structure
-func (pm *PluginPackageManager) MigrateFromLegacy(ctx context.Context, legacyDir string) error {
+// MigrationEntry describes the outcome for one entry of a legacy plugin dir
+type MigrationEntry struct {
+	VMID    string `json:"vmid"`
+	Source  string `json:"source"`            // Legacy symlink target
+	Package string `json:"package,omitempty"` // org/name@version installed
+	Reason  string `json:"reason,omitempty"`  // Why it was skipped or failed
+}
+
+// MigrationReport summarizes a MigrateFromLegacy run
+type MigrationReport struct {
+	Migrated []MigrationEntry `json:"migrated"`
+	Skipped  []MigrationEntry `json:"skipped"`
+	Failed   []MigrationEntry `json:"failed"`
+}
+
+// MigrateFromLegacy migrates plugins from the old VMID-based structure.
+// Each VMID symlink in legacyDir is installed as legacy/<name>@v0.0.0,
+// where name is derived from the VMID (see legacyPackageName).
+// Directories and regular files are skipped. A failure to install one
+// plugin is recorded in the report and does not stop the migration.
+func (pm *PluginPackageManager) MigrateFromLegacy(ctx context.Context, legacyDir string) (*MigrationReport, error) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	report := &MigrationReport{}
 	entries, err := os.ReadDir(legacyDir)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return nil // Nothing to migrate
+			return report, nil // Nothing to migrate
 		}
-		return err
+		return nil, err
 	}
 
 	for _, entry := range entries {
-		if entry.IsDir() {
-			continue // Skip directories (new structure uses files/symlinks)
+		if err := ctx.Err(); err != nil {
+			return report, err
 		}
 
 		vmid := entry.Name()
 		oldPath := filepath.Join(legacyDir, vmid)
+		if entry.IsDir() {
+			// New structure uses files/symlinks
+			report.Skipped = append(report.Skipped, MigrationEntry{VMID: vmid, Source: oldPath, Reason: "directory"})
+			continue
+		}
 
 		// Check if it's a symlink
 		target, err := os.Readlink(oldPath)
 		if err != nil {
-			continue // Not a symlink, skip
+			report.Skipped = append(report.Skipped, MigrationEntry{VMID: vmid, Source: oldPath, Reason: "not a symlink"})
+			continue
+		}
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(legacyDir, target)
 		}
 
 		// Create a basic manifest for legacy plugins
 		manifest := &PluginManifest{
 			Org:     "legacy",
-			Name:    vmid[:8] + "...", // Truncated VMID as name
+			Name:    pm.legacyPackageName(vmid),
 			Version: "v0.0.0",
 			VMID:    vmid,
 			Binary:  filepath.Base(target),
 		}
 
 		// Install the legacy plugin
-		if err := pm.Install(ctx, manifest, target); err != nil {
-			fmt.Printf("warning: failed to migrate legacy plugin %s: %v\n", vmid, err)
+		result := MigrationEntry{VMID: vmid, Source: target}
+		if err := pm.install(ctx, manifest, target); err != nil {
+			result.Reason = err.Error()
+			report.Failed = append(report.Failed, result)
+			continue
 		}
+		result.Package = fmt.Sprintf("%s/%s@%s", manifest.Org, manifest.Name, manifest.Version)
+		report.Migrated = append(report.Migrated, result)
 	}
 
+	return report, nil
+}
+
+// legacyPackageName returns the package name MigrateFromLegacy uses for
+// vmid: the VMID truncated and lowercased to be a valid package name. Case
+// folding loses information, so when that name already belongs to a package
+// with a different VMID it is suffixed -2, -3, and so on. The caller must
+// hold pm.mu.
+func (pm *PluginPackageManager) legacyPackageName(vmid string) string {
+	base := strings.ToLower(vmid)
+	if len(base) > 8 {
+		base = base[:8]
+	}
+
+	name := base
+	for i := 2; ; i++ {
+		versions := pm.registry.Plugins["legacy/"+name]
+		if len(versions) == 0 {
+			return name
+		}
+		for _, version := range versions {
+			if m, err := pm.getManifest("legacy", name, version); err == nil && m.VMID == vmid {
+				return name
+			}
+		}
+		name = fmt.Sprintf("%s-%d", base, i)
+	}
+}
+
+// ExportLegacy writes the active plugins into dir in the legacy flat layout:
+// one symlink per VMID pointing at the active binary, for consumers that
+// don't understand the packages/ structure. Existing symlinks in dir are
+// replaced; any other existing file with a VMID's name is an error.
+func (pm *PluginPackageManager) ExportLegacy(dir string) error {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create legacy directory: %w", err)
+	}
+
+	vmids := make([]string, 0, len(pm.registry.Active))
+	for vmid := range pm.registry.Active {
+		vmids = append(vmids, vmid)
+	}
+	sort.Strings(vmids)
+
+	for _, vmid := range vmids {
+		target, err := pm.activeTarget(pm.registry.Active[vmid])
+		if err != nil {
+			return fmt.Errorf("failed to resolve %s: %w", vmid, err)
+		}
+
+		linkPath := filepath.Join(dir, vmid)
+		if info, err := os.Lstat(linkPath); err == nil {
+			if info.Mode()&os.ModeSymlink == 0 {
+				return fmt.Errorf("refusing to replace non-symlink %s", linkPath)
+			}
+			if err := os.Remove(linkPath); err != nil {
+				return fmt.Errorf("failed to remove existing symlink: %w", err)
+			}
+		}
+		if err := os.Symlink(target, linkPath); err != nil {
+			return fmt.Errorf("failed to create legacy symlink %s: %w", vmid, err)
+		}
+	}
 	return nil
 }
 
 // Helper functions
 
+// lockPackage makes an installed package read-only
+func lockPackage(pkgPath, binaryPath, manifestPath string) error {
+	if err := os.Chmod(binaryPath, 0555); err != nil {
+		return err
+	}
+	if err := os.Chmod(manifestPath, 0444); err != nil {
+		return err
+	}
+	return os.Chmod(pkgPath, 0555)
+}
+
+// makeWritable restores owner write permission on a package directory and
+// its files so it can be modified or removed. A missing path is not an error.
+func makeWritable(pkgPath string) error {
+	if _, err := os.Lstat(pkgPath); os.IsNotExist(err) {
+		return nil
+	}
+	return filepath.WalkDir(pkgPath, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type()&os.ModeSymlink != 0 {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if info.Mode().Perm()&0200 != 0 {
+			return nil
+		}
+		return os.Chmod(path, info.Mode().Perm()|0200)
+	})
+}
+
+// pkgKeyOf returns the org/name part of an org/name@version reference
+func pkgKeyOf(ref string) string {
+	if i := strings.LastIndex(ref, "@"); i >= 0 {
+		return ref[:i]
+	}
+	return ref
+}
+
+// splitPackageRef parses an org/name@version reference
+func splitPackageRef(ref string) (org, name, version string, ok bool) {
+	atIdx := strings.LastIndex(ref, "@")
+	if atIdx == -1 {
+		return "", "", "", false
+	}
+	parts := strings.SplitN(ref[:atIdx], "/", 2)
+	if len(parts) != 2 {
+		return "", "", "", false
+	}
+	return parts[0], parts[1], ref[atIdx+1:], true
+}
+
 func contains(slice []string, item string) bool {
 	for _, s := range slice {
 		if s == item {
@@ -0,0 +1,82 @@
+// Copyright (C) 2024-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// manifestOptions holds settings for GetManifest
+type manifestOptions struct {
+	fresh bool
+}
+
+// ManifestOption is a functional option for GetManifest
+type ManifestOption func(*manifestOptions)
+
+// FreshManifest makes GetManifest read the manifest from disk instead of
+// the cache, e.g. after it was edited by another process. The cache is
+// refreshed with the result.
+func FreshManifest() ManifestOption {
+	return func(o *manifestOptions) {
+		o.fresh = true
+	}
+}
+
+// manifestKey keys the manifest cache
+func manifestKey(org, name, version string) string {
+	return org + "/" + name + "@" + version
+}
+
+// getManifest returns the manifest for a package version, from the cache
+// when possible. The caller must hold pm.mu.
+func (pm *PluginPackageManager) getManifest(org, name, version string) (*PluginManifest, error) {
+	return pm.loadManifest(org, name, version, false)
+}
+
+// loadManifest implements GetManifest. The caller must hold pm.mu.
+func (pm *PluginPackageManager) loadManifest(org, name, version string, fresh bool) (*PluginManifest, error) {
+	key := manifestKey(org, name, version)
+	if cached, ok := pm.manifests[key]; ok && !fresh {
+		return cloneManifest(cached), nil
+	}
+
+	manifestPath := filepath.Join(pm.PackagePath(org, name, version), "manifest.json")
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		delete(pm.manifests, key)
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	manifest := &PluginManifest{}
+	if err := json.Unmarshal(data, manifest); err != nil {
+		delete(pm.manifests, key)
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+
+	if pm.manifests == nil {
+		pm.manifests = make(map[string]*PluginManifest)
+	}
+	pm.manifests[key] = manifest
+	return cloneManifest(manifest), nil
+}
+
+// invalidateManifest drops a package version from the manifest cache. The
+// caller must hold pm.mu.
+func (pm *PluginPackageManager) invalidateManifest(org, name, version string) {
+	delete(pm.manifests, manifestKey(org, name, version))
+}
+
+// cloneManifest copies m so callers can't modify the cached manifest
+func cloneManifest(m *PluginManifest) *PluginManifest {
+	c := *m
+	c.Aliases = slices.Clone(m.Aliases)
+	c.Env = maps.Clone(m.Env)
+	return &c
+}
@@ -0,0 +1,177 @@
+// Copyright (C) 2024-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// RelinkStatus is the outcome of relinking one VMID symlink
+type RelinkStatus string
+
+const (
+	RelinkOK       RelinkStatus = "ok"       // Symlink already pointed at the right binary
+	RelinkRepaired RelinkStatus = "repaired" // Symlink was recreated
+	RelinkMissing  RelinkStatus = "missing"  // The package binary itself is missing
+)
+
+// RelinkResult reports what RelinkAll did for one active VMID
+type RelinkResult struct {
+	VMID    string       `json:"vmid"`
+	Package string       `json:"package"` // org/name@version
+	Target  string       `json:"target,omitempty"`
+	Status  RelinkStatus `json:"status"`
+	Reason  string       `json:"reason,omitempty"`
+}
+
+// RelinkAll recreates the VMID symlink of every active registry entry so it
+// points at the package binary under the current base directory. Installed
+// packages link to their copied binary; linked (development) packages link
+// to the source binary their package entry points at. Alias symlinks are
+// refreshed too. Use it after moving or restoring the plugin directory,
+// or to convert existing links after enabling WithRelativeSymlinks.
+// Results are sorted by VMID; the error is set only if ctx is cancelled or
+// a symlink cannot be written.
+func (pm *PluginPackageManager) RelinkAll(ctx context.Context) ([]RelinkResult, error) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	vmids := make([]string, 0, len(pm.registry.Active))
+	for vmid := range pm.registry.Active {
+		vmids = append(vmids, vmid)
+	}
+	sort.Strings(vmids)
+
+	var results []RelinkResult
+	for _, vmid := range vmids {
+		select {
+		case <-ctx.Done():
+			return results, ctx.Err()
+		default:
+		}
+
+		ref := pm.registry.Active[vmid]
+		result := RelinkResult{VMID: vmid, Package: ref}
+		target, err := pm.activeTarget(ref)
+		if err != nil {
+			result.Status, result.Reason = RelinkMissing, err.Error()
+			results = append(results, result)
+			continue
+		}
+		result.Target = target
+
+		vmidPath := pm.ActivePath(vmid)
+		repaired, err := relinkSymlink(vmidPath, pm.linkTarget(vmidPath, target))
+		if err != nil {
+			return results, fmt.Errorf("failed to relink %s: %w", vmid, err)
+		}
+		result.Status = RelinkOK
+		if repaired {
+			result.Status = RelinkRepaired
+		}
+		results = append(results, result)
+	}
+
+	for alias, ref := range pm.registry.Aliases {
+		target, err := pm.activeTarget(ref)
+		if err != nil {
+			continue
+		}
+		aliasPath := pm.AliasPath(alias)
+		if _, err := relinkSymlink(aliasPath, pm.linkTarget(aliasPath, target)); err != nil {
+			return results, fmt.Errorf("failed to relink alias %s: %w", alias, err)
+		}
+	}
+
+	if err := pm.saveRegistry(); err != nil {
+		return results, err
+	}
+	return results, nil
+}
+
+// activeTarget returns the binary a package reference's symlinks should
+// point at, resolving linked packages to their source binary
+func (pm *PluginPackageManager) activeTarget(ref string) (string, error) {
+	org, name, version, ok := splitPackageRef(ref)
+	if !ok {
+		return "", fmt.Errorf("invalid package reference %q", ref)
+	}
+	manifest, err := pm.getManifest(org, name, version)
+	if err != nil {
+		return "", err
+	}
+
+	binaryName := manifest.Binary
+	if binaryName == "" {
+		binaryName = name
+	}
+	binaryPath := filepath.Join(pm.PackagePath(org, name, version), binaryName)
+
+	info, err := os.Lstat(binaryPath)
+	if err != nil {
+		return "", fmt.Errorf("binary missing: %s", binaryPath)
+	}
+	if info.Mode()&os.ModeSymlink != 0 {
+		// Linked package: point straight at the source binary
+		source, err := os.Readlink(binaryPath)
+		if err != nil {
+			return "", err
+		}
+		if !filepath.IsAbs(source) {
+			source = filepath.Join(filepath.Dir(binaryPath), source)
+		}
+		if !Exists(source) {
+			return "", fmt.Errorf("linked source binary missing: %s", source)
+		}
+		return source, nil
+	}
+
+	return binaryPath, nil
+}
+
+// linkTarget returns the symlink target to write at linkPath for target:
+// relative to linkPath's directory with WithRelativeSymlinks when target is
+// under the base directory, otherwise target unchanged
+func (pm *PluginPackageManager) linkTarget(linkPath, target string) string {
+	if !pm.relativeLinks {
+		return target
+	}
+	absBase, err := filepath.Abs(pm.baseDir)
+	if err != nil {
+		return target
+	}
+	absLink, err := filepath.Abs(linkPath)
+	if err != nil {
+		return target
+	}
+	absTarget, err := filepath.Abs(target)
+	if err != nil || !pathWithin(absBase, absTarget) {
+		return target
+	}
+	rel, err := filepath.Rel(filepath.Dir(absLink), absTarget)
+	if err != nil {
+		return target
+	}
+	return rel
+}
+
+// relinkSymlink makes path a symlink to target, reporting whether it changed
+func relinkSymlink(path, target string) (bool, error) {
+	if current, err := os.Readlink(path); err == nil && current == target {
+		return false, nil
+	}
+	if _, err := os.Lstat(path); err == nil {
+		if err := os.Remove(path); err != nil {
+			return false, err
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return false, err
+	}
+	return true, os.Symlink(target, path)
+}
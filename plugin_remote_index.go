@@ -0,0 +1,177 @@
+// Copyright (C) 2024-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package config
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// RemoteRelease is one downloadable package version in a remote index
+type RemoteRelease struct {
+	Org      string `json:"-"`
+	Name     string `json:"-"`
+	Version  string `json:"version"`
+	URL      string `json:"url"`
+	Checksum string `json:"checksum,omitempty"` // Hex sha256 of the binary
+	VMID     string `json:"vmid"`
+	VMName   string `json:"vm_name,omitempty"`
+}
+
+// Manifest returns a manifest for installing the release with InstallFromURL
+func (r *RemoteRelease) Manifest() *PluginManifest {
+	return &PluginManifest{
+		Org:      r.Org,
+		Name:     r.Name,
+		Version:  r.Version,
+		VMID:     r.VMID,
+		VMName:   r.VMName,
+		Checksum: r.Checksum,
+	}
+}
+
+// RemotePackage lists the released versions of one org/name
+type RemotePackage struct {
+	Org         string          `json:"org"`
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Versions    []RemoteRelease `json:"versions"`
+}
+
+// RemoteIndex is a catalog of installable plugins, as served in a remote
+// index.json. It is independent of the local registry, so callers can
+// cache and refresh it on their own schedule.
+type RemoteIndex struct {
+	Packages  []RemotePackage `json:"packages"`
+	UpdatedAt time.Time       `json:"updated_at,omitempty"`
+}
+
+// FetchIndex downloads and parses the remote index at url
+func FetchIndex(ctx context.Context, url string) (*RemoteIndex, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch index %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch index %s: unexpected status %s", url, resp.Status)
+	}
+	return ReadIndex(resp.Body)
+}
+
+// ReadIndex parses a remote index, e.g. a cached copy of one fetched by
+// FetchIndex. Every package reference, version, URL, VMID, and checksum is
+// validated.
+func ReadIndex(r io.Reader) (*RemoteIndex, error) {
+	idx := &RemoteIndex{}
+	if err := json.NewDecoder(r).Decode(idx); err != nil {
+		return nil, fmt.Errorf("failed to parse index: %w", err)
+	}
+
+	seen := make(map[string]bool, len(idx.Packages))
+	for i := range idx.Packages {
+		pkg := &idx.Packages[i]
+		if err := ValidatePackageRef(pkg.Org, pkg.Name); err != nil {
+			return nil, fmt.Errorf("invalid index entry: %w", err)
+		}
+		key := pkg.Org + "/" + pkg.Name
+		if seen[key] {
+			return nil, fmt.Errorf("invalid index: package %s is listed twice", key)
+		}
+		seen[key] = true
+
+		for j := range pkg.Versions {
+			release := &pkg.Versions[j]
+			release.Org, release.Name = pkg.Org, pkg.Name
+			if err := release.validate(); err != nil {
+				return nil, fmt.Errorf("invalid index entry %s@%s: %w", key, release.Version, err)
+			}
+		}
+	}
+	return idx, nil
+}
+
+// validate checks a release's version, URL, VMID, and checksum
+func (r *RemoteRelease) validate() error {
+	if !IsValidSemver(r.Version) {
+		return fmt.Errorf("version is not valid semver")
+	}
+	if r.URL == "" {
+		return fmt.Errorf("missing url")
+	}
+	if err := ValidateVMID(r.VMID); err != nil {
+		return err
+	}
+	if r.Checksum != "" {
+		if sum, err := hex.DecodeString(r.Checksum); err != nil || len(sum) != 32 {
+			return fmt.Errorf("checksum is not a hex sha256")
+		}
+	}
+	return nil
+}
+
+// find returns the package entry for org/name, or nil
+func (idx *RemoteIndex) find(org, name string) *RemotePackage {
+	for i := range idx.Packages {
+		if idx.Packages[i].Org == org && idx.Packages[i].Name == name {
+			return &idx.Packages[i]
+		}
+	}
+	return nil
+}
+
+// Versions returns the versions available for org/name, oldest first
+func (idx *RemoteIndex) Versions(org, name string) []string {
+	pkg := idx.find(org, name)
+	if pkg == nil {
+		return nil
+	}
+	versions := make([]string, 0, len(pkg.Versions))
+	for _, release := range pkg.Versions {
+		versions = append(versions, release.Version)
+	}
+	return compactVersions(versions)
+}
+
+// Available returns the available versions keyed by org/name, in the form
+// UpgradePlan takes
+func (idx *RemoteIndex) Available() map[string][]string {
+	available := make(map[string][]string, len(idx.Packages))
+	for _, pkg := range idx.Packages {
+		available[pkg.Org+"/"+pkg.Name] = idx.Versions(pkg.Org, pkg.Name)
+	}
+	return available
+}
+
+// Resolve returns the release of org/name at version. An empty version or
+// "latest" selects the newest stable release. The error wraps
+// ErrPluginNotFound when the index has no such release.
+func (idx *RemoteIndex) Resolve(org, name, version string) (*RemoteRelease, error) {
+	pkg := idx.find(org, name)
+	if pkg == nil {
+		return nil, fmt.Errorf("%w: %s/%s not in index", ErrPluginNotFound, org, name)
+	}
+
+	if version == "" || version == "latest" {
+		version = latestStable(idx.Versions(org, name))
+	}
+	for i := range pkg.Versions {
+		if pkg.Versions[i].Version == version {
+			release := pkg.Versions[i]
+			release.Org, release.Name = pkg.Org, pkg.Name
+			return &release, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s/%s@%s not in index", ErrPluginNotFound, org, name, version)
+}
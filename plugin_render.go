@@ -0,0 +1,103 @@
+// Copyright (C) 2024-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+)
+
+// vmidDisplayLen is the number of VMID characters shown in tables
+const vmidDisplayLen = 12
+
+// String returns a human-readable multi-line description of the manifest
+func (m PluginManifest) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s/%s@%s\n", m.Org, m.Name, m.Version)
+	fmt.Fprintf(&b, "  VMID:        %s\n", m.VMID)
+	if m.VMName != "" {
+		fmt.Fprintf(&b, "  VM name:     %s\n", m.VMName)
+	}
+	if len(m.Aliases) > 0 {
+		fmt.Fprintf(&b, "  Aliases:     %s\n", strings.Join(m.Aliases, ", "))
+	}
+	if m.Binary != "" {
+		fmt.Fprintf(&b, "  Binary:      %s\n", m.Binary)
+	}
+	if m.Platform != "" {
+		fmt.Fprintf(&b, "  Platform:    %s\n", m.Platform)
+	}
+	if m.Size > 0 {
+		fmt.Fprintf(&b, "  Size:        %s\n", formatSize(m.Size))
+	}
+	if m.Checksum != "" {
+		fmt.Fprintf(&b, "  Checksum:    %s\n", m.Checksum)
+	}
+	if m.BuildCommit != "" {
+		fmt.Fprintf(&b, "  Commit:      %s\n", m.ShortCommit())
+	}
+	if m.BuiltBy != "" {
+		fmt.Fprintf(&b, "  Built by:    %s\n", m.BuiltBy)
+	}
+	if !m.BuildTime.IsZero() {
+		fmt.Fprintf(&b, "  Built:       %s\n", m.BuildTime.Format("2006-01-02 15:04:05"))
+	}
+	if m.ToolVersion != "" {
+		fmt.Fprintf(&b, "  Toolchain:   %s\n", m.ToolVersion)
+	}
+	if !m.InstalledAt.IsZero() {
+		fmt.Fprintf(&b, "  Installed:   %s\n", m.InstalledAt.Format("2006-01-02 15:04:05"))
+	}
+	if m.Description != "" {
+		fmt.Fprintf(&b, "  Description: %s\n", m.Description)
+	}
+	if m.Repository != "" {
+		fmt.Fprintf(&b, "  Repository:  %s\n", m.Repository)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// RenderTable renders manifests as aligned columns for CLI listings
+func RenderTable(manifests []PluginManifest) []byte {
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PACKAGE\tVERSION\tVMID\tCOMMIT\tSIZE\tINSTALLED")
+	for _, m := range manifests {
+		installed := "-"
+		if !m.InstalledAt.IsZero() {
+			installed = m.InstalledAt.Format("2006-01-02 15:04")
+		}
+		commit := m.ShortCommit()
+		if commit == "" {
+			commit = "-"
+		}
+		fmt.Fprintf(w, "%s/%s\t%s\t%s\t%s\t%s\t%s\n", m.Org, m.Name, m.Version, truncateVMID(m.VMID), commit, formatSize(m.Size), installed)
+	}
+	w.Flush()
+	return buf.Bytes()
+}
+
+// truncateVMID shortens a VMID for display
+func truncateVMID(vmid string) string {
+	if len(vmid) <= vmidDisplayLen {
+		return vmid
+	}
+	return vmid[:vmidDisplayLen] + "..."
+}
+
+// formatSize formats a byte count using binary units (KiB, MiB, GiB)
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit && exp < 2; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMG"[exp])
+}
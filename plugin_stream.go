@@ -0,0 +1,66 @@
+// Copyright (C) 2024-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// ListStream writes every installed package to w as JSON lines, one
+// PluginManifest per line, in List order. Manifests are read and written
+// one at a time, and the lock is not held while writing, so a slow reader
+// does not block the manager. Packages with invalid manifests are skipped,
+// as in List.
+func (pm *PluginPackageManager) ListStream(ctx context.Context, w io.Writer) error {
+	// Snapshot the package references; only these are held in memory
+	pm.mu.Lock()
+	type pkgVersions struct {
+		org, name string
+		versions  []string
+	}
+	pkgs := make([]pkgVersions, 0, len(pm.registry.Plugins))
+	for pkgKey, versions := range pm.registry.Plugins {
+		org, name, ok := strings.Cut(pkgKey, "/")
+		if !ok {
+			continue
+		}
+		pkgs = append(pkgs, pkgVersions{org, name, append([]string(nil), versions...)})
+	}
+	pm.mu.Unlock()
+
+	sort.Slice(pkgs, func(i, j int) bool {
+		if pkgs[i].org != pkgs[j].org {
+			return pkgs[i].org < pkgs[j].org
+		}
+		return pkgs[i].name < pkgs[j].name
+	})
+
+	enc := json.NewEncoder(w)
+	for _, pkg := range pkgs {
+		sort.Slice(pkg.versions, func(i, j int) bool {
+			return CompareSemver(pkg.versions[i], pkg.versions[j]) > 0
+		})
+		for _, version := range pkg.versions {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			default:
+			}
+
+			manifest, err := pm.GetManifest(pkg.org, pkg.name, version)
+			if err != nil {
+				continue // Skip packages with invalid manifests
+			}
+			if err := enc.Encode(manifest); err != nil {
+				return fmt.Errorf("failed to write manifest: %w", err)
+			}
+		}
+	}
+	return nil
+}
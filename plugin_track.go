@@ -0,0 +1,80 @@
+// Copyright (C) 2024-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MissingVM is a VM required by some chains that has no usable active plugin
+type MissingVM struct {
+	VMID   string   `json:"vmid"`
+	Chains []string `json:"chains"` // Sorted chains that need the VM
+	Reason string   `json:"reason"`
+}
+
+// ChainVMReport splits the VMs needed by a set of chains into those with an
+// active plugin and those without
+type ChainVMReport struct {
+	Active  []string    `json:"active"` // Sorted VMIDs with an active plugin
+	Missing []MissingVM `json:"missing,omitempty"`
+}
+
+// Err returns nil if every required VM has an active plugin, and otherwise
+// an error wrapping ErrPluginNotFound that names each chain and the VM it
+// needs
+func (r *ChainVMReport) Err() error {
+	if len(r.Missing) == 0 {
+		return nil
+	}
+	var msgs []string
+	for _, m := range r.Missing {
+		for _, chain := range m.Chains {
+			msgs = append(msgs, fmt.Sprintf("chain %s needs VM %s (%s)", chain, m.VMID, m.Reason))
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrPluginNotFound, strings.Join(msgs, "; "))
+}
+
+// CheckChainVMs reports which of the VMs needed by chainVMIDs (chain name
+// or ID to VMID) have an active plugin whose binary is present, so a
+// launcher can refuse to start a node that would fail to load a VM
+func (pm *PluginPackageManager) CheckChainVMs(chainVMIDs map[string]string) (*ChainVMReport, error) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	chainsByVM := make(map[string][]string)
+	for chain, vmid := range chainVMIDs {
+		if vmid == "" {
+			return nil, fmt.Errorf("chain %s has no VMID", chain)
+		}
+		chainsByVM[vmid] = append(chainsByVM[vmid], chain)
+	}
+
+	vmids := make([]string, 0, len(chainsByVM))
+	for vmid := range chainsByVM {
+		vmids = append(vmids, vmid)
+	}
+	sort.Strings(vmids)
+
+	report := &ChainVMReport{}
+	for _, vmid := range vmids {
+		chains := chainsByVM[vmid]
+		sort.Strings(chains)
+
+		ref, ok := pm.registry.Active[vmid]
+		if !ok {
+			report.Missing = append(report.Missing, MissingVM{VMID: vmid, Chains: chains, Reason: "not installed"})
+			continue
+		}
+		if _, err := pm.activeTarget(ref); err != nil {
+			report.Missing = append(report.Missing, MissingVM{VMID: vmid, Chains: chains, Reason: err.Error()})
+			continue
+		}
+		report.Active = append(report.Active, vmid)
+	}
+	return report, nil
+}
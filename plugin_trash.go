@@ -0,0 +1,212 @@
+// Copyright (C) 2024-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// trashTimeLayout timestamps trashed package directories, in UTC
+const trashTimeLayout = "20060102T150405.000000000Z"
+
+// uninstallOptions holds settings for Uninstall
+type uninstallOptions struct {
+	trash bool
+}
+
+// UninstallOption is a functional option for Uninstall
+type UninstallOption func(*uninstallOptions)
+
+// WithTrash makes Uninstall move the package directory to trash/ under the
+// base directory instead of deleting it, so RestoreTrashed can undo it
+func WithTrash() UninstallOption {
+	return func(o *uninstallOptions) {
+		o.trash = true
+	}
+}
+
+// applyUninstallOptions builds uninstallOptions from opts
+func applyUninstallOptions(opts []UninstallOption) uninstallOptions {
+	var o uninstallOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// TrashEntry is a package version moved to the trash by Uninstall
+type TrashEntry struct {
+	Org       string    `json:"org"`
+	Name      string    `json:"name"`
+	Version   string    `json:"version"`
+	TrashedAt time.Time `json:"trashed_at"`
+	Path      string    `json:"path"`
+}
+
+// trashPackage moves a package directory to trash/org/name/version@time.
+// Write permission is restored first, since moving a directory to another
+// parent requires it and immutable packages are read-only. A missing
+// package directory is not an error. The caller must hold pm.mu.
+func (pm *PluginPackageManager) trashPackage(org, name, version string) error {
+	pkgPath := pm.PackagePath(org, name, version)
+	if _, err := os.Lstat(pkgPath); os.IsNotExist(err) {
+		return nil
+	}
+	if err := makeWritable(pkgPath); err != nil {
+		return fmt.Errorf("failed to unlock package: %w", err)
+	}
+
+	dir := filepath.Join(pm.baseDir, trashDir, org, name)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create trash directory: %w", err)
+	}
+	dest := filepath.Join(dir, version+"@"+time.Now().UTC().Format(trashTimeLayout))
+	if err := os.Rename(pkgPath, dest); err != nil {
+		return fmt.Errorf("failed to move package to trash: %w", err)
+	}
+	return nil
+}
+
+// ListTrash returns the trashed package versions, oldest first
+func (pm *PluginPackageManager) ListTrash() ([]TrashEntry, error) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	return pm.listTrash()
+}
+
+// listTrash implements ListTrash. The caller must hold pm.mu.
+func (pm *PluginPackageManager) listTrash() ([]TrashEntry, error) {
+	root := filepath.Join(pm.baseDir, trashDir)
+	orgs, err := os.ReadDir(root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read trash: %w", err)
+	}
+
+	var entries []TrashEntry
+	for _, org := range orgs {
+		if !org.IsDir() {
+			continue
+		}
+		names, err := os.ReadDir(filepath.Join(root, org.Name()))
+		if err != nil {
+			continue
+		}
+		for _, name := range names {
+			if !name.IsDir() {
+				continue
+			}
+			versions, err := os.ReadDir(filepath.Join(root, org.Name(), name.Name()))
+			if err != nil {
+				continue
+			}
+			for _, v := range versions {
+				version, stamp, ok := strings.Cut(v.Name(), "@")
+				if !ok {
+					continue
+				}
+				trashedAt, err := time.Parse(trashTimeLayout, stamp)
+				if err != nil {
+					continue
+				}
+				entries = append(entries, TrashEntry{
+					Org:       org.Name(),
+					Name:      name.Name(),
+					Version:   version,
+					TrashedAt: trashedAt,
+					Path:      filepath.Join(root, org.Name(), name.Name(), v.Name()),
+				})
+			}
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].TrashedAt.Before(entries[j].TrashedAt)
+	})
+	return entries, nil
+}
+
+// PurgeTrash permanently deletes trashed packages older than olderThan and
+// returns how many were removed. A zero olderThan empties the trash.
+func (pm *PluginPackageManager) PurgeTrash(olderThan time.Duration) (int, error) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	entries, err := pm.listTrash()
+	if err != nil {
+		return 0, err
+	}
+
+	cutoff := time.Now().Add(-olderThan)
+	purged := 0
+	for _, entry := range entries {
+		if entry.TrashedAt.After(cutoff) {
+			continue
+		}
+		if err := makeWritable(entry.Path); err != nil {
+			return purged, fmt.Errorf("failed to unlock trashed package: %w", err)
+		}
+		if err := os.RemoveAll(entry.Path); err != nil {
+			return purged, fmt.Errorf("failed to purge trashed package: %w", err)
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+// RestoreTrashed undoes an Uninstall made with WithTrash: the most recently
+// trashed copy of the package version is moved back and registered, and it
+// is activated if no other package is active for its VMID. Pins removed by
+// Uninstall are not restored.
+func (pm *PluginPackageManager) RestoreTrashed(org, name, version string) error {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	entries, err := pm.listTrash()
+	if err != nil {
+		return err
+	}
+	var latest *TrashEntry
+	for i := range entries {
+		e := &entries[i]
+		if e.Org == org && e.Name == name && e.Version == version {
+			latest = e // entries are oldest first
+		}
+	}
+	if latest == nil {
+		return fmt.Errorf("%w: %s/%s@%s not in trash", ErrPluginNotFound, org, name, version)
+	}
+
+	pkgPath := pm.PackagePath(org, name, version)
+	if _, err := os.Lstat(pkgPath); err == nil {
+		return fmt.Errorf("package %s/%s@%s is already installed", org, name, version)
+	}
+	if err := os.MkdirAll(filepath.Dir(pkgPath), 0755); err != nil {
+		return fmt.Errorf("failed to create package directory: %w", err)
+	}
+	if err := os.Rename(latest.Path, pkgPath); err != nil {
+		return fmt.Errorf("failed to restore package: %w", err)
+	}
+	pm.invalidateManifest(org, name, version)
+
+	pkgKey := fmt.Sprintf("%s/%s", org, name)
+	pm.registry.Plugins[pkgKey] = compactVersions(append(pm.registry.Plugins[pkgKey], version))
+
+	manifest, err := pm.getManifest(org, name, version)
+	if err == nil && manifest.VMID != "" {
+		if _, active := pm.registry.Active[manifest.VMID]; !active {
+			return pm.activate(context.Background(), org, name, version)
+		}
+	}
+	return pm.saveRegistry()
+}
@@ -0,0 +1,334 @@
+// Copyright (C) 2024-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// UpgradeStatus classifies an installed package against available versions
+type UpgradeStatus string
+
+const (
+	UpgradeAvailable UpgradeStatus = "upgrade"
+	UpgradeUpToDate  UpgradeStatus = "up-to-date"
+)
+
+// UpgradeAction describes what upgrading one installed package would do
+type UpgradeAction struct {
+	Package string        `json:"package"` // org/name
+	Current string        `json:"current"` // Active version, or newest installed
+	Active  bool          `json:"active"`  // Whether Current is the active version
+	Latest  string        `json:"latest"`  // Newest available stable version
+	Status  UpgradeStatus `json:"status"`
+}
+
+// String renders the action, e.g. "luxfi/evm: active v1.0.0 → latest available v1.2.0"
+func (a UpgradeAction) String() string {
+	state := "installed"
+	if a.Active {
+		state = "active"
+	}
+	switch a.Status {
+	case UpgradeAvailable:
+		return fmt.Sprintf("%s: %s %s → latest available %s", a.Package, state, a.Current, a.Latest)
+	default:
+		return fmt.Sprintf("%s: %s %s is up to date", a.Package, state, a.Current)
+	}
+}
+
+// Pin holds org/name at version, so UpgradePlan skips it
+func (pm *PluginPackageManager) Pin(org, name, version string) error {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	pkgKey := fmt.Sprintf("%s/%s", org, name)
+	if !contains(pm.registry.Plugins[pkgKey], version) {
+		return fmt.Errorf("%w: %s@%s", ErrPluginNotFound, pkgKey, version)
+	}
+	pm.registry.Pinned[pkgKey] = version
+	return pm.saveRegistry()
+}
+
+// Unpin releases a pin set by Pin
+func (pm *PluginPackageManager) Unpin(org, name string) error {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	delete(pm.registry.Pinned, fmt.Sprintf("%s/%s", org, name))
+	return pm.saveRegistry()
+}
+
+// UpgradePlan compares installed packages against available versions, keyed
+// by org/name (e.g. from a remote index), and reports one action per
+// installed package that has available versions. Prerelease and invalid
+// versions in available are ignored, and pinned packages are skipped.
+// Actions are sorted by package; nothing is installed.
+func (pm *PluginPackageManager) UpgradePlan(available map[string][]string) ([]UpgradeAction, error) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	activeVersions := make(map[string]string)
+	for _, ref := range pm.registry.Active {
+		if org, name, version, ok := splitPackageRef(ref); ok {
+			activeVersions[org+"/"+name] = version
+		}
+	}
+
+	var actions []UpgradeAction
+	for pkgKey, installed := range pm.registry.Plugins {
+		if len(installed) == 0 || pm.registry.Pinned[pkgKey] != "" {
+			continue
+		}
+		latest := latestStable(available[pkgKey])
+		if latest == "" {
+			continue
+		}
+
+		action := UpgradeAction{Package: pkgKey, Latest: latest}
+		if version, ok := activeVersions[pkgKey]; ok {
+			action.Current, action.Active = version, true
+		} else {
+			action.Current = LatestSemver(installed)
+		}
+
+		if CompareSemver(latest, action.Current) > 0 {
+			action.Status = UpgradeAvailable
+		} else {
+			action.Status = UpgradeUpToDate
+		}
+		actions = append(actions, action)
+	}
+
+	sort.Slice(actions, func(i, j int) bool { return actions[i].Package < actions[j].Package })
+	return actions, nil
+}
+
+// latestStable returns the newest valid, non-prerelease version
+func latestStable(versions []string) string {
+	latest := ""
+	for _, v := range versions {
+		sv, ok := parseSemver(v)
+		if !ok || len(sv.prerelease) > 0 {
+			continue
+		}
+		if latest == "" || CompareSemver(v, latest) > 0 {
+			latest = v
+		}
+	}
+	return latest
+}
+
+// activeChange records one activation made by ApplyActiveSet or
+// ApplyLockfile so it can be rolled back. A deactivation is recorded with an
+// empty ref and the deactivated version as prev.
+type activeChange struct {
+	ref       string // org/name@version that was activated, or "" for a deactivation
+	vmid      string // VMID of ref
+	prev      string // Previously active version of the same package, or ""
+	prevOwner string // Previous owner of vmid in the active set, or ""
+}
+
+// ApplyActiveSet activates the desired version of each package, keyed by
+// org/name, as a single transaction. Every version must already be
+// installed; this is checked before anything changes. If any activation
+// fails, the activations already made are rolled back and the previous
+// active set is restored. Packages already at their desired version are
+// left alone.
+func (pm *PluginPackageManager) ApplyActiveSet(ctx context.Context, desired map[string]string) error {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	return pm.applyActiveSet(ctx, desired)
+}
+
+// applyActiveSet implements ApplyActiveSet. The caller must hold pm.mu.
+func (pm *PluginPackageManager) applyActiveSet(ctx context.Context, desired map[string]string) error {
+	activeByPkg := make(map[string]string)
+	for _, ref := range pm.registry.Active {
+		activeByPkg[pkgKeyOf(ref)] = ref
+	}
+
+	pkgKeys := make([]string, 0, len(desired))
+	for pkgKey := range desired {
+		pkgKeys = append(pkgKeys, pkgKey)
+	}
+	sort.Strings(pkgKeys)
+
+	var plan []*PluginManifest
+	for _, pkgKey := range pkgKeys {
+		version := desired[pkgKey]
+		org, name, _, ok := splitPackageRef(pkgKey + "@" + version)
+		if !ok || org == "" || name == "" || version == "" {
+			return fmt.Errorf("invalid package version %s@%s", pkgKey, version)
+		}
+		if !contains(pm.registry.Plugins[pkgKey], version) {
+			return fmt.Errorf("%w: %s@%s is not installed", ErrPluginNotFound, pkgKey, version)
+		}
+		if activeByPkg[pkgKey] == pkgKey+"@"+version {
+			continue
+		}
+		manifest, err := pm.getManifest(org, name, version)
+		if err != nil {
+			return fmt.Errorf("failed to load manifest for %s@%s: %w", pkgKey, version, err)
+		}
+		plan = append(plan, manifest)
+	}
+
+	var applied []activeChange
+	for _, manifest := range plan {
+		pkgKey := fmt.Sprintf("%s/%s", manifest.Org, manifest.Name)
+		change := activeChange{
+			ref:       fmt.Sprintf("%s@%s", pkgKey, manifest.Version),
+			vmid:      manifest.VMID,
+			prev:      activeByPkg[pkgKey],
+			prevOwner: pm.registry.Active[manifest.VMID],
+		}
+
+		err := ctx.Err()
+		if err == nil {
+			// Record the change before activating: a failed Activate may
+			// have already replaced the VMID symlink
+			applied = append(applied, change)
+			err = pm.activate(ctx, manifest.Org, manifest.Name, manifest.Version)
+			if err != nil {
+				err = fmt.Errorf("failed to activate %s: %w", change.ref, err)
+			}
+		}
+		if err != nil {
+			if rbErr := pm.rollbackActivations(applied); rbErr != nil {
+				return errors.Join(err, fmt.Errorf("rollback failed: %w", rbErr))
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// rollbackActivations undoes changes in reverse order. The caller must hold
+// pm.mu.
+func (pm *PluginPackageManager) rollbackActivations(changes []activeChange) error {
+	var errs []error
+	for i := len(changes) - 1; i >= 0; i-- {
+		c := changes[i]
+		if c.prev != "" {
+			if err := pm.activateRef(c.prev); err != nil {
+				errs = append(errs, err)
+			}
+		} else {
+			pkgKey := pkgKeyOf(c.ref)
+			pm.unlinkAliases(func(ref string) bool { return pkgKeyOf(ref) == pkgKey })
+		}
+
+		// Restore the VMID if the previous version did not reclaim it
+		if pm.registry.Active[c.vmid] != c.ref {
+			continue
+		}
+		if c.prevOwner != "" {
+			if err := pm.activateRef(c.prevOwner); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		if err := os.Remove(pm.ActivePath(c.vmid)); err != nil && !os.IsNotExist(err) {
+			errs = append(errs, fmt.Errorf("failed to remove VMID symlink %s: %w", c.vmid, err))
+		}
+		delete(pm.registry.Active, c.vmid)
+	}
+
+	if err := pm.saveRegistry(); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// activateRef activates an org/name@version reference. The caller must hold
+// pm.mu.
+func (pm *PluginPackageManager) activateRef(ref string) error {
+	org, name, version, ok := splitPackageRef(ref)
+	if !ok {
+		return fmt.Errorf("invalid package reference: %s", ref)
+	}
+	if err := pm.activate(context.Background(), org, name, version); err != nil {
+		return fmt.Errorf("failed to reactivate %s: %w", ref, err)
+	}
+	return nil
+}
+
+// ActivateAllLatest activates the highest installed semver version of every
+// package in the registry, or the pinned version if the package is pinned.
+// It returns the version now active for each org/name. Packages with no
+// valid semver version, whose version fails to activate, or whose VMID is
+// active for a different package are skipped; they are reported together in
+// the returned error and do not stop the others.
+func (pm *PluginPackageManager) ActivateAllLatest(ctx context.Context) (map[string]string, error) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	pkgKeys := make([]string, 0, len(pm.registry.Plugins))
+	for pkgKey := range pm.registry.Plugins {
+		pkgKeys = append(pkgKeys, pkgKey)
+	}
+	sort.Strings(pkgKeys)
+
+	activated := make(map[string]string)
+	var errs []error
+	for _, pkgKey := range pkgKeys {
+		select {
+		case <-ctx.Done():
+			return activated, ctx.Err()
+		default:
+		}
+
+		version, err := pm.activateLatest(ctx, pkgKey)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", pkgKey, err))
+			continue
+		}
+		activated[pkgKey] = version
+	}
+	return activated, errors.Join(errs...)
+}
+
+// activateLatest activates the pinned or highest valid version of pkgKey
+// and returns it. The caller must hold pm.mu.
+func (pm *PluginPackageManager) activateLatest(ctx context.Context, pkgKey string) (string, error) {
+	org, name, ok := strings.Cut(pkgKey, "/")
+	if !ok {
+		return "", fmt.Errorf("invalid registry key")
+	}
+
+	version := pm.registry.Pinned[pkgKey]
+	if version == "" {
+		var valid []string
+		for _, v := range pm.registry.Plugins[pkgKey] {
+			if IsValidSemver(v) {
+				valid = append(valid, v)
+			}
+		}
+		if version = LatestSemver(valid); version == "" {
+			return "", fmt.Errorf("no valid semver version installed")
+		}
+	}
+
+	manifest, err := pm.getManifest(org, name, version)
+	if err != nil {
+		return "", err
+	}
+	if pm.registry.Active[manifest.VMID] == fmt.Sprintf("%s@%s", pkgKey, version) {
+		return version, nil
+	}
+	if err := pm.checkVMIDConflict(manifest); err != nil {
+		return "", err
+	}
+	if err := pm.activate(ctx, org, name, version); err != nil {
+		return "", err
+	}
+	return version, nil
+}
@@ -0,0 +1,135 @@
+// Copyright (C) 2024-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Which resolves a plugin reference to the absolute path of its binary.
+// ref may be any of:
+//   - org/name@version: that exact version
+//   - org/name: the active version, or the newest installed one
+//   - an alias or VM name, optionally with @version
+//   - a VMID
+//
+// Returns an error wrapping ErrPluginNotFound when nothing matches.
+func (pm *PluginPackageManager) Which(ref string) (string, error) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	org, name, version, err := pm.resolveRef(ref)
+	if err != nil {
+		return "", err
+	}
+
+	manifest, err := pm.getManifest(org, name, version)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s/%s@%s: %v", ErrPluginNotFound, org, name, version, err)
+	}
+	binaryName := manifest.Binary
+	if binaryName == "" {
+		binaryName = name
+	}
+	binaryPath := filepath.Join(pm.PackagePath(org, name, version), binaryName)
+	if !Exists(binaryPath) {
+		return "", fmt.Errorf("%w: binary missing for %s/%s@%s", ErrPluginNotFound, org, name, version)
+	}
+	return filepath.Abs(binaryPath)
+}
+
+// resolveRef parses a plugin reference into an installed org/name@version.
+// The caller must hold pm.mu.
+func (pm *PluginPackageManager) resolveRef(ref string) (org, name, version string, err error) {
+	if ref == "" {
+		return "", "", "", fmt.Errorf("empty plugin reference")
+	}
+
+	base, version := ref, ""
+	if i := strings.LastIndex(ref, "@"); i >= 0 {
+		base, version = ref[:i], ref[i+1:]
+		if version == "" {
+			return "", "", "", fmt.Errorf("invalid plugin reference %q: empty version", ref)
+		}
+	}
+
+	pkgKey := base
+	if !strings.Contains(base, "/") {
+		if pkgKey, err = pm.resolveShortRef(base); err != nil {
+			return "", "", "", err
+		}
+		// A VMID or alias without a version means its active version
+		if version == "" {
+			if owner, ok := pm.registry.Active[base]; ok {
+				version = owner[strings.LastIndex(owner, "@")+1:]
+			} else if owner, ok := pm.registry.Aliases[base]; ok {
+				version = owner[strings.LastIndex(owner, "@")+1:]
+			}
+		}
+	}
+
+	org, name, _, ok := splitPackageRef(pkgKey + "@")
+	if !ok || org == "" || name == "" {
+		return "", "", "", fmt.Errorf("invalid plugin reference %q: want org/name[@version]", ref)
+	}
+	versions := pm.registry.Plugins[pkgKey]
+	if version == "" {
+		version = pm.defaultVersion(pkgKey)
+	}
+	if version == "" || !contains(versions, version) {
+		return "", "", "", fmt.Errorf("%w: %s", ErrPluginNotFound, ref)
+	}
+	return org, name, version, nil
+}
+
+// resolveShortRef maps a VMID, alias, or VM name to its org/name. The
+// caller must hold pm.mu.
+func (pm *PluginPackageManager) resolveShortRef(ref string) (string, error) {
+	if owner, ok := pm.registry.Active[ref]; ok {
+		return pkgKeyOf(owner), nil
+	}
+	if owner, ok := pm.registry.Aliases[ref]; ok {
+		return pkgKeyOf(owner), nil
+	}
+
+	idx, err := pm.lookupIndex()
+	if err != nil {
+		return "", err
+	}
+	matches := idx.byVMName[ref]
+	if len(matches) == 0 {
+		matches = idx.byVMID[ref]
+	}
+	if len(matches) == 0 {
+		return "", fmt.Errorf("%w: %s", ErrPluginNotFound, ref)
+	}
+
+	pkgKey := matches[0].Org + "/" + matches[0].Name
+	for _, m := range matches[1:] {
+		if other := m.Org + "/" + m.Name; other != pkgKey {
+			return "", fmt.Errorf("ambiguous plugin reference %q: provided by %s and %s", ref, pkgKey, other)
+		}
+	}
+	return pkgKey, nil
+}
+
+// defaultVersion returns the active version of org/name, or the newest
+// installed version when none is active. The caller must hold pm.mu.
+func (pm *PluginPackageManager) defaultVersion(pkgKey string) string {
+	for _, ref := range pm.registry.Active {
+		if pkgKeyOf(ref) == pkgKey {
+			return ref[len(pkgKey)+1:]
+		}
+	}
+
+	var newest string
+	for _, version := range pm.registry.Plugins[pkgKey] {
+		if newest == "" || CompareSemver(version, newest) > 0 {
+			newest = version
+		}
+	}
+	return newest
+}
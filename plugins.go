@@ -6,10 +6,13 @@ package config
 import (
 	"context"
 	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/btcsuite/btcutil/base58"
@@ -37,6 +40,30 @@ func VMID(vmName string) string {
 	return base58.CheckEncode(hash[:], 0)
 }
 
+// ErrInvalidVMID is returned for a string that is not a well-formed VMID
+var ErrInvalidVMID = errors.New("invalid vmid")
+
+// ValidateVMID checks that vmid decodes to a 32-byte ID with a valid
+// checksum. Both the base58check form produced by VMID and the node's CB58
+// form (base58 with a 4-byte sha256 checksum) are accepted.
+func ValidateVMID(vmid string) error {
+	if vmid == "" {
+		return fmt.Errorf("%w: empty", ErrInvalidVMID)
+	}
+	if payload, err := cb58Decode(vmid); err == nil && len(payload) == 32 {
+		return nil
+	}
+
+	decoded, version, err := base58.CheckDecode(vmid)
+	if err != nil {
+		return fmt.Errorf("%w %q: %v", ErrInvalidVMID, vmid, err)
+	}
+	if version != 0 || len(decoded) != 32 {
+		return fmt.Errorf("%w %q: decodes to %d bytes, want 32", ErrInvalidVMID, vmid, len(decoded))
+	}
+	return nil
+}
+
 // WellKnownVMIDs returns a map of well-known VM names to their IDs
 func WellKnownVMIDs() map[string]string {
 	return map[string]string{
@@ -100,25 +127,96 @@ type PluginManager interface {
 	EnsureDir() error
 }
 
+// DefaultCopyBufferSize is the buffer size used when installing plugins
+const DefaultCopyBufferSize = 1 << 20 // 1MB
+
+// CopyStrategy selects how DefaultPluginManager copies plugin binaries
+type CopyStrategy int
+
+const (
+	// CopyBuffered copies through a user-space buffer, hashing as it reads
+	CopyBuffered CopyStrategy = iota
+	// CopyKernel uses io.Copy between the files so the OS can copy without
+	// a user-space buffer (copy_file_range, sendfile), then hashes the source
+	CopyKernel
+)
+
+// OverwritePolicy decides what DefaultPluginManager.Install does when a
+// plugin with the same VMID is already installed
+type OverwritePolicy int
+
+const (
+	// FailIfExists refuses to replace an installed plugin, returning ErrExists
+	FailIfExists OverwritePolicy = iota
+	// Overwrite replaces the installed plugin by renaming a fully written
+	// temp file over it, so a running node keeps its mapped binary intact
+	Overwrite
+	// SkipIfExists leaves the installed plugin untouched and succeeds
+	SkipIfExists
+)
+
+// ErrExists is returned by Install when the plugin is already installed
+// and the overwrite policy is FailIfExists
+var ErrExists = errors.New("plugin already installed")
+
 // DefaultPluginManager implements PluginManager
 type DefaultPluginManager struct {
-	pluginDir string
-	config    *LuxConfig
+	pluginDir    string
+	config       *LuxConfig
+	bufferSize   int
+	copyStrategy CopyStrategy
+	overwrite    OverwritePolicy
 }
 
-// NewPluginManager creates a new plugin manager
-func NewPluginManager(cfg *LuxConfig) PluginManager {
-	return &DefaultPluginManager{
-		pluginDir: cfg.PluginDir,
-		config:    cfg,
+// PluginManagerOption configures a DefaultPluginManager
+type PluginManagerOption func(*DefaultPluginManager)
+
+// WithCopyBufferSize sets the buffer size used by Install. With CopyKernel
+// it is the chunk size between cancellation checks. Sizes below 1 keep the
+// default.
+func WithCopyBufferSize(size int) PluginManagerOption {
+	return func(pm *DefaultPluginManager) {
+		if size > 0 {
+			pm.bufferSize = size
+		}
+	}
+}
+
+// WithCopyStrategy sets how Install copies plugin binaries
+func WithCopyStrategy(strategy CopyStrategy) PluginManagerOption {
+	return func(pm *DefaultPluginManager) {
+		pm.copyStrategy = strategy
 	}
 }
 
+// WithOverwritePolicy sets what Install does when the plugin is already
+// installed. The default is FailIfExists.
+func WithOverwritePolicy(policy OverwritePolicy) PluginManagerOption {
+	return func(pm *DefaultPluginManager) {
+		pm.overwrite = policy
+	}
+}
+
+// NewPluginManager creates a new plugin manager
+func NewPluginManager(cfg *LuxConfig, opts ...PluginManagerOption) PluginManager {
+	return newDefaultPluginManager(cfg.PluginDir, cfg, opts)
+}
+
 // NewPluginManagerWithDir creates a plugin manager with a specific directory
-func NewPluginManagerWithDir(pluginDir string) PluginManager {
-	return &DefaultPluginManager{
-		pluginDir: pluginDir,
+func NewPluginManagerWithDir(pluginDir string, opts ...PluginManagerOption) PluginManager {
+	return newDefaultPluginManager(pluginDir, nil, opts)
+}
+
+func newDefaultPluginManager(pluginDir string, cfg *LuxConfig, opts []PluginManagerOption) *DefaultPluginManager {
+	pm := &DefaultPluginManager{
+		pluginDir:  pluginDir,
+		config:     cfg,
+		bufferSize: DefaultCopyBufferSize,
+	}
+	for _, opt := range opts {
+		opt(pm)
 	}
+	return pm
 }
 
 // GetPluginDir returns the plugin directory
@@ -199,64 +297,167 @@ func (pm *DefaultPluginManager) Get(ctx context.Context, vmID string) (*PluginIn
 
 // Install installs a plugin from a source path
 func (pm *DefaultPluginManager) Install(ctx context.Context, source string, vmID string) error {
+	_, err := pm.InstallVerified(ctx, source, vmID, "")
+	return err
+}
+
+// InstallVerified installs a plugin from a source path and verifies the copy.
+// The binary is written to a temp file in the plugin directory, fsynced and
+// re-read; its sha256 must match the digest of the bytes read from source
+// and, if expectedChecksum (hex sha256) is non-empty, that checksum too.
+// The temp file is then renamed into place, subject to the overwrite policy.
+// Returns the installed binary's hex sha256.
+func (pm *DefaultPluginManager) InstallVerified(ctx context.Context, source, vmID, expectedChecksum string) (string, error) {
 	// Ensure plugin directory exists
 	if err := pm.EnsureDir(); err != nil {
-		return fmt.Errorf("failed to create plugin directory: %w", err)
+		return "", fmt.Errorf("failed to create plugin directory: %w", err)
 	}
 
 	destPath := pm.GetPath(vmID)
+	if _, err := os.Lstat(destPath); err == nil {
+		switch pm.overwrite {
+		case FailIfExists:
+			return "", fmt.Errorf("%w: %s", ErrExists, vmID)
+		case SkipIfExists:
+			sum, err := fileSHA256(destPath)
+			if err != nil {
+				return "", fmt.Errorf("failed to checksum plugin: %w", err)
+			}
+			if expectedChecksum != "" && !strings.EqualFold(sum, expectedChecksum) {
+				return "", fmt.Errorf("checksum mismatch for installed plugin: got %s, want %s", sum, expectedChecksum)
+			}
+			return sum, nil
+		}
+	}
 
 	// Check if source exists
 	srcInfo, err := os.Stat(source)
 	if err != nil {
-		return fmt.Errorf("source file not found: %w", err)
+		return "", fmt.Errorf("source file not found: %w", err)
 	}
 	if srcInfo.IsDir() {
-		return fmt.Errorf("source is a directory, expected file")
+		return "", fmt.Errorf("source is a directory, expected file")
 	}
 
 	// Open source file
 	srcFile, err := os.Open(source)
 	if err != nil {
-		return fmt.Errorf("failed to open source: %w", err)
+		return "", fmt.Errorf("failed to open source: %w", err)
 	}
 	defer srcFile.Close()
 
-	// Create destination file (executable)
-	dstFile, err := os.OpenFile(destPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0755)
+	// Write to a temp file so an installed binary is never truncated
+	dstFile, err := os.CreateTemp(pm.pluginDir, "."+vmID+".tmp-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create plugin file: %w", err)
+	}
+	tempPath := dstFile.Name()
+
+	var srcSum string
+	if pm.copyStrategy == CopyKernel {
+		err = copyChunked(ctx, dstFile, srcFile, pm.copyBufferSize())
+		if err == nil {
+			if srcSum, err = fileSHA256(source); err != nil {
+				err = fmt.Errorf("failed to checksum source: %w", err)
+			}
+		}
+	} else {
+		srcSum, err = copyWithContext(ctx, dstFile, srcFile, pm.copyBufferSize())
+	}
+	if err == nil {
+		// Make executable and flush to disk before verifying
+		if chmodErr := dstFile.Chmod(0755); chmodErr != nil {
+			err = fmt.Errorf("failed to make plugin executable: %w", chmodErr)
+		} else if syncErr := dstFile.Sync(); syncErr != nil {
+			err = fmt.Errorf("failed to sync plugin: %w", syncErr)
+		}
+	}
+	if closeErr := dstFile.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to close plugin: %w", closeErr)
+	}
+	if err != nil {
+		os.Remove(tempPath)
+		return "", err
+	}
+
+	// Verify the written file against the source stream
+	dstSum, err := fileSHA256(tempPath)
 	if err != nil {
-		return fmt.Errorf("failed to create plugin file: %w", err)
+		os.Remove(tempPath)
+		return "", fmt.Errorf("failed to checksum plugin: %w", err)
+	}
+	if dstSum != srcSum {
+		os.Remove(tempPath)
+		return "", fmt.Errorf("plugin verification failed: wrote %s, read %s", dstSum, srcSum)
+	}
+	if expectedChecksum != "" && !strings.EqualFold(dstSum, expectedChecksum) {
+		os.Remove(tempPath)
+		return "", fmt.Errorf("checksum mismatch: got %s, want %s", dstSum, expectedChecksum)
+	}
+
+	if err := os.Rename(tempPath, destPath); err != nil {
+		os.Remove(tempPath)
+		return "", fmt.Errorf("failed to install plugin: %w", err)
+	}
+	return dstSum, nil
+}
+
+// copyBufferSize returns the configured buffer size, defaulting for
+// managers not built by a constructor
+func (pm *DefaultPluginManager) copyBufferSize() int {
+	if pm.bufferSize > 0 {
+		return pm.bufferSize
+	}
+	return DefaultCopyBufferSize
+}
+
+// copyWithContext copies src to dst through a bufSize buffer, checking ctx
+// before every read, and returns the hex sha256 of the bytes read
+func copyWithContext(ctx context.Context, dst io.Writer, src io.Reader, bufSize int) (string, error) {
+	h := sha256.New()
+	buf := make([]byte, bufSize)
+	// Neither side implements ReaderFrom or WriterTo, so buf is always used
+	if _, err := io.CopyBuffer(io.MultiWriter(dst, h), &ctxReader{ctx: ctx, r: src}, buf); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return "", ctxErr
+		}
+		return "", fmt.Errorf("failed to copy plugin: %w", err)
 	}
-	defer dstFile.Close()
 
-	// Copy content with context cancellation check
-	buf := make([]byte, 32*1024) // 32KB buffer
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// copyChunked copies src to dst with io.CopyN in chunk-sized pieces, letting
+// the OS copy file to file directly, and checks ctx between pieces
+func copyChunked(ctx context.Context, dst io.Writer, src io.Reader, chunk int) error {
 	for {
 		select {
 		case <-ctx.Done():
-			// Clean up partial file on cancellation
-			os.Remove(destPath)
 			return ctx.Err()
 		default:
 		}
 
-		n, err := srcFile.Read(buf)
-		if n > 0 {
-			if _, writeErr := dstFile.Write(buf[:n]); writeErr != nil {
-				os.Remove(destPath)
-				return fmt.Errorf("failed to write plugin: %w", writeErr)
-			}
-		}
-		if err == io.EOF {
-			break
+		n, err := io.CopyN(dst, src, int64(chunk))
+		if err == io.EOF || (err == nil && n < int64(chunk)) {
+			return nil
 		}
 		if err != nil {
-			os.Remove(destPath)
-			return fmt.Errorf("failed to read source: %w", err)
+			return fmt.Errorf("failed to copy plugin: %w", err)
 		}
 	}
+}
 
-	return nil
+// ctxReader fails reads once ctx is cancelled
+type ctxReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r *ctxReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
 }
 
 // Uninstall removes a plugin
@@ -393,23 +594,38 @@ func (pm *DefaultPluginManager) Verify(vmID string) error {
 	return nil
 }
 
+// Rules reported by ResolvePluginDirExplained, in precedence order
+const (
+	PluginDirSourceEnv       = "LUX_PLUGIN_DIR"
+	PluginDirSourceLegacyEnv = "LUXD_PLUGIN_DIR"
+	PluginDirSourceConfig    = "config"
+	PluginDirSourceDataDir   = "data-dir-default"
+)
+
 // ResolvePluginBaseDir returns the base plugin directory
 // This contains packages/, current/, and registry.json
 func ResolvePluginBaseDir() string {
+	dir, _ := resolvePluginBaseDir()
+	return dir
+}
+
+// resolvePluginBaseDir implements ResolvePluginBaseDir, also returning the
+// rule that selected the directory
+func resolvePluginBaseDir() (string, string) {
 	// 1. Check environment variable first
 	if dir := os.Getenv("LUX_PLUGIN_DIR"); dir != "" {
-		return expandPath(dir)
+		return expandPath(dir), PluginDirSourceEnv
 	}
 
 	// 2. Check legacy environment variable
 	if dir := os.Getenv("LUXD_PLUGIN_DIR"); dir != "" {
-		return expandPath(dir)
+		return expandPath(dir), PluginDirSourceLegacyEnv
 	}
 
 	// 3. Use global config
 	cfg := Global()
 	if cfg != nil && cfg.PluginDir != "" {
-		return cfg.PluginDir
+		return cfg.PluginDir, PluginDirSourceConfig
 	}
 
 	// 4. Default based on data directory
@@ -421,7 +637,31 @@ func ResolvePluginBaseDir() string {
 		dataDir = DefaultDataDir
 	}
 
-	return filepath.Join(expandPath(dataDir), "plugins")
+	return filepath.Join(expandPath(dataDir), "plugins"), PluginDirSourceDataDir
+}
+
+// ResolvePluginDirExplained is ResolvePluginDir, also returning the rule
+// that chose the base directory: one of the PluginDirSource constants. When
+// the base directory does not use the package layout, the node is pointed
+// at the base directory itself rather than current/, and the source is
+// suffixed with the layout, e.g. "config (legacy-flat)" or
+// "data-dir-default (empty)". The same applies to a package layout whose
+// current/ does not exist yet, reported as e.g. "env (packages, no
+// current/)". Unlike ResolvePluginDir, a base directory that cannot be read
+// is reported as an error.
+func ResolvePluginDirExplained() (dir string, source string, err error) {
+	baseDir, source := resolvePluginBaseDir()
+	layout, err := DetectPluginLayout(baseDir)
+	if err != nil {
+		return "", source, err
+	}
+	if layout == LayoutPackages {
+		if current := filepath.Join(baseDir, CurrentPluginsDir); isDir(current) {
+			return current, source, nil
+		}
+		return baseDir, fmt.Sprintf("%s (%s, no %s/)", source, layout, CurrentPluginsDir), nil
+	}
+	return baseDir, fmt.Sprintf("%s (%s)", source, layout), nil
 }
 
 // ResolvePluginDir resolves the plugin directory using the configuration stack
@@ -433,14 +673,64 @@ func ResolvePluginBaseDir() string {
 //	├── current/ag3GReY.../         # VMID symlinks (what node uses)
 //	└── registry.json
 func ResolvePluginDir() string {
-	baseDir := ResolvePluginBaseDir()
+	// New structure keeps VMID symlinks in current/; the legacy structure
+	// keeps plugins directly in the base dir
+	return nodePluginDir(ResolvePluginBaseDir())
+}
+
+// PluginLayout identifies the on-disk structure of a plugin base directory
+type PluginLayout int
+
+const (
+	// LayoutEmpty means the directory is missing or contains no plugins
+	LayoutEmpty PluginLayout = iota
+
+	// LayoutPackages is the package manager layout (packages/, current/, registry.json)
+	LayoutPackages
 
-	// Check if new structure exists (has current/ subdirectory)
-	currentDir := filepath.Join(baseDir, "current")
-	if info, err := os.Stat(currentDir); err == nil && info.IsDir() {
-		return currentDir
+	// LayoutLegacyFlat has plugin binaries or VMID symlinks directly in the base dir
+	LayoutLegacyFlat
+)
+
+// String returns the layout name
+func (l PluginLayout) String() string {
+	switch l {
+	case LayoutPackages:
+		return "packages"
+	case LayoutLegacyFlat:
+		return "legacy-flat"
+	default:
+		return "empty"
+	}
+}
+
+// DetectPluginLayout determines which plugin layout baseDir uses.
+// The presence of packages/, current/, or registry.json indicates the
+// package manager layout; otherwise any plugin file directly in baseDir
+// indicates the legacy flat layout.
+func DetectPluginLayout(baseDir string) (PluginLayout, error) {
+	entries, err := os.ReadDir(baseDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return LayoutEmpty, nil
+		}
+		return LayoutEmpty, fmt.Errorf("failed to read plugin directory: %w", err)
+	}
+
+	hasFiles := false
+	for _, entry := range entries {
+		switch {
+		case entry.IsDir() && (entry.Name() == packagesDir || entry.Name() == activeDir):
+			return LayoutPackages, nil
+		case !entry.IsDir() && entry.Name() == registryFile:
+			return LayoutPackages, nil
+		case !entry.IsDir():
+			hasFiles = true
+		}
 	}
 
-	// Fall back to legacy structure (plugins directly in base dir)
-	return baseDir
+	if hasFiles {
+		return LayoutLegacyFlat, nil
+	}
+	return LayoutEmpty, nil
 }
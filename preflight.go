@@ -0,0 +1,126 @@
+// Copyright (C) 2024-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/luxfi/config/spec"
+)
+
+// CheckStatus is the outcome of a single preflight check
+type CheckStatus string
+
+const (
+	CheckPass CheckStatus = "pass"
+	CheckWarn CheckStatus = "warn"
+	CheckFail CheckStatus = "fail"
+)
+
+// CheckResult is the result of a named preflight check
+type CheckResult struct {
+	Name   string      `json:"name"`
+	Status CheckStatus `json:"status"`
+	Detail string      `json:"detail,omitempty"`
+}
+
+// PreflightCheck runs environment diagnostics for cfg: config validation,
+// spec parsing, data directory writability, plugin directory resolution,
+// and GPU configuration. Every check is run and reported; none stops the
+// others. Used by "lux doctor".
+func PreflightCheck(cfg *LuxConfig) []CheckResult {
+	results := []CheckResult{checkConfig(cfg), checkSpec()}
+	if cfg != nil {
+		results = append(results, checkDataDir(cfg.DataDir), checkPluginDir(cfg.PluginDir))
+	}
+	return append(results, checkGPU(GetGlobalGPUConfig()))
+}
+
+// PreflightFailed reports whether any check in results failed
+func PreflightFailed(results []CheckResult) bool {
+	for _, r := range results {
+		if r.Status == CheckFail {
+			return true
+		}
+	}
+	return false
+}
+
+func checkConfig(cfg *LuxConfig) CheckResult {
+	r := CheckResult{Name: "config", Status: CheckPass}
+	if cfg == nil {
+		r.Status, r.Detail = CheckFail, "no configuration loaded"
+	} else if err := cfg.Validate(); err != nil {
+		r.Status, r.Detail = CheckFail, err.Error()
+	}
+	return r
+}
+
+func checkSpec() CheckResult {
+	r := CheckResult{Name: "spec"}
+	s, err := spec.Spec()
+	if err != nil {
+		r.Status, r.Detail = CheckFail, err.Error()
+		return r
+	}
+	r.Status, r.Detail = CheckPass, fmt.Sprintf("%d flags", len(s.Flags))
+	return r
+}
+
+func checkDataDir(dir string) CheckResult {
+	r := CheckResult{Name: "data-dir", Detail: dir}
+	if dir == "" {
+		r.Status, r.Detail = CheckFail, "data-dir is empty"
+		return r
+	}
+	if err := checkWritableDir(dir); err != nil {
+		r.Status, r.Detail = CheckFail, err.Error()
+		return r
+	}
+	r.Status = CheckPass
+	return r
+}
+
+// checkWritableDir creates dir if needed and checks that a file can be
+// written in it
+func checkWritableDir(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	f, err := os.CreateTemp(dir, ".preflight-*")
+	if err != nil {
+		return fmt.Errorf("%s is not writable: %w", dir, err)
+	}
+	f.Close()
+	_ = os.Remove(f.Name())
+	return nil
+}
+
+func checkPluginDir(dir string) CheckResult {
+	r := CheckResult{Name: "plugin-dir", Detail: dir}
+	layout, err := DetectPluginLayout(dir)
+	switch {
+	case err != nil:
+		r.Status, r.Detail = CheckFail, err.Error()
+	case layout == LayoutEmpty:
+		r.Status, r.Detail = CheckWarn, fmt.Sprintf("no plugins installed in %s", dir)
+	default:
+		r.Status, r.Detail = CheckPass, fmt.Sprintf("%s (%s layout)", dir, layout)
+	}
+	return r
+}
+
+func checkGPU(gpu GPUConfig) CheckResult {
+	r := CheckResult{Name: "gpu", Status: CheckPass, Detail: "disabled"}
+	if !gpu.Enabled {
+		return r
+	}
+	if err := gpu.Validate(); err != nil {
+		r.Status, r.Detail = CheckFail, err.Error()
+	} else {
+		r.Detail = gpu.ResolveBackend()
+	}
+	return r
+}
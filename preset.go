@@ -0,0 +1,53 @@
+// Copyright (C) 2024-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// presetOptions holds settings for PresetConfig
+type presetOptions struct {
+	sharedDataDir bool
+}
+
+// PresetOption is a functional option for PresetConfig
+type PresetOption func(*presetOptions)
+
+// WithSharedDataDir makes PresetConfig keep every network in the default
+// data directory instead of a per-network subdirectory
+func WithSharedDataDir() PresetOption {
+	return func(o *presetOptions) {
+		o.sharedDataDir = true
+	}
+}
+
+// PresetConfig returns the default configuration for a registered network.
+// Mainnet uses the default data directory; every other network is rooted at
+// a subdirectory named after it (e.g. ~/.lux/local, ~/.lux/testnet), so a
+// local run cannot write into mainnet's databases, logs, or chain configs.
+// Paths derived from the config follow its DataDir. The plugin directory
+// stays shared, since plugin binaries do not depend on the network.
+func PresetConfig(network string, opts ...PresetOption) (*LuxConfig, error) {
+	var o presetOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	name, ok := lookupNetworkName(network)
+	if !ok {
+		return nil, fmt.Errorf("unknown network: %s", network)
+	}
+	id, _ := LookupNetworkID(name)
+
+	cfg := DefaultConfig()
+	cfg.Network.Name = name
+	cfg.Network.ID = id
+	if !o.sharedDataDir && name != NetworkMainnet {
+		cfg.DataDir = filepath.Join(cfg.DataDir, name)
+		cfg.Log.Directory = filepath.Join(cfg.DataDir, "logs")
+	}
+	return cfg, nil
+}
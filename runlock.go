@@ -0,0 +1,170 @@
+// Copyright (C) 2021-2025, Lux Industries Inc. All rights reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// RunLockFile is the lock file created in a run directory by TryLockRun
+const RunLockFile = ".lock"
+
+// ErrRunLocked is returned by TryLockRun when another process holds the run
+var ErrRunLocked = errors.New("run is locked by another process")
+
+// RunLockPath returns the lock file path for a run
+func (p *Paths) RunLockPath(networkName, runID string) string {
+	return filepath.Join(p.NetworkRunDir(networkName, runID), RunLockFile)
+}
+
+// TryLockRun takes the lock on a run directory, creating the directory if
+// needed, and returns a func that releases it. The lock file records the
+// owner's PID and is created atomically with that content. A lock whose
+// owner is no longer running is stale and is taken over; the takeover only
+// removes the lock file if it still holds the dead PID, so two processes
+// racing for a stale lock cannot both win. Fails with ErrRunLocked if a
+// live process holds the lock, including the calling process. The release
+// func only removes the lock while it still records this process.
+func (p *Paths) TryLockRun(networkName, runID string) (func() error, error) {
+	runDir := p.NetworkRunDir(networkName, runID)
+	if err := p.EnsureDir(runDir); err != nil {
+		return nil, err
+	}
+	lockPath := p.RunLockPath(networkName, runID)
+	pid := strconv.Itoa(os.Getpid())
+
+	for attempt := 0; attempt < 3; attempt++ {
+		created, err := createLockFile(runDir, lockPath, pid)
+		if err != nil {
+			return nil, err
+		}
+		if created {
+			return func() error { return releaseLockFile(lockPath, pid) }, nil
+		}
+
+		owner, err := readLockPID(lockPath)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil || owner <= 0 || processRunning(owner) {
+			return nil, fmt.Errorf("%w: %s", ErrRunLocked, runID)
+		}
+		// Stale lock left by a process that died without unlocking
+		if err := removeStaleLock(lockPath, owner); err != nil {
+			return nil, err
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrRunLocked, runID)
+}
+
+// createLockFile atomically creates lockPath holding pid by linking a
+// fully written temp file into place. Reports false if lockPath exists.
+func createLockFile(dir, lockPath, pid string) (bool, error) {
+	tmp, err := os.CreateTemp(dir, RunLockFile+".tmp-*")
+	if err != nil {
+		return false, fmt.Errorf("failed to create run lock: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	_, err = tmp.WriteString(pid)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to write run lock: %w", err)
+	}
+
+	if err := os.Link(tmpPath, lockPath); err != nil {
+		if os.IsExist(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to create run lock: %w", err)
+	}
+	return true, nil
+}
+
+// removeStaleLock removes lockPath only if it still records the dead PID
+// stale. The lock is first renamed to a name private to this process, so
+// only one process can claim it; if the claimed file turns out to be a
+// newer lock, it is linked back into place.
+func removeStaleLock(lockPath string, stale int) error {
+	claimed := fmt.Sprintf("%s.stale-%d", lockPath, os.Getpid())
+	if err := os.Rename(lockPath, claimed); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to remove stale run lock: %w", err)
+	}
+	defer os.Remove(claimed)
+
+	if owner, err := readLockPID(claimed); err == nil && owner == stale {
+		return nil
+	}
+	// Another process replaced the stale lock first; give its lock back
+	if err := os.Link(claimed, lockPath); err != nil && !os.IsExist(err) {
+		return fmt.Errorf("failed to restore run lock: %w", err)
+	}
+	return nil
+}
+
+// releaseLockFile removes lockPath if it still records pid
+func releaseLockFile(lockPath, pid string) error {
+	data, err := os.ReadFile(lockPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("run lock %s was removed by another process", lockPath)
+		}
+		return fmt.Errorf("failed to read run lock: %w", err)
+	}
+	if strings.TrimSpace(string(data)) != pid {
+		return fmt.Errorf("run lock %s is held by another process", lockPath)
+	}
+	return os.Remove(lockPath)
+}
+
+// readLockPID returns the PID recorded in a lock file, or an error if the
+// file cannot be read or does not hold a PID
+func readLockPID(lockPath string) (int, error) {
+	data, err := os.ReadFile(lockPath)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(data)))
+}
+
+// IsRunLocked reports whether a live process holds the run's lock
+func (p *Paths) IsRunLocked(networkName, runID string) bool {
+	return lockHeld(p.RunLockPath(networkName, runID))
+}
+
+// lockHeld reports whether the lock file exists and its owner is running.
+// A lock whose PID cannot be read counts as held.
+func lockHeld(lockPath string) bool {
+	pid, err := readLockPID(lockPath)
+	if os.IsNotExist(err) {
+		return false
+	}
+	if err != nil || pid <= 0 {
+		return true
+	}
+	return processRunning(pid)
+}
+
+// processRunning reports whether pid is a running process. When liveness
+// cannot be determined the process is assumed to be running.
+func processRunning(pid int) bool {
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	err = proc.Signal(syscall.Signal(0))
+	return err == nil || !(errors.Is(err, os.ErrProcessDone) || errors.Is(err, syscall.ESRCH))
+}
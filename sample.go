@@ -0,0 +1,175 @@
+// Copyright (C) 2024-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// sampleEntry is a single key in a generated sample config
+type sampleEntry struct {
+	section string      // Parent section ("" for top-level keys)
+	key     string      // Key within the section
+	flag    string      // Flag key used to look up the description
+	value   interface{} // Default value
+}
+
+// sampleEntries returns the sample config keys in output order
+func sampleEntries(cfg *LuxConfig) []sampleEntry {
+	return []sampleEntry{
+		{"", "data-dir", DataDirKey, cfg.DataDir},
+		{"", "plugin-dir", PluginDirKey, cfg.PluginDir},
+
+		{"log", "level", LogLevelKey, cfg.Log.Level},
+		{"log", "format", LogFormatKey, cfg.Log.Format},
+		{"log", "directory", LogDirKey, cfg.Log.Directory},
+		{"log", "max-size", LogMaxSizeKey, cfg.Log.MaxSize},
+		{"log", "max-files", LogMaxFilesKey, cfg.Log.MaxFiles},
+		{"log", "max-age", LogMaxAgeKey, cfg.Log.MaxAge},
+		{"log", "compress", LogCompressKey, cfg.Log.Compress},
+		{"log", "show-caller", LogShowCallerKey, cfg.Log.ShowCaller},
+		{"log", "show-colors", LogShowColorsKey, cfg.Log.ShowColors},
+
+		{"network", "id", NetworkIDKey, cfg.Network.ID},
+		{"network", "name", NetworkNameKey, cfg.Network.Name},
+		{"network", "api-endpoint", NetworkAPIEndpointKey, cfg.Network.APIEndpoint},
+
+		{"node", "http-port", HTTPPortKey, cfg.Node.HTTPPort},
+		{"node", "staking-port", StakingPortKey, cfg.Node.StakingPort},
+		{"node", "db-type", DBTypeKey, cfg.Node.DBType},
+
+		{"telemetry", "metrics-enabled", MetricsEnabledKey, cfg.Telemetry.MetricsEnabled},
+		{"telemetry", "metrics-port", MetricsPortKey, cfg.Telemetry.MetricsPort},
+		{"telemetry", "tracing-enabled", TracingEnabledKey, cfg.Telemetry.TracingEnabled},
+		{"telemetry", "tracing-endpoint", TracingEndpointKey, cfg.Telemetry.TracingEndpoint},
+		{"telemetry", "sample-rate", TracingSampleRateKey, cfg.Telemetry.SampleRate},
+	}
+}
+
+// WriteSampleConfig writes a starter config file populated with DefaultConfig
+// values. Format is "yaml", "toml", or "json"; if empty it is inferred from the
+// file extension, defaulting to YAML. YAML and TOML output carries a comment
+// above each key taken from FlagDescriptions. An existing file is only
+// overwritten when force is true.
+func WriteSampleConfig(path, format string, force bool) error {
+	if format == "" {
+		format = strings.TrimPrefix(filepath.Ext(path), ".")
+	}
+
+	data, err := renderSampleConfig(DefaultConfig(), format)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if force {
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return fmt.Errorf("failed to write config file: %w", err)
+		}
+		return nil
+	}
+
+	// Create exclusively so a file appearing concurrently is never clobbered
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if os.IsExist(err) {
+		return fmt.Errorf("config file already exists: %s", path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(path)
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(path)
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
+
+// renderSampleConfig renders cfg in the requested format
+func renderSampleConfig(cfg *LuxConfig, format string) ([]byte, error) {
+	switch strings.ToLower(format) {
+	case "json":
+		data, err := json.MarshalIndent(cfg, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal config: %w", err)
+		}
+		return append(data, '\n'), nil
+	case "toml":
+		return renderSampleTOML(cfg), nil
+	case "", "yaml", "yml":
+		return renderSampleYAML(cfg), nil
+	default:
+		return nil, fmt.Errorf("unsupported config format %q: must be yaml, toml, or json", format)
+	}
+}
+
+// renderSampleYAML renders cfg as commented YAML
+func renderSampleYAML(cfg *LuxConfig) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("# Lux configuration file\n")
+
+	section := ""
+	for _, e := range sampleEntries(cfg) {
+		indent := ""
+		if e.section != "" {
+			if e.section != section {
+				fmt.Fprintf(&buf, "\n%s:\n", e.section)
+				section = e.section
+			}
+			indent = "  "
+		} else {
+			buf.WriteString("\n")
+		}
+		writeSampleComment(&buf, indent, e.flag)
+		fmt.Fprintf(&buf, "%s%s: %s\n", indent, e.key, sampleValue(e.value))
+	}
+
+	return buf.Bytes()
+}
+
+// renderSampleTOML renders cfg as commented TOML
+func renderSampleTOML(cfg *LuxConfig) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("# Lux configuration file\n")
+
+	section := ""
+	for _, e := range sampleEntries(cfg) {
+		if e.section != section {
+			fmt.Fprintf(&buf, "\n[%s]\n", e.section)
+			section = e.section
+		} else {
+			buf.WriteString("\n")
+		}
+		writeSampleComment(&buf, "", e.flag)
+		fmt.Fprintf(&buf, "%s = %s\n", e.key, sampleValue(e.value))
+	}
+
+	return buf.Bytes()
+}
+
+// writeSampleComment writes the flag description as a comment line
+func writeSampleComment(buf *bytes.Buffer, indent, flag string) {
+	if desc := GetFlagDescription(flag); desc != "" {
+		fmt.Fprintf(buf, "%s# %s\n", indent, desc)
+	}
+}
+
+// sampleValue formats a value for YAML and TOML output
+func sampleValue(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return fmt.Sprintf("%q", s)
+	}
+	return fmt.Sprintf("%v", v)
+}
@@ -0,0 +1,151 @@
+// Copyright (C) 2024-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package config
+
+import (
+	"strconv"
+	"strings"
+)
+
+// semver is a parsed semantic version (vMAJOR.MINOR.PATCH[-PRERELEASE][+BUILD])
+type semver struct {
+	major, minor, patch uint64
+	prerelease          []string
+}
+
+// parseSemver parses a semantic version with an optional "v" prefix
+func parseSemver(v string) (semver, bool) {
+	v = strings.TrimPrefix(v, "v")
+
+	// Build metadata does not affect precedence
+	if i := strings.IndexByte(v, '+'); i >= 0 {
+		if i == len(v)-1 {
+			return semver{}, false
+		}
+		v = v[:i]
+	}
+
+	var sv semver
+	if i := strings.IndexByte(v, '-'); i >= 0 {
+		pre := v[i+1:]
+		if pre == "" {
+			return semver{}, false
+		}
+		sv.prerelease = strings.Split(pre, ".")
+		for _, id := range sv.prerelease {
+			if id == "" {
+				return semver{}, false
+			}
+		}
+		v = v[:i]
+	}
+
+	parts := strings.Split(v, ".")
+	if len(parts) != 3 {
+		return semver{}, false
+	}
+	nums := make([]uint64, 3)
+	for i, p := range parts {
+		if p == "" || (len(p) > 1 && p[0] == '0') {
+			return semver{}, false
+		}
+		n, err := strconv.ParseUint(p, 10, 64)
+		if err != nil {
+			return semver{}, false
+		}
+		nums[i] = n
+	}
+	sv.major, sv.minor, sv.patch = nums[0], nums[1], nums[2]
+
+	return sv, true
+}
+
+// IsValidSemver reports whether v is a valid semantic version.
+// A leading "v" is accepted (e.g. "v1.2.3", "1.2.3-rc.1").
+func IsValidSemver(v string) bool {
+	_, ok := parseSemver(v)
+	return ok
+}
+
+// CompareSemver compares two semantic versions, returning -1, 0, or 1.
+// Invalid versions sort before valid ones and are compared lexically
+// among themselves.
+func CompareSemver(a, b string) int {
+	va, okA := parseSemver(a)
+	vb, okB := parseSemver(b)
+	switch {
+	case !okA && !okB:
+		return strings.Compare(a, b)
+	case !okA:
+		return -1
+	case !okB:
+		return 1
+	}
+
+	if c := compareUint(va.major, vb.major); c != 0 {
+		return c
+	}
+	if c := compareUint(va.minor, vb.minor); c != 0 {
+		return c
+	}
+	if c := compareUint(va.patch, vb.patch); c != 0 {
+		return c
+	}
+	return comparePrerelease(va.prerelease, vb.prerelease)
+}
+
+// LatestSemver returns the highest version in versions, or "" if empty
+func LatestSemver(versions []string) string {
+	latest := ""
+	for _, v := range versions {
+		if latest == "" || CompareSemver(v, latest) > 0 {
+			latest = v
+		}
+	}
+	return latest
+}
+
+// comparePrerelease compares prerelease identifiers per the semver spec.
+// A version without a prerelease has higher precedence.
+func comparePrerelease(a, b []string) int {
+	switch {
+	case len(a) == 0 && len(b) == 0:
+		return 0
+	case len(a) == 0:
+		return 1
+	case len(b) == 0:
+		return -1
+	}
+
+	for i := 0; i < len(a) && i < len(b); i++ {
+		na, errA := strconv.ParseUint(a[i], 10, 64)
+		nb, errB := strconv.ParseUint(b[i], 10, 64)
+		switch {
+		case errA == nil && errB == nil:
+			if c := compareUint(na, nb); c != 0 {
+				return c
+			}
+		case errA == nil:
+			return -1 // Numeric identifiers sort before alphanumeric
+		case errB == nil:
+			return 1
+		default:
+			if c := strings.Compare(a[i], b[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	return compareUint(uint64(len(a)), uint64(len(b)))
+}
+
+func compareUint(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -17,7 +17,14 @@ package spec
 import (
 	_ "embed"
 	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 )
 
 //go:generate sh -c "cd ../../node && go run ./cmd/config dump-spec --format=json > ../sdk/configspec/spec.json"
@@ -108,11 +115,52 @@ var (
 	cachedSpec *ConfigSpec
 	specOnce   sync.Once
 	specErr    error
+
+	overrideMu   sync.RWMutex
+	overrideSpec *ConfigSpec
 )
 
-// Spec returns the embedded configuration specification.
-// The spec is parsed once and cached for subsequent calls.
+// LoadFrom parses a configuration specification in the spec.json format,
+// e.g. one dumped by the node binary actually being driven.
+func LoadFrom(r io.Reader) (*ConfigSpec, error) {
+	s := &ConfigSpec{}
+	if err := json.NewDecoder(r).Decode(s); err != nil {
+		return nil, fmt.Errorf("failed to parse config spec: %w", err)
+	}
+
+	seen := make(map[string]bool, len(s.Flags))
+	for _, f := range s.Flags {
+		if f.Key == "" {
+			return nil, fmt.Errorf("config spec contains a flag without a key")
+		}
+		if seen[f.Key] {
+			return nil, fmt.Errorf("config spec contains duplicate flag %q", f.Key)
+		}
+		seen[f.Key] = true
+	}
+
+	return s, nil
+}
+
+// SetSpec overrides the embedded specification returned by Spec and
+// MustSpec. Passing nil restores the embedded specification.
+func SetSpec(s *ConfigSpec) {
+	overrideMu.Lock()
+	defer overrideMu.Unlock()
+	overrideSpec = s
+}
+
+// Spec returns the configuration specification set by SetSpec, or the
+// embedded one. The embedded spec is parsed once and cached for subsequent
+// calls.
 func Spec() (*ConfigSpec, error) {
+	overrideMu.RLock()
+	s := overrideSpec
+	overrideMu.RUnlock()
+	if s != nil {
+		return s, nil
+	}
+
 	specOnce.Do(func() {
 		cachedSpec = &ConfigSpec{}
 		specErr = json.Unmarshal(specJSON, cachedSpec)
@@ -123,7 +171,7 @@ func Spec() (*ConfigSpec, error) {
 	return cachedSpec, nil
 }
 
-// MustSpec returns the embedded configuration specification or panics on error.
+// MustSpec returns the configuration specification or panics on error.
 func MustSpec() *ConfigSpec {
 	s, err := Spec()
 	if err != nil {
@@ -142,6 +190,31 @@ func (s *ConfigSpec) GetFlag(key string) *FlagSpec {
 	return nil
 }
 
+// EnvPrefix is the environment variable prefix used by the config loader.
+const EnvPrefix = "LUX_"
+
+// EnvVar returns the environment variable that overrides the flag,
+// e.g. "LUX_NETWORK_ID" for "network-id".
+func (f *FlagSpec) EnvVar() string {
+	return EnvPrefix + strings.NewReplacer("-", "_", ".", "_").Replace(strings.ToUpper(f.Key))
+}
+
+// GetFlagByEnv returns the spec for the flag overridden by envVar, or nil if
+// none matches. The env mapping is lossy ("log.level" and "log-level" both
+// map to LUX_LOG_LEVEL); when several flags match, the first in spec order
+// is returned.
+func (s *ConfigSpec) GetFlagByEnv(envVar string) *FlagSpec {
+	if !strings.HasPrefix(envVar, EnvPrefix) {
+		return nil
+	}
+	for i := range s.Flags {
+		if s.Flags[i].EnvVar() == envVar {
+			return &s.Flags[i]
+		}
+	}
+	return nil
+}
+
 // FlagsByCategory returns all flags in a specific category.
 func (s *ConfigSpec) FlagsByCategory(cat Category) []FlagSpec {
 	var result []FlagSpec
@@ -178,6 +251,327 @@ func (s *ConfigSpec) KnownKey(key string) bool {
 	return s.GetFlag(key) != nil
 }
 
+// Merge returns a new spec combining s with extra flags and categories,
+// e.g. CLI-specific flags layered over the node spec. A flag in extra with
+// the same key as one in s replaces it in place; other extra flags are
+// appended in order. s is not modified.
+func (s *ConfigSpec) Merge(extra []FlagSpec, categories map[Category]string) *ConfigSpec {
+	merged, _ := s.merge(extra, categories, false)
+	return merged
+}
+
+// MergeStrict is like Merge but returns an error if a flag in extra
+// duplicates a key already in s or in extra.
+func (s *ConfigSpec) MergeStrict(extra []FlagSpec, categories map[Category]string) (*ConfigSpec, error) {
+	return s.merge(extra, categories, true)
+}
+
+func (s *ConfigSpec) merge(extra []FlagSpec, categories map[Category]string, strict bool) (*ConfigSpec, error) {
+	merged := &ConfigSpec{
+		Version:     s.Version,
+		NodeVersion: s.NodeVersion,
+		GeneratedAt: s.GeneratedAt,
+		Flags:       append([]FlagSpec(nil), s.Flags...),
+		Categories:  make(map[Category]string, len(s.Categories)+len(categories)),
+	}
+	for cat, desc := range s.Categories {
+		merged.Categories[cat] = desc
+	}
+	for cat, desc := range categories {
+		merged.Categories[cat] = desc
+	}
+
+	index := make(map[string]int, len(merged.Flags))
+	for i, f := range merged.Flags {
+		index[f.Key] = i
+	}
+	for _, f := range extra {
+		if i, ok := index[f.Key]; ok {
+			if strict {
+				return nil, fmt.Errorf("duplicate flag %q", f.Key)
+			}
+			merged.Flags[i] = f
+			continue
+		}
+		index[f.Key] = len(merged.Flags)
+		merged.Flags = append(merged.Flags, f)
+	}
+
+	return merged, nil
+}
+
+// Violation describes a configuration value that breaks a flag's constraints.
+type Violation struct {
+	Key     string      `json:"key"`
+	Value   interface{} `json:"value,omitempty"`
+	Message string      `json:"message"`
+}
+
+// Error implements the error interface.
+func (v Violation) Error() string {
+	return fmt.Sprintf("%s: %s", v.Key, v.Message)
+}
+
+// ValidateValue checks a value against the flag's constraints.
+// Numeric bounds accept numbers or their string forms.
+func (f *FlagSpec) ValidateValue(value interface{}) error {
+	c := f.Constraints
+	if c == nil || value == nil {
+		return nil
+	}
+
+	if len(c.Enum) > 0 {
+		str := fmt.Sprint(value)
+		valid := false
+		for _, e := range c.Enum {
+			if e == str {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return fmt.Errorf("value %q must be one of %v", str, c.Enum)
+		}
+	}
+
+	if c.Min != nil || c.Max != nil {
+		n, ok := toFloat(value)
+		if !ok {
+			return fmt.Errorf("value %v is not numeric", value)
+		}
+		if min, ok := toFloat(c.Min); ok && n < min {
+			return fmt.Errorf("value %v is below minimum %v", value, c.Min)
+		}
+		if max, ok := toFloat(c.Max); ok && n > max {
+			return fmt.Errorf("value %v is above maximum %v", value, c.Max)
+		}
+	}
+
+	if c.Pattern != "" {
+		re, err := regexp.Compile(c.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern %q in spec: %w", c.Pattern, err)
+		}
+		if str := fmt.Sprint(value); !re.MatchString(str) {
+			return fmt.Errorf("value %q does not match pattern %q", str, c.Pattern)
+		}
+	}
+
+	return nil
+}
+
+// Validate checks values keyed by flag key against the spec constraints,
+// including required_with and conflicts_with relationships. Keys not in
+// the spec are ignored. Violations are returned sorted by key.
+func (s *ConfigSpec) Validate(values map[string]interface{}) []Violation {
+	var violations []Violation
+	for key, value := range values {
+		f := s.GetFlag(key)
+		if f == nil {
+			continue
+		}
+		if err := f.ValidateValue(value); err != nil {
+			violations = append(violations, Violation{Key: key, Value: value, Message: err.Error()})
+		}
+		if f.Constraints == nil || !isSet(value) {
+			continue
+		}
+		for _, other := range f.Constraints.RequiredWith {
+			if !isSet(values[other]) {
+				violations = append(violations, Violation{Key: key, Value: value, Message: fmt.Sprintf("requires %s to be set", other)})
+			}
+		}
+		for _, other := range f.Constraints.ConflictsWith {
+			if isSet(values[other]) {
+				violations = append(violations, Violation{Key: key, Value: value, Message: fmt.Sprintf("conflicts with %s", other)})
+			}
+		}
+	}
+
+	sort.SliceStable(violations, func(i, j int) bool {
+		return violations[i].Key < violations[j].Key
+	})
+	return violations
+}
+
+// MissingOption configures MissingRequired.
+type MissingOption func(*missingOptions)
+
+type missingOptions struct {
+	ignoreDefaults bool
+}
+
+// IgnoreDefaults makes MissingRequired report required flags that have a
+// spec default but no explicit value, for wizards that want every required
+// flag set by the operator.
+func IgnoreDefaults() MissingOption {
+	return func(o *missingOptions) {
+		o.ignoreDefaults = true
+	}
+}
+
+// MissingRequired returns the required flags that have no value in values,
+// sorted by key. By default a flag with a non-empty spec default counts as
+// provided; see IgnoreDefaults.
+func (s *ConfigSpec) MissingRequired(values map[string]interface{}, opts ...MissingOption) []FlagSpec {
+	var o missingOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	var missing []FlagSpec
+	for _, f := range s.Flags {
+		if !f.Required || isSet(values[f.Key]) {
+			continue
+		}
+		if !o.ignoreDefaults && isSet(f.Default) {
+			continue
+		}
+		missing = append(missing, f)
+	}
+	sort.SliceStable(missing, func(i, j int) bool {
+		return missing[i].Key < missing[j].Key
+	})
+	return missing
+}
+
+// Coerce converts a string value (as read from env vars or flags) into the
+// Go type matching the flag's Type. Durations accept time.ParseDuration
+// syntax or integer nanoseconds, slices are comma-separated, and
+// string-to-string values are comma-separated key=value pairs. Non-string
+// values are returned unchanged.
+func (f *FlagSpec) Coerce(value interface{}) (interface{}, error) {
+	str, ok := value.(string)
+	if !ok {
+		return value, nil
+	}
+	str = strings.TrimSpace(str)
+
+	switch f.Type {
+	case TypeBool:
+		return strconv.ParseBool(str)
+	case TypeInt:
+		n, err := strconv.ParseInt(str, 10, 64)
+		return int(n), err
+	case TypeUint, TypeUint64:
+		return strconv.ParseUint(str, 10, 64)
+	case TypeFloat64:
+		return strconv.ParseFloat(str, 64)
+	case TypeDuration:
+		if d, err := time.ParseDuration(str); err == nil {
+			return d, nil
+		}
+		n, err := strconv.ParseInt(str, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid duration %q", str)
+		}
+		return time.Duration(n), nil
+	case TypeStringSlice:
+		return splitList(str), nil
+	case TypeIntSlice:
+		parts := splitList(str)
+		ints := make([]int, len(parts))
+		for i, p := range parts {
+			n, err := strconv.Atoi(p)
+			if err != nil {
+				return nil, fmt.Errorf("invalid int %q in list", p)
+			}
+			ints[i] = n
+		}
+		return ints, nil
+	case TypeStringToString:
+		m := make(map[string]string)
+		for _, pair := range splitList(str) {
+			k, v, ok := strings.Cut(pair, "=")
+			if !ok || k == "" {
+				return nil, fmt.Errorf("invalid key=value pair %q", pair)
+			}
+			m[strings.TrimSpace(k)] = strings.TrimSpace(v)
+		}
+		return m, nil
+	default:
+		return value, nil
+	}
+}
+
+// Coerce converts the string values of known flags to their spec types.
+// Keys not in the spec are copied unchanged. Conversion failures are
+// reported per key and the original value is kept.
+func (s *ConfigSpec) Coerce(values map[string]interface{}) (map[string]interface{}, []Violation) {
+	result := make(map[string]interface{}, len(values))
+	var violations []Violation
+	for key, value := range values {
+		result[key] = value
+		f := s.GetFlag(key)
+		if f == nil {
+			continue
+		}
+		coerced, err := f.Coerce(value)
+		if err != nil {
+			violations = append(violations, Violation{Key: key, Value: value, Message: fmt.Sprintf("expected %s: %v", f.Type, err)})
+			continue
+		}
+		result[key] = coerced
+	}
+
+	sort.SliceStable(violations, func(i, j int) bool {
+		return violations[i].Key < violations[j].Key
+	})
+	return result, violations
+}
+
+// splitList splits a comma-separated list, dropping empty entries.
+func splitList(str string) []string {
+	var parts []string
+	for _, p := range strings.Split(str, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			parts = append(parts, p)
+		}
+	}
+	return parts
+}
+
+// isSet reports whether a value counts as provided.
+func isSet(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case string:
+		return v != ""
+	case []string:
+		return len(v) > 0
+	default:
+		return true
+	}
+}
+
+// toFloat converts a numeric value or numeric string to float64.
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
 // Version returns the spec version.
 func Version() string {
 	return MustSpec().Version
@@ -197,3 +591,8 @@ func AllKeys() []string {
 func KnownKey(key string) bool {
 	return MustSpec().KnownKey(key)
 }
+
+// MissingRequired returns the required flags with no value in values.
+func MissingRequired(values map[string]interface{}, opts ...MissingOption) []FlagSpec {
+	return MustSpec().MissingRequired(values, opts...)
+}
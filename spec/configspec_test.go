@@ -4,7 +4,9 @@
 package spec
 
 import (
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestSpec(t *testing.T) {
@@ -97,3 +99,165 @@ func TestNodeVersion(t *testing.T) {
 		t.Error("NodeVersion() returned empty string")
 	}
 }
+
+func TestValidate(t *testing.T) {
+	s := MustSpec()
+
+	violations := s.Validate(map[string]interface{}{
+		"db-type":                     "pebble",
+		"log-level":                   "info",
+		"network-timeout-coefficient": 0.5,
+		"genesis-db":                  "/tmp/genesis",
+		"genesis-file":                "/tmp/genesis.json",
+		"unknown-flag-xyz":            "ignored",
+	})
+
+	got := make(map[string]bool)
+	for _, v := range violations {
+		got[v.Key] = true
+	}
+	for _, key := range []string{"db-type", "network-timeout-coefficient", "genesis-db"} {
+		if !got[key] {
+			t.Errorf("expected violation for %s, got %v", key, violations)
+		}
+	}
+	if got["log-level"] || got["unknown-flag-xyz"] {
+		t.Errorf("unexpected violations: %v", violations)
+	}
+}
+
+func TestMissingRequired(t *testing.T) {
+	s := &ConfigSpec{Flags: []FlagSpec{
+		{Key: "network-id", Type: TypeString, Required: true},
+		{Key: "data-dir", Type: TypeString, Required: true, Default: "/var/lib/luxd"},
+		{Key: "http-port", Type: TypeUint, Required: true},
+		{Key: "log-level", Type: TypeString},
+	}}
+
+	missing := s.MissingRequired(map[string]interface{}{"http-port": 9630, "network-id": ""})
+	if len(missing) != 1 || missing[0].Key != "network-id" {
+		t.Errorf("MissingRequired() = %v, want network-id", missing)
+	}
+
+	missing = s.MissingRequired(map[string]interface{}{"network-id": "mainnet"}, IgnoreDefaults())
+	var keys []string
+	for _, f := range missing {
+		keys = append(keys, f.Key)
+	}
+	if got := strings.Join(keys, ","); got != "data-dir,http-port" {
+		t.Errorf("MissingRequired(IgnoreDefaults) = %s, want data-dir,http-port", got)
+	}
+}
+
+func TestCoerce(t *testing.T) {
+	s := MustSpec()
+
+	values, violations := s.Coerce(map[string]interface{}{
+		"uptime-metric-freq": "1h",
+		"fd-limit":           "65536",
+		"tracing-headers":    "a=1, b=2",
+		"staking-port":       "not-a-port",
+		"custom-key":         "kept",
+	})
+
+	if d, ok := values["uptime-metric-freq"].(time.Duration); !ok || d != time.Hour {
+		t.Errorf("uptime-metric-freq = %#v, want 1h", values["uptime-metric-freq"])
+	}
+	if n, ok := values["fd-limit"].(uint64); !ok || n != 65536 {
+		t.Errorf("fd-limit = %#v, want uint64 65536", values["fd-limit"])
+	}
+	if m, ok := values["tracing-headers"].(map[string]string); !ok || m["b"] != "2" {
+		t.Errorf("tracing-headers = %#v, want map with b=2", values["tracing-headers"])
+	}
+	if values["custom-key"] != "kept" {
+		t.Errorf("custom-key = %#v, want unchanged", values["custom-key"])
+	}
+	if len(violations) != 1 || violations[0].Key != "staking-port" {
+		t.Errorf("violations = %v, want one for staking-port", violations)
+	}
+}
+
+func TestLoadFromAndSetSpec(t *testing.T) {
+	custom, err := LoadFrom(strings.NewReader(`{"version":"9.9.9","flags":[{"key":"custom-flag","type":"bool"}]}`))
+	if err != nil {
+		t.Fatalf("LoadFrom() failed: %v", err)
+	}
+
+	SetSpec(custom)
+	t.Cleanup(func() { SetSpec(nil) })
+
+	if s := MustSpec(); s.Version != "9.9.9" || !s.KnownKey("custom-flag") {
+		t.Errorf("Spec() did not return the override: version %s", s.Version)
+	}
+
+	SetSpec(nil)
+	if s := MustSpec(); s.KnownKey("custom-flag") || !s.KnownKey("network-id") {
+		t.Error("SetSpec(nil) did not restore the embedded spec")
+	}
+
+	if _, err := LoadFrom(strings.NewReader(`{"flags":[{"key":"a"},{"key":"a"}]}`)); err == nil {
+		t.Error("LoadFrom() accepted duplicate flag keys")
+	}
+	if _, err := LoadFrom(strings.NewReader(`not json`)); err == nil {
+		t.Error("LoadFrom() accepted invalid JSON")
+	}
+}
+
+func TestGetFlagByEnv(t *testing.T) {
+	s := MustSpec()
+
+	f := s.GetFlagByEnv("LUX_NETWORK_ID")
+	if f == nil || f.Key != "network-id" {
+		t.Fatalf("GetFlagByEnv(LUX_NETWORK_ID) = %v, want network-id", f)
+	}
+	if f.EnvVar() != "LUX_NETWORK_ID" {
+		t.Errorf("EnvVar() = %s, want LUX_NETWORK_ID", f.EnvVar())
+	}
+
+	for _, env := range []string{"NETWORK_ID", "LUX_NOT_A_FLAG", "lux_network_id"} {
+		if f := s.GetFlagByEnv(env); f != nil {
+			t.Errorf("GetFlagByEnv(%s) = %s, want nil", env, f.Key)
+		}
+	}
+
+	// Lossy mapping returns the first match in spec order
+	ambiguous := &ConfigSpec{Flags: []FlagSpec{{Key: "log.level"}, {Key: "log-level"}}}
+	if f := ambiguous.GetFlagByEnv("LUX_LOG_LEVEL"); f == nil || f.Key != "log.level" {
+		t.Errorf("GetFlagByEnv() with ambiguous keys = %v, want log.level", f)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	base := MustSpec()
+	extra := []FlagSpec{
+		{Key: "cli-output", Type: TypeString, Category: "cli", Constraints: &Constraints{Enum: []string{"text", "json"}}},
+		{Key: "network-id", Type: TypeUint, Description: "overridden"},
+	}
+
+	merged := base.Merge(extra, map[Category]string{"cli": "CLI options"})
+	if !merged.KnownKey("cli-output") || !merged.KnownKey("http-port") {
+		t.Error("merged spec is missing base or extra flags")
+	}
+	if got := merged.GetFlag("network-id").Description; got != "overridden" {
+		t.Errorf("network-id description = %q, want override", got)
+	}
+	if len(merged.Flags) != len(base.Flags)+1 {
+		t.Errorf("merged spec has %d flags, want %d", len(merged.Flags), len(base.Flags)+1)
+	}
+	if merged.Categories["cli"] != "CLI options" {
+		t.Error("extra category not merged")
+	}
+	if base.KnownKey("cli-output") || base.GetFlag("network-id").Description == "overridden" {
+		t.Error("Merge() modified the base spec")
+	}
+	if v := merged.Validate(map[string]interface{}{"cli-output": "yaml"}); len(v) != 1 {
+		t.Errorf("Validate() on merged flag = %v, want one violation", v)
+	}
+
+	if _, err := base.MergeStrict(extra, nil); err == nil {
+		t.Error("MergeStrict() accepted a duplicate key")
+	}
+	if _, err := base.MergeStrict(extra[:1], nil); err != nil {
+		t.Errorf("MergeStrict() error = %v", err)
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright (C) 2024-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package config
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Store holds a configuration that can be replaced at runtime.
+// Get is lock-free; subscribers are notified synchronously on each Set, in
+// registration order. Sets are serialized, so every subscriber sees the
+// updates in the order they were stored and the last notification always
+// carries the current configuration. A subscriber must not call Set.
+type Store struct {
+	cfg atomic.Pointer[LuxConfig]
+
+	setMu sync.Mutex // serializes storing and notifying
+
+	mu     sync.Mutex
+	nextID int
+	subs   []subscription
+}
+
+// subscription is a registered subscriber
+type subscription struct {
+	id int
+	fn func(*LuxConfig)
+}
+
+// NewStore creates a store holding cfg (which may be nil)
+func NewStore(cfg *LuxConfig) *Store {
+	s := &Store{}
+	s.cfg.Store(cfg)
+	return s
+}
+
+// Get returns the current configuration
+func (s *Store) Get() *LuxConfig {
+	return s.cfg.Load()
+}
+
+// Set replaces the configuration and notifies subscribers
+func (s *Store) Set(cfg *LuxConfig) {
+	s.setMu.Lock()
+	defer s.setMu.Unlock()
+
+	s.cfg.Store(cfg)
+	s.notify(cfg)
+}
+
+// setIfEmpty stores cfg only if no configuration is set yet
+func (s *Store) setIfEmpty(cfg *LuxConfig) bool {
+	s.setMu.Lock()
+	defer s.setMu.Unlock()
+
+	if !s.cfg.CompareAndSwap(nil, cfg) {
+		return false
+	}
+	s.notify(cfg)
+	return true
+}
+
+// Subscribe registers fn to be called with each new configuration.
+// The returned function removes the subscription.
+func (s *Store) Subscribe(fn func(*LuxConfig)) func() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := s.nextID
+	s.nextID++
+	s.subs = append(s.subs, subscription{id: id, fn: fn})
+
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for i, sub := range s.subs {
+			if sub.id == id {
+				s.subs = append(s.subs[:i:i], s.subs[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// notify calls every subscriber with cfg. The caller must hold s.setMu.
+func (s *Store) notify(cfg *LuxConfig) {
+	s.mu.Lock()
+	subs := make([]func(*LuxConfig), 0, len(s.subs))
+	for _, sub := range s.subs {
+		subs = append(subs, sub.fn)
+	}
+	s.mu.Unlock()
+
+	for _, fn := range subs {
+		fn(cfg)
+	}
+}
+
+// globalStore backs Global and SetGlobal
+var globalStore = &Store{}
+
+// GlobalStore returns the store backing Global, for components that need
+// to react when SetGlobal replaces the configuration
+func GlobalStore() *Store {
+	return globalStore
+}
@@ -0,0 +1,76 @@
+// Copyright (C) 2019-2025, Lux Industries, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package config
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// TrackChains is the list of chain IDs or aliases passed to the node's
+// track-chains flag
+type TrackChains []string
+
+// ParseTrackChains parses a comma-separated track-chains value. Spaces
+// around entries are trimmed and repeated entries are dropped; an empty
+// string yields an empty list.
+func ParseTrackChains(s string) (TrackChains, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+
+	var chains TrackChains
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(s, ",") {
+		chain := strings.TrimSpace(part)
+		if chain == "" {
+			return nil, fmt.Errorf("invalid %s %q: empty chain", TrackChainsKey, s)
+		}
+		if seen[chain] {
+			continue
+		}
+		seen[chain] = true
+		chains = append(chains, chain)
+	}
+	return chains, chains.Validate()
+}
+
+// Validate checks that every entry is non-empty, contains no commas or
+// whitespace, and appears once
+func (t TrackChains) Validate() error {
+	seen := make(map[string]bool, len(t))
+	for _, chain := range t {
+		if chain == "" {
+			return fmt.Errorf("invalid %s: empty chain", TrackChainsKey)
+		}
+		if strings.ContainsRune(chain, ',') || strings.IndexFunc(chain, unicode.IsSpace) >= 0 {
+			return fmt.Errorf("invalid %s: chain %q contains a comma or whitespace", TrackChainsKey, chain)
+		}
+		if seen[chain] {
+			return fmt.Errorf("invalid %s: chain %s is listed twice", TrackChainsKey, chain)
+		}
+		seen[chain] = true
+	}
+	return nil
+}
+
+// String renders the list as the node expects it, comma-separated
+func (t TrackChains) String() string {
+	return strings.Join(t, ",")
+}
+
+// TrackChainsFor maps chain names to their IDs using chainIDs, keeping the
+// order of names. Every name must have a non-empty ID.
+func TrackChainsFor(names []string, chainIDs map[string]string) (TrackChains, error) {
+	chains := make(TrackChains, 0, len(names))
+	for _, name := range names {
+		id, ok := chainIDs[name]
+		if !ok || id == "" {
+			return nil, fmt.Errorf("no chain id for chain %s", name)
+		}
+		chains = append(chains, id)
+	}
+	return chains, chains.Validate()
+}
@@ -0,0 +1,48 @@
+// Copyright (C) 2024-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package config
+
+import (
+	"errors"
+	"sync"
+)
+
+var (
+	validatorsMu sync.RWMutex
+
+	// configValidators run after LuxConfig.Validate, in registration order
+	configValidators []func(*LuxConfig) error
+)
+
+// RegisterConfigValidator adds a validation rule that the Loader runs on
+// every loaded config once the built-in Validate has passed. Validators
+// run in registration order and all of their errors are reported together.
+func RegisterConfigValidator(v func(*LuxConfig) error) {
+	validatorsMu.Lock()
+	defer validatorsMu.Unlock()
+	configValidators = append(configValidators, v)
+}
+
+// ResetConfigValidators removes all registered validators
+func ResetConfigValidators() {
+	validatorsMu.Lock()
+	defer validatorsMu.Unlock()
+	configValidators = nil
+}
+
+// runConfigValidators runs the registered validators on cfg and joins
+// their errors
+func runConfigValidators(cfg *LuxConfig) error {
+	validatorsMu.RLock()
+	validators := append([]func(*LuxConfig) error(nil), configValidators...)
+	validatorsMu.RUnlock()
+
+	var errs []error
+	for _, v := range validators {
+		if err := v(cfg); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}